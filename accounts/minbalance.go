@@ -0,0 +1,256 @@
+// Package accounts computes properties of accounts, such as their minimum
+// balance, from the account information returned by algod or the indexer.
+package accounts
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var errOverflow = errors.New("minimum balance overflows uint64")
+var errInsufficientBalance = errors.New("balance does not cover the amounts spent by the transaction")
+
+// footprint is what an account holds that raises its minimum balance
+type footprint struct {
+	assets     map[uint64]bool
+	created    int
+	optedIn    int
+	schema     types.StateSchema
+	extraPages uint64
+}
+
+func makeFootprint(account models.Account) footprint {
+	fp := footprint{assets: make(map[uint64]bool)}
+	for _, holding := range account.Assets {
+		fp.assets[holding.AssetId] = true
+	}
+	// the creator of an asset always holds it
+	for _, asset := range account.CreatedAssets {
+		fp.assets[asset.Index] = true
+	}
+
+	fp.created = len(account.CreatedApps)
+	fp.optedIn = len(account.AppsLocalState)
+	if account.AppsTotalSchema != (models.ApplicationStateSchema{}) {
+		fp.schema = types.StateSchema{NumUint: account.AppsTotalSchema.NumUint, NumByteSlice: account.AppsTotalSchema.NumByteSlice}
+	} else {
+		for _, app := range account.CreatedApps {
+			fp.addSchema(app.Params.GlobalStateSchema)
+		}
+		for _, local := range account.AppsLocalState {
+			fp.addSchema(local.Schema)
+		}
+	}
+	fp.extraPages = account.AppsTotalExtraPages
+	if fp.extraPages == 0 {
+		for _, app := range account.CreatedApps {
+			fp.extraPages += app.Params.ExtraProgramPages
+		}
+	}
+	return fp
+}
+
+func (fp *footprint) addSchema(schema models.ApplicationStateSchema) {
+	fp.schema.NumUint += schema.NumUint
+	fp.schema.NumByteSlice += schema.NumByteSlice
+}
+
+func (fp *footprint) removeSchema(schema models.ApplicationStateSchema) {
+	fp.schema.NumUint -= schema.NumUint
+	fp.schema.NumByteSlice -= schema.NumByteSlice
+}
+
+// sum accumulates products, remembering whether any step overflowed
+type sum struct {
+	total    uint64
+	overflow bool
+}
+
+func (s *sum) add(a, b uint64) {
+	product, overflowed := types.OMul(a, b)
+	s.overflow = s.overflow || overflowed
+	total, overflowed := types.OAdd(s.total, product)
+	s.overflow = s.overflow || overflowed
+	s.total = total
+}
+
+func (fp footprint) minBalance(params consensus.Params) (uint64, error) {
+	var s sum
+	s.add(params.MinBalance, 1+uint64(len(fp.assets)))
+	s.add(params.AppFlatParamsMinBalance, uint64(fp.created)+fp.extraPages)
+	s.add(params.AppFlatOptInMinBalance, uint64(fp.optedIn))
+	s.add(params.SchemaMinBalancePerEntry+params.SchemaUintMinBalance, fp.schema.NumUint)
+	s.add(params.SchemaMinBalancePerEntry+params.SchemaBytesMinBalance, fp.schema.NumByteSlice)
+	if s.overflow {
+		return 0, errOverflow
+	}
+	return s.total, nil
+}
+
+// MinBalance returns the minimum balance, in microAlgos, the account must keep
+// under the passed consensus parameters. It accounts for asset holdings, created
+// assets, applications opted in to with their local schemas and created
+// applications with their global schemas and extra program pages.
+func MinBalance(account models.Account, params consensus.Params) (uint64, error) {
+	return makeFootprint(account).minBalance(params)
+}
+
+// Projection is the state of an account after a transaction
+type Projection struct {
+	// Balance is the balance after the transaction, in microAlgos
+	Balance uint64
+
+	// MinBalance is the minimum balance after the transaction, in microAlgos
+	MinBalance uint64
+
+	// Closed is whether the transaction closes the account
+	Closed bool
+}
+
+// Spendable returns how much the account can still spend, in microAlgos
+func (p Projection) Spendable() uint64 {
+	if p.Balance < p.MinBalance {
+		return 0
+	}
+	return p.Balance - p.MinBalance
+}
+
+// Affordable reports whether the account keeps its minimum balance
+func (p Projection) Affordable() bool {
+	return p.Closed || p.Balance >= p.MinBalance
+}
+
+// MinBalanceAfter projects the balance and minimum balance of the account once
+// txn is applied. The account may be the sender or the receiver of txn. Opting
+// in to an application requires its parameters, to learn its local schema:
+// pass them in apps.
+func MinBalanceAfter(account models.Account, txn types.Transaction, params consensus.Params, apps ...models.Application) (Projection, error) {
+	addr, err := types.DecodeAddress(account.Address)
+	if err != nil {
+		return Projection{}, err
+	}
+	fp := makeFootprint(account)
+	balance := account.Amount
+
+	if txn.Type == types.PaymentTx && txn.Receiver == addr && txn.Sender != addr {
+		balance, _ = types.OAdd(balance, uint64(txn.Amount))
+	}
+
+	if txn.Sender == addr {
+		var spent sum
+		spent.add(uint64(txn.Fee), 1)
+		if txn.Type == types.PaymentTx && txn.Receiver != addr {
+			spent.add(uint64(txn.Amount), 1)
+		}
+		if spent.overflow || spent.total > balance {
+			return Projection{}, errInsufficientBalance
+		}
+		balance -= spent.total
+
+		if txn.Type == types.PaymentTx && !txn.CloseRemainderTo.IsZero() {
+			return Projection{Closed: true}, nil
+		}
+		if err := fp.apply(account, txn, params, apps); err != nil {
+			return Projection{}, err
+		}
+	}
+
+	minBalance, err := fp.minBalance(params)
+	if err != nil {
+		return Projection{}, err
+	}
+	return Projection{Balance: balance, MinBalance: minBalance}, nil
+}
+
+// apply updates the footprint with the effects of a transaction sent by the account
+func (fp *footprint) apply(account models.Account, txn types.Transaction, params consensus.Params, apps []models.Application) error {
+	switch txn.Type {
+	case types.AssetTransferTx:
+		id := uint64(txn.XferAsset)
+		switch {
+		case !txn.AssetCloseTo.IsZero() && txn.AssetSender.IsZero():
+			delete(fp.assets, id)
+		case txn.AssetReceiver == txn.Sender && txn.AssetSender.IsZero() && !fp.assets[id]:
+			fp.assets[id] = true
+		}
+	case types.AssetConfigTx:
+		if txn.ConfigAsset == 0 {
+			// the id of the new asset is not known yet, any unused key will do
+			fp.assets[^uint64(0)-uint64(len(fp.assets))] = true
+		} else if txn.AssetParams.IsZero() {
+			for _, asset := range account.CreatedAssets {
+				if asset.Index == uint64(txn.ConfigAsset) {
+					delete(fp.assets, asset.Index)
+				}
+			}
+		}
+	case types.ApplicationCallTx:
+		if err := fp.applyApplicationCall(account, txn, apps); err != nil {
+			return err
+		}
+	}
+	if fp.created > params.MaxAppsCreated {
+		return fmt.Errorf("account would have created %d applications, at most %d allowed", fp.created, params.MaxAppsCreated)
+	}
+	if fp.optedIn > params.MaxAppsOptedIn {
+		return fmt.Errorf("account would be opted in to %d applications, at most %d allowed", fp.optedIn, params.MaxAppsOptedIn)
+	}
+	if len(fp.assets) > params.MaxAssetsPerAccount {
+		return fmt.Errorf("account would hold %d assets, at most %d allowed", len(fp.assets), params.MaxAssetsPerAccount)
+	}
+	return nil
+}
+
+func (fp *footprint) applyApplicationCall(account models.Account, txn types.Transaction, apps []models.Application) error {
+	toModel := func(schema types.StateSchema) models.ApplicationStateSchema {
+		return models.ApplicationStateSchema{NumUint: schema.NumUint, NumByteSlice: schema.NumByteSlice}
+	}
+	id := uint64(txn.ApplicationID)
+
+	if id == 0 {
+		fp.created++
+		fp.addSchema(toModel(txn.GlobalStateSchema))
+		if txn.OnCompletion == types.OptInOC {
+			fp.optedIn++
+			fp.addSchema(toModel(txn.LocalStateSchema))
+		}
+		return nil
+	}
+
+	switch txn.OnCompletion {
+	case types.OptInOC:
+		for _, local := range account.AppsLocalState {
+			if local.Id == id {
+				return nil
+			}
+		}
+		for _, app := range apps {
+			if app.Id == id {
+				fp.optedIn++
+				fp.addSchema(app.Params.LocalStateSchema)
+				return nil
+			}
+		}
+		return fmt.Errorf("parameters of application %d are needed to learn its local schema", id)
+	case types.CloseOutOC, types.ClearStateOC:
+		for _, local := range account.AppsLocalState {
+			if local.Id == id {
+				fp.optedIn--
+				fp.removeSchema(local.Schema)
+			}
+		}
+	case types.DeleteApplicationOC:
+		for _, app := range account.CreatedApps {
+			if app.Id == id {
+				fp.created--
+				fp.removeSchema(app.Params.GlobalStateSchema)
+				fp.extraPages -= app.Params.ExtraProgramPages
+			}
+		}
+	}
+	return nil
+}
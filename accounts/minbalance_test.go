@@ -0,0 +1,89 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var testParams = types.SuggestedParams{
+	Fee:             1000,
+	FlatFee:         true,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     []byte("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	FirstRoundValid: 100,
+	LastRoundValid:  1100,
+}
+
+func TestMinBalance(t *testing.T) {
+	params := consensus.LookupOrLatest(consensus.V28)
+	account := models.Account{
+		Address:       crypto.GenerateAccount().Address.String(),
+		Amount:        10000000,
+		Assets:        []models.AssetHolding{{AssetId: 1}, {AssetId: 2}},
+		CreatedAssets: []models.Asset{{Index: 2}},
+		CreatedApps: []models.Application{{Id: 10, Params: models.ApplicationParams{
+			GlobalStateSchema: models.ApplicationStateSchema{NumUint: 2, NumByteSlice: 1},
+			ExtraProgramPages: 1,
+		}}},
+		AppsLocalState: []models.ApplicationLocalState{{Id: 11, Schema: models.ApplicationStateSchema{NumUint: 1}}},
+	}
+	minBalance, err := MinBalance(account, params)
+	require.NoError(t, err)
+	expected := uint64(100000*3 + // account and two assets
+		100000*2 + // created app and its extra page
+		100000 + // opt-in
+		28500*3 + // three uints
+		50000) // one byte slice
+	require.Equal(t, expected, minBalance)
+
+	// the totals reported by algod take precedence over the listed apps
+	account.AppsTotalSchema = models.ApplicationStateSchema{NumUint: 3, NumByteSlice: 1}
+	fromTotals, err := MinBalance(account, params)
+	require.NoError(t, err)
+	require.Equal(t, minBalance, fromTotals)
+}
+
+func TestMinBalanceAfter(t *testing.T) {
+	params := consensus.LookupOrLatest(consensus.V28)
+	owner := crypto.GenerateAccount()
+	account := models.Account{Address: owner.Address.String(), Amount: 301000}
+
+	optin, err := future.MakeAssetAcceptanceTxn(owner.Address.String(), nil, testParams, 7)
+	require.NoError(t, err)
+	optin.Fee = 1000
+	p, err := MinBalanceAfter(account, optin, params)
+	require.NoError(t, err)
+	require.Equal(t, Projection{Balance: 300000, MinBalance: 200000}, p)
+	require.Equal(t, uint64(100000), p.Spendable())
+	require.True(t, p.Affordable())
+
+	appOptIn, err := future.MakeApplicationOptInTx(12, nil, nil, nil, nil, testParams, owner.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+	require.NoError(t, err)
+	_, err = MinBalanceAfter(account, appOptIn, params)
+	require.Error(t, err)
+	app := models.Application{Id: 12, Params: models.ApplicationParams{LocalStateSchema: models.ApplicationStateSchema{NumByteSlice: 3}}}
+	p, err = MinBalanceAfter(account, appOptIn, params, app)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100000+100000+3*50000), p.MinBalance)
+	require.False(t, p.Affordable())
+
+	// receiving a payment raises the balance only
+	payer := crypto.GenerateAccount()
+	pay, err := future.MakePaymentTxn(payer.Address.String(), owner.Address.String(), 5000, nil, "", testParams)
+	require.NoError(t, err)
+	p, err = MinBalanceAfter(account, pay, params)
+	require.NoError(t, err)
+	require.Equal(t, uint64(306000), p.Balance)
+
+	pay, err = future.MakePaymentTxn(owner.Address.String(), payer.Address.String(), 301000, nil, "", testParams)
+	require.NoError(t, err)
+	_, err = MinBalanceAfter(account, pay, params)
+	require.Error(t, err)
+}
@@ -0,0 +1,416 @@
+package airdrop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/client/v2/indexer"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/lease"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+// network is a fake algod and indexer confirming the groups it receives in
+// the next round
+type network struct {
+	mu        sync.Mutex
+	round     uint64
+	confirmed map[string]uint64
+	optedIn   map[uint64]map[string]bool
+	payments  []types.Transaction
+	submitted int
+
+	// dropping loses the groups received, and hidingPending answers that
+	// confirmed transactions are unknown to the pool
+	dropping      bool
+	hidingPending bool
+}
+
+func newNetwork() *network {
+	return &network{
+		round:     100,
+		confirmed: make(map[string]uint64),
+		optedIn:   make(map[uint64]map[string]bool),
+	}
+}
+
+func (n *network) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		switch {
+		case r.URL.Path == "/v2/transactions/params":
+			json.NewEncoder(w).Encode(models.TransactionParams{
+				GenesisID: "test", Genesishash: []byte("01234567890123456789012345678901"), LastRound: n.round, MinFee: 1000,
+			})
+		case r.URL.Path == "/v2/status":
+			json.NewEncoder(w).Encode(models.NodeStatus{LastRound: n.round})
+		case strings.HasPrefix(r.URL.Path, "/v2/status/wait-for-block-after/"):
+			n.round++
+			json.NewEncoder(w).Encode(models.NodeStatus{LastRound: n.round})
+		case r.URL.Path == "/v2/transactions" && r.Method == http.MethodPost:
+			if err := n.submit(t, r.Body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, err)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"txId": "ok"})
+		case strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/"):
+			round, ok := n.confirmed[strings.TrimPrefix(r.URL.Path, "/v2/transactions/pending/")]
+			if !ok || n.hidingPending {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(msgpack.Encode(models.PendingTransactionInfoResponse{ConfirmedRound: round}))
+		case r.URL.Path == "/v2/transactions":
+			response := models.TransactionsResponse{CurrentRound: n.round}
+			txid := r.URL.Query().Get("txid")
+			if round, ok := n.confirmed[txid]; ok {
+				response.Transactions = []models.Transaction{{Id: txid, ConfirmedRound: round}}
+			}
+			json.NewEncoder(w).Encode(response)
+		case strings.HasSuffix(r.URL.Path, "/balances"):
+			var assetID uint64
+			fmt.Sscanf(r.URL.Path, "/v2/assets/%d/balances", &assetID)
+			response := models.AssetBalancesResponse{CurrentRound: n.round}
+			for addr := range n.optedIn[assetID] {
+				response.Balances = append(response.Balances, models.MiniAssetHolding{Address: addr})
+			}
+			json.NewEncoder(w).Encode(response)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+// submit confirms a group in the next round, rejecting transactions already
+// confirmed or not valid in that round
+func (n *network) submit(t *testing.T, body io.Reader) error {
+	n.submitted++
+	dec := msgpack.NewDecoder(body)
+	var group []types.Transaction
+	for {
+		var stx types.SignedTxn
+		err := dec.Decode(&stx)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		group = append(group, stx.Txn)
+	}
+	for _, txn := range group {
+		txid := crypto.TransactionIDString(txn)
+		if _, ok := n.confirmed[txid]; ok {
+			return fmt.Errorf("transaction %s already in ledger", txid)
+		}
+		if txn.FirstValid > types.Round(n.round+1) || txn.LastValid < types.Round(n.round+1) {
+			return fmt.Errorf("transaction %s is not valid in round %d", txid, n.round+1)
+		}
+	}
+	if n.dropping {
+		return nil
+	}
+	for _, txn := range group {
+		n.confirmed[crypto.TransactionIDString(txn)] = n.round + 1
+		n.payments = append(n.payments, txn)
+	}
+	return nil
+}
+
+// paid returns the number of payments to each receiver
+func (n *network) paid() map[types.Address]int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	paid := make(map[types.Address]int)
+	for _, txn := range n.payments {
+		if txn.Type == types.PaymentTx {
+			paid[txn.Receiver]++
+		} else {
+			paid[txn.AssetReceiver]++
+		}
+	}
+	return paid
+}
+
+type fixture struct {
+	net     *network
+	algod   *algod.Client
+	indexer *indexer.Client
+	sender  crypto.Account
+	path    string
+}
+
+func newFixture(t *testing.T) (*fixture, func()) {
+	net := newNetwork()
+	server := httptest.NewServer(net.handler(t))
+	algodClient, err := algod.MakeClient(server.URL, "")
+	require.NoError(t, err)
+	indexerClient, err := indexer.MakeClient(server.URL, "")
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "airdrop")
+	require.NoError(t, err)
+	f := &fixture{
+		net:     net,
+		algod:   algodClient,
+		indexer: indexerClient,
+		sender:  crypto.GenerateAccount(),
+		path:    filepath.Join(dir, "journal"),
+	}
+	return f, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// engine returns an engine with a journal freshly opened, as after a restart
+func (f *fixture) engine(t *testing.T, config Config) (*Engine, func()) {
+	journal, err := OpenJournal(f.path)
+	require.NoError(t, err)
+	config.Sender = f.sender.Address
+	config.Signer = future.BasicAccountTransactionSigner{Account: f.sender}
+	config.Namespace = "airdrop-1"
+	return NewEngine(f.algod, f.indexer, journal, config), func() { journal.Close() }
+}
+
+func makeRows(prefix string, algos, assets int) []Row {
+	var rows []Row
+	for i := 0; i < algos+assets; i++ {
+		row := Row{ID: fmt.Sprintf("%s %d", prefix, i), Receiver: crypto.GenerateAccount().Address, Amount: uint64(i + 1)}
+		if i >= algos {
+			row.AssetID = 7
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func requirePaidOnce(t *testing.T, net *network, rows []Row) {
+	paid := net.paid()
+	require.Len(t, paid, len(rows))
+	for _, row := range rows {
+		require.Equal(t, 1, paid[row.Receiver], row.ID)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	a, b := crypto.GenerateAccount().Address, crypto.GenerateAccount().Address
+	rows, err := ReadCSV(strings.NewReader(fmt.Sprintf(`address,amount,asset,id
+# first wave
+%s,1000
+%s, 25, 7, bonus-1
+`, a, b)))
+	require.NoError(t, err)
+	require.Equal(t, []Row{
+		{ID: "record 1", Receiver: a, Amount: 1000},
+		{ID: "bonus-1", Receiver: b, Amount: 25, AssetID: 7},
+	}, rows)
+
+	invalid := []string{
+		"not-an-address,1",
+		a.String() + ",-1",
+		a.String() + ",1.5",
+		a.String() + ",1,asset",
+		a.String(),
+		a.String() + ",1,2,3,4",
+	}
+	for _, csv := range invalid {
+		_, err := ReadCSV(strings.NewReader(csv))
+		require.Error(t, err, csv)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	f, closeFixture := newFixture(t)
+	defer closeFixture()
+	engine, closeEngine := f.engine(t, Config{})
+	defer closeEngine()
+	ctx := context.Background()
+
+	rows := makeRows("row", 30, 10)
+	plan, err := engine.Plan(ctx, rows)
+	require.NoError(t, err)
+	require.Len(t, plan.Groups, 3)
+	require.Len(t, plan.Groups[0].Rows, 16)
+	require.Len(t, plan.Groups[2].Rows, 8)
+	require.Equal(t, uint64(40*1000), plan.Fee)
+	require.Equal(t, uint64(30*31/2), plan.Algos)
+	require.Equal(t, uint64(40*41/2-30*31/2), plan.Assets[7])
+
+	// rows recorded as paid or pending are not planned again
+	require.NoError(t, engine.journal.Record(Entry{Event: EventConfirmed, IDs: []string{"row 0"}, TxIDs: []string{"a"}}))
+	require.NoError(t, engine.journal.Record(Entry{Event: EventSubmitted, IDs: []string{"row 1"}, TxIDs: []string{"b"}}))
+	plan, err = engine.Plan(ctx, rows)
+	require.NoError(t, err)
+	require.Equal(t, rows[:1], plan.Paid)
+	require.Equal(t, rows[1:2], plan.Pending)
+	require.Len(t, plan.Groups[2].Rows, 6)
+
+	_, err = engine.Plan(ctx, append(rows, rows[5]))
+	require.Error(t, err)
+	_, err = engine.Plan(ctx, []Row{{ID: "zero", Receiver: f.sender.Address}})
+	require.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	f, closeFixture := newFixture(t)
+	defer closeFixture()
+	engine, closeEngine := f.engine(t, Config{Concurrency: 3})
+	defer closeEngine()
+	ctx := context.Background()
+	rows := makeRows("row", 30, 5)
+
+	// nothing is paid while receivers of assets have not opted in
+	f.net.optedIn[7] = make(map[string]bool)
+	for _, row := range rows[30:34] {
+		f.net.optedIn[7][row.Receiver.String()] = true
+	}
+	_, err := engine.Run(ctx, rows)
+	require.IsType(t, &OptInError{}, err)
+	require.Equal(t, rows[34:], err.(*OptInError).Rows)
+	require.Zero(t, f.net.submitted)
+
+	f.net.optedIn[7][rows[34].Receiver.String()] = true
+	result, err := engine.Run(ctx, rows)
+	require.NoError(t, err)
+	require.Len(t, result.Paid, 35)
+	require.Equal(t, uint64(35*1000), result.Fee)
+	require.Equal(t, 3, f.net.submitted)
+	requirePaidOnce(t, f.net, rows)
+	for _, txn := range f.net.payments {
+		if txn.Receiver == rows[0].Receiver {
+			require.Equal(t, lease.Derive("airdrop-1", []byte("row 0")), txn.Lease)
+		}
+		require.NotEqual(t, [32]byte{}, txn.Lease)
+	}
+
+	// running again pays nothing
+	result, err = engine.Run(ctx, rows)
+	require.NoError(t, err)
+	require.Empty(t, result.Paid)
+	require.Len(t, result.Skipped, 35)
+	require.Equal(t, 3, f.net.submitted)
+}
+
+func TestResume(t *testing.T) {
+	f, closeFixture := newFixture(t)
+	defer closeFixture()
+	ctx := context.Background()
+	rows := makeRows("row", 40, 0)
+
+	// the first group is lost, which stops the run
+	f.net.dropping = true
+	engine, closeEngine := f.engine(t, Config{Concurrency: 1, WaitRounds: 2})
+	_, err := engine.Run(ctx, rows)
+	require.Error(t, err)
+	closeEngine()
+	require.Empty(t, f.net.payments)
+
+	// the next run resubmits it while it is valid
+	f.net.dropping = false
+	engine, closeEngine = f.engine(t, Config{})
+	result, err := engine.Run(ctx, rows)
+	require.NoError(t, err)
+	require.Len(t, result.Paid, 24)
+	closeEngine()
+	requirePaidOnce(t, f.net, rows)
+
+	// a group lost until its LastValid round expires, and is paid again
+	rows = append(rows, makeRows("late", 16, 0)...)
+	f.net.dropping = true
+	engine, closeEngine = f.engine(t, Config{WaitRounds: 2})
+	_, err = engine.Run(ctx, rows)
+	require.Error(t, err)
+	closeEngine()
+	pending, err := OpenJournal(f.path)
+	require.NoError(t, err)
+	expired := pending.Pending()
+	require.Len(t, expired, 1)
+	pending.Close()
+
+	f.net.dropping = false
+	f.net.round = expired[0].LastValid + 1
+	engine, closeEngine = f.engine(t, Config{})
+	result, err = engine.Run(ctx, rows)
+	require.NoError(t, err)
+	require.Len(t, result.Paid, 16)
+	latest, ok := engine.journal.Latest("late 0")
+	require.True(t, ok)
+	require.Equal(t, EventConfirmed, latest.Event)
+	closeEngine()
+	requirePaidOnce(t, f.net, rows)
+}
+
+func TestResumeConfirmedGroup(t *testing.T) {
+	f, closeFixture := newFixture(t)
+	defer closeFixture()
+	ctx := context.Background()
+	rows := makeRows("row", 3, 0)
+
+	// the group is confirmed but the run does not learn it
+	f.net.hidingPending = true
+	engine, closeEngine := f.engine(t, Config{WaitRounds: 2})
+	_, err := engine.Run(ctx, rows)
+	require.Error(t, err)
+	closeEngine()
+	requirePaidOnce(t, f.net, rows)
+
+	// the indexer shows it was confirmed, so it is not paid again
+	engine, closeEngine = f.engine(t, Config{})
+	defer closeEngine()
+	result, err := engine.Run(ctx, rows)
+	require.NoError(t, err)
+	require.Empty(t, result.Paid)
+	require.Len(t, result.Skipped, 3)
+	requirePaidOnce(t, f.net, rows)
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "airdrop")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal")
+
+	journal, err := OpenJournal(path)
+	require.NoError(t, err)
+	submitted := Entry{Event: EventSubmitted, IDs: []string{"a", "b"}, TxIDs: []string{"t1", "t2"}, LastValid: 10, Signed: []byte{1, 2}}
+	require.NoError(t, journal.Record(submitted))
+	require.NoError(t, journal.Record(Entry{Event: EventSubmitted, IDs: []string{"c"}, TxIDs: []string{"t3"}}))
+	require.NoError(t, journal.Record(Entry{Event: EventConfirmed, IDs: []string{"c"}, TxIDs: []string{"t3"}, Round: 5}))
+	require.NoError(t, journal.Close())
+
+	// a line cut short by a crash is discarded
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"event":"confirmed","ids":["a"`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	journal, err = OpenJournal(path)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{submitted}, journal.Pending())
+	latest, ok := journal.Latest("c")
+	require.True(t, ok)
+	require.Equal(t, uint64(5), latest.Round)
+	require.NoError(t, journal.Record(Entry{Event: EventExpired, IDs: []string{"a", "b"}, TxIDs: []string{"t1", "t2"}}))
+	require.NoError(t, journal.Close())
+
+	journal, err = OpenJournal(path)
+	require.NoError(t, err)
+	defer journal.Close()
+	require.Empty(t, journal.Pending())
+}
@@ -0,0 +1,482 @@
+// Package airdrop pays many receivers in Algos or assets, exactly once.
+//
+// An Engine plans the payments of a list of rows, read from a CSV file with
+// ReadCSV or built in code, into groups of up to the maximum group size of
+// the protocol, and submits the groups concurrently. Receivers of assets
+// must have opted in: Run checks it on the indexer before paying anything
+// and fails with an *OptInError otherwise.
+//
+// Every payment carries a lease derived from the namespace of the airdrop
+// and the ID of its row, and every group is recorded in a Journal, with its
+// signed transactions, before being sent. When a run is interrupted, the next
+// run first settles the groups left pending: it resubmits the same signed
+// transactions while they are valid, and pays their rows again only once the
+// indexer shows that they were not confirmed by their LastValid round. A row
+// is therefore never paid twice, whenever the process crashes.
+package airdrop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/indexer"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/lease"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/jffp113/go-algorand-sdk/validate"
+)
+
+const (
+	// defaultConcurrency is the number of groups submitted at once
+	defaultConcurrency = 4
+
+	// defaultWaitRounds is the number of rounds a group waits to be confirmed
+	defaultWaitRounds = 10
+)
+
+// Config configures an airdrop
+type Config struct {
+	// Sender pays every row, with transactions signed by Signer
+	Sender types.Address
+	Signer future.TransactionSigner
+
+	// Namespace identifies the airdrop: the leases of its payments are
+	// derived from it and the IDs of the rows, see lease.Derive
+	Namespace string
+
+	// Note is the note of every payment
+	Note []byte
+
+	// GroupSize is the number of payments per group. Zero or a value above
+	// the maximum group size of the protocol means the maximum group size.
+	GroupSize int
+
+	// Concurrency is the number of groups submitted at once, 4 by default
+	Concurrency int
+
+	// WaitRounds is the number of rounds to wait for each group to be
+	// confirmed, 10 by default
+	WaitRounds uint64
+}
+
+// Engine runs an airdrop
+type Engine struct {
+	algod   *algod.Client
+	indexer *indexer.Client
+	journal *Journal
+	config  Config
+	leases  *lease.Registry
+}
+
+// NewEngine returns an Engine running the airdrop configured by config and
+// recording its progress in journal
+func NewEngine(algodClient *algod.Client, indexerClient *indexer.Client, journal *Journal, config Config) *Engine {
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaultConcurrency
+	}
+	if config.WaitRounds == 0 {
+		config.WaitRounds = defaultWaitRounds
+	}
+	return &Engine{
+		algod:   algodClient,
+		indexer: indexerClient,
+		journal: journal,
+		config:  config,
+		leases:  lease.MakeRegistry(),
+	}
+}
+
+// Group is a group of payments planned
+type Group struct {
+	Rows []Row
+
+	// Fee is the total fee of the group at the suggested params of the plan
+	Fee uint64
+}
+
+// Plan is the payments an airdrop still has to make
+type Plan struct {
+	Groups []Group
+
+	// Paid are the rows the journal records as paid, and Pending the rows
+	// whose group was submitted but is not settled yet
+	Paid    []Row
+	Pending []Row
+
+	// Fee is the total fee of the groups, Algos the total amount of
+	// microAlgos paid and Assets the total amount paid of each asset
+	Fee    uint64
+	Algos  uint64
+	Assets map[uint64]uint64
+}
+
+// OptInError is returned when receivers of assets have not opted in
+type OptInError struct {
+	Rows []Row
+}
+
+func (e *OptInError) Error() string {
+	receivers := make([]string, len(e.Rows))
+	for i, row := range e.Rows {
+		receivers[i] = fmt.Sprintf("%s (asset %d)", row.Receiver, row.AssetID)
+	}
+	return fmt.Sprintf("%d receivers have not opted in: %s", len(e.Rows), strings.Join(receivers, ", "))
+}
+
+// build returns the payment of row, with its lease
+func (e *Engine) build(row Row, sp types.SuggestedParams) (types.Transaction, error) {
+	var txn types.Transaction
+	var err error
+	if row.AssetID == 0 {
+		txn, err = future.MakePaymentTxn(e.config.Sender.String(), row.Receiver.String(), row.Amount, e.config.Note, "", sp)
+	} else {
+		txn, err = future.MakeAssetTransferTxn(e.config.Sender.String(), row.Receiver.String(), row.Amount, e.config.Note, sp, "", row.AssetID)
+	}
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("row %s: %v", row.ID, err)
+	}
+	if err := lease.Apply(&txn, e.config.Namespace, []byte(row.ID), sp); err != nil {
+		return types.Transaction{}, fmt.Errorf("row %s: %v", row.ID, err)
+	}
+	return txn, nil
+}
+
+// Plan groups the rows left to pay and computes their fees at the current
+// suggested params
+func (e *Engine) Plan(ctx context.Context, rows []Row) (Plan, error) {
+	if e.config.Namespace == "" {
+		return Plan{}, fmt.Errorf("airdrop has no namespace")
+	}
+	sp, err := e.algod.SuggestedParams().Do(ctx)
+	if err != nil {
+		return Plan{}, err
+	}
+	params, err := consensus.FromSuggestedParams(sp)
+	if err != nil {
+		return Plan{}, err
+	}
+	size := e.config.GroupSize
+	if size <= 0 || size > params.MaxTxGroupSize {
+		size = params.MaxTxGroupSize
+	}
+
+	plan := Plan{Assets: make(map[uint64]uint64)}
+	ids := make(map[string]bool, len(rows))
+	var group Group
+	for _, row := range rows {
+		if row.ID == "" {
+			return Plan{}, fmt.Errorf("row paying %s has no ID", row.Receiver)
+		}
+		if ids[row.ID] {
+			return Plan{}, fmt.Errorf("row %s is duplicated", row.ID)
+		}
+		ids[row.ID] = true
+		if row.Receiver.IsZero() {
+			return Plan{}, fmt.Errorf("row %s has no receiver", row.ID)
+		}
+		if row.Amount == 0 {
+			return Plan{}, fmt.Errorf("row %s has no amount", row.ID)
+		}
+		if entry, ok := e.journal.Latest(row.ID); ok {
+			switch entry.Event {
+			case EventConfirmed:
+				plan.Paid = append(plan.Paid, row)
+				continue
+			case EventSubmitted:
+				plan.Pending = append(plan.Pending, row)
+				continue
+			}
+		}
+
+		txn, err := e.build(row, sp)
+		if err != nil {
+			return Plan{}, err
+		}
+		var overflowed bool
+		if row.AssetID == 0 {
+			plan.Algos, overflowed = types.OAdd(plan.Algos, row.Amount)
+		} else {
+			plan.Assets[row.AssetID], overflowed = types.OAdd(plan.Assets[row.AssetID], row.Amount)
+		}
+		if overflowed {
+			return Plan{}, fmt.Errorf("row %s overflows the total amount paid", row.ID)
+		}
+		group.Rows = append(group.Rows, row)
+		group.Fee += uint64(txn.Fee)
+		plan.Fee += uint64(txn.Fee)
+		if len(group.Rows) == size {
+			plan.Groups = append(plan.Groups, group)
+			group = Group{}
+		}
+	}
+	if len(group.Rows) > 0 {
+		plan.Groups = append(plan.Groups, group)
+	}
+	return plan, nil
+}
+
+// CheckOptIns returns the rows paying assets to receivers which have not
+// opted in the asset, according to the indexer
+func (e *Engine) CheckOptIns(ctx context.Context, rows []Row) ([]Row, error) {
+	holders := make(map[uint64]map[string]bool)
+	var missing []Row
+	for _, row := range rows {
+		if row.AssetID == 0 {
+			continue
+		}
+		if _, ok := holders[row.AssetID]; !ok {
+			h, err := e.holders(ctx, row.AssetID)
+			if err != nil {
+				return nil, err
+			}
+			holders[row.AssetID] = h
+		}
+		if !holders[row.AssetID][row.Receiver.String()] {
+			missing = append(missing, row)
+		}
+	}
+	return missing, nil
+}
+
+// holders returns the addresses opted in the asset
+func (e *Engine) holders(ctx context.Context, assetID uint64) (map[string]bool, error) {
+	holders := make(map[string]bool)
+	next := ""
+	for {
+		response, err := e.indexer.LookupAssetBalances(assetID).NextToken(next).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("looking up holders of asset %d: %v", assetID, err)
+		}
+		for _, balance := range response.Balances {
+			holders[balance.Address] = true
+		}
+		if response.NextToken == "" || len(response.Balances) == 0 {
+			return holders, nil
+		}
+		next = response.NextToken
+	}
+}
+
+// Result is the outcome of a run
+type Result struct {
+	// Paid are the rows paid by the run, and Skipped the rows paid by
+	// previous runs
+	Paid    []Row
+	Skipped []Row
+
+	// Fee is the total fee paid by the run
+	Fee uint64
+}
+
+// Run settles the groups left pending by previous runs, then pays the rows
+// not paid yet. It stops at the first group failing and returns what was
+// paid so far: running again with the same rows and journal resumes the
+// airdrop.
+func (e *Engine) Run(ctx context.Context, rows []Row) (Result, error) {
+	if err := e.Resume(ctx); err != nil {
+		return Result{}, err
+	}
+	plan, err := e.Plan(ctx, rows)
+	if err != nil {
+		return Result{}, err
+	}
+	var unpaid []Row
+	for _, group := range plan.Groups {
+		unpaid = append(unpaid, group.Rows...)
+	}
+	missing, err := e.CheckOptIns(ctx, unpaid)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(missing) > 0 {
+		return Result{}, &OptInError{Rows: missing}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	result := Result{Skipped: plan.Paid}
+	var mu sync.Mutex
+	var firstErr error
+	groups := make(chan Group)
+	var wg sync.WaitGroup
+	for i := 0; i < e.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range groups {
+				fee, err := e.pay(ctx, group.Rows)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					cancel()
+				} else {
+					result.Paid = append(result.Paid, group.Rows...)
+					result.Fee += fee
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, group := range plan.Groups {
+		select {
+		case groups <- group:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(groups)
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return result, firstErr
+}
+
+// pay submits a group paying rows and waits for its confirmation. It returns
+// the fee of the group.
+func (e *Engine) pay(ctx context.Context, rows []Row) (uint64, error) {
+	sp, err := e.algod.SuggestedParams().Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	params, err := consensus.FromSuggestedParams(sp)
+	if err != nil {
+		return 0, err
+	}
+	txns := make([]types.Transaction, len(rows))
+	ids := make([]string, len(rows))
+	var fee uint64
+	for i, row := range rows {
+		if txns[i], err = e.build(row, sp); err != nil {
+			return 0, err
+		}
+		ids[i] = row.ID
+		fee += uint64(txns[i].Fee)
+	}
+	if len(txns) > 1 {
+		gid, err := crypto.ComputeGroupID(txns)
+		if err != nil {
+			return 0, err
+		}
+		for i := range txns {
+			txns[i].Group = gid
+		}
+	}
+	if err := validate.Group(txns, params); err != nil {
+		return 0, fmt.Errorf("group of %s: %v", ids[0], err)
+	}
+
+	// the leases are held by this process until the group is settled, so
+	// that a row is never in two groups in flight
+	for i, txn := range txns {
+		if err := e.leases.Acquire(txn); err != nil {
+			for _, acquired := range txns[:i] {
+				e.leases.Release(acquired)
+			}
+			return 0, fmt.Errorf("row %s: %v", ids[i], err)
+		}
+	}
+
+	indexes := make([]int, len(txns))
+	txids := make([]string, len(txns))
+	for i, txn := range txns {
+		indexes[i] = i
+		txids[i] = crypto.TransactionIDString(txn)
+	}
+	entry := Entry{
+		Event:     EventSubmitted,
+		IDs:       ids,
+		TxIDs:     txids,
+		LastValid: uint64(txns[0].LastValid),
+	}
+	signed, err := e.config.Signer.SignTransactions(txns, indexes)
+	if err == nil {
+		entry.Signed = bytes.Join(signed, nil)
+		err = e.journal.Record(entry)
+	}
+	if err != nil {
+		// nothing was sent, the rows can be paid by another group
+		for _, txn := range txns {
+			e.leases.Release(txn)
+		}
+		return 0, err
+	}
+
+	// once recorded, the group is settled by the next run if anything below
+	// fails, even if its submission was rejected
+	if _, err := e.algod.SendRawTransaction(entry.Signed).Do(ctx); err != nil {
+		return 0, fmt.Errorf("submitting group of %s: %v", ids[0], err)
+	}
+	confirmed, err := future.WaitForConfirmation(e.algod, txids[0], e.config.WaitRounds, ctx)
+	if err != nil {
+		return 0, fmt.Errorf("group of %s: %v", ids[0], err)
+	}
+	return fee, e.settled(entry, EventConfirmed, confirmed.ConfirmedRound)
+}
+
+// settled records the outcome of a submitted group
+func (e *Engine) settled(submitted Entry, event string, round uint64) error {
+	return e.journal.Record(Entry{Event: event, IDs: submitted.IDs, TxIDs: submitted.TxIDs, Round: round})
+}
+
+// Resume settles the groups the journal records as submitted but neither
+// confirmed nor expired, typically after a run was interrupted. Groups still
+// valid are resubmitted as they were signed and waited for. The others are
+// looked up on the indexer, and marked expired once the indexer has passed
+// their LastValid round without them, so that their rows are paid again.
+func (e *Engine) Resume(ctx context.Context) error {
+	for _, entry := range e.journal.Pending() {
+		if err := e.settle(ctx, entry); err != nil {
+			return fmt.Errorf("settling group of %s: %v", entry.IDs[0], err)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) settle(ctx context.Context, entry Entry) error {
+	txid := entry.TxIDs[0]
+	if info, _, err := e.algod.PendingTransactionInformation(txid).Do(ctx); err == nil && info.ConfirmedRound > 0 {
+		return e.settled(entry, EventConfirmed, info.ConfirmedRound)
+	}
+
+	status, err := e.algod.Status().Do(ctx)
+	if err != nil {
+		return err
+	}
+	if status.LastRound < entry.LastValid {
+		// resubmitting the same signed transactions cannot pay twice: the
+		// network rejects them if they were confirmed already
+		e.algod.SendRawTransaction(entry.Signed).Do(ctx)
+		confirmed, err := future.WaitForConfirmation(e.algod, txid, entry.LastValid-status.LastRound, ctx)
+		if err == nil {
+			return e.settled(entry, EventConfirmed, confirmed.ConfirmedRound)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	// past its LastValid round, the group either was confirmed or never will
+	// be
+	response, err := e.indexer.SearchForTransactions().TXID(txid).Do(ctx)
+	if err != nil {
+		return err
+	}
+	if len(response.Transactions) > 0 {
+		return e.settled(entry, EventConfirmed, response.Transactions[0].ConfirmedRound)
+	}
+	if response.CurrentRound <= entry.LastValid {
+		return fmt.Errorf("group is valid until round %d and the indexer is at round %d, retry later", entry.LastValid, response.CurrentRound)
+	}
+	return e.settled(entry, EventExpired, 0)
+}
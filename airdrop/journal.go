@@ -0,0 +1,142 @@
+package airdrop
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Events recorded in a journal
+const (
+	// EventSubmitted is recorded before a group is sent, with its signed
+	// transactions
+	EventSubmitted = "submitted"
+
+	// EventConfirmed is recorded once a group is confirmed
+	EventConfirmed = "confirmed"
+
+	// EventExpired is recorded once a submitted group can no longer be
+	// confirmed, making its rows payable again
+	EventExpired = "expired"
+)
+
+// Entry is an event of a group of payments
+type Entry struct {
+	Event string `json:"event"`
+
+	// IDs are the rows paid by the group
+	IDs   []string `json:"ids"`
+	TxIDs []string `json:"txids"`
+
+	// LastValid and Signed are set on submission: the group can be
+	// resubmitted as is until its LastValid round
+	LastValid uint64 `json:"last-valid,omitempty"`
+	Signed    []byte `json:"signed,omitempty"`
+
+	// Round is the round a confirmed group was confirmed in
+	Round uint64 `json:"round,omitempty"`
+}
+
+// Journal is an append-only log of the groups of an airdrop, one JSON entry
+// per line. Every entry is synced to disk before the operation it records
+// goes on, so a run interrupted at any point can be resumed without paying a
+// row twice. It is safe for concurrent use.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+
+	// latest is the latest entry of each row
+	latest map[string]Entry
+}
+
+// OpenJournal opens the journal stored at path, creating it if needed. A
+// last line cut short by a crash while writing it is discarded.
+func OpenJournal(path string) (*Journal, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	j := &Journal{latest: make(map[string]Entry)}
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	for i, line := range bytes.Split(data[:complete], []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", path, i+1, err)
+		}
+		j.apply(entry)
+	}
+
+	j.file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.file.Truncate(int64(complete)); err != nil {
+		j.file.Close()
+		return nil, err
+	}
+	if _, err := j.file.Seek(int64(complete), 0); err != nil {
+		j.file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *Journal) apply(entry Entry) {
+	for _, id := range entry.IDs {
+		j.latest[id] = entry
+	}
+}
+
+// Record appends entry to the journal and syncs it to disk
+func (j *Journal) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	j.apply(entry)
+	return nil
+}
+
+// Latest returns the latest entry of the row id
+func (j *Journal) Latest(id string) (Entry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry, ok := j.latest[id]
+	return entry, ok
+}
+
+// Pending returns the groups submitted but neither confirmed nor expired
+func (j *Journal) Pending() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var pending []Entry
+	seen := make(map[string]bool)
+	for _, entry := range j.latest {
+		if entry.Event == EventSubmitted && !seen[entry.TxIDs[0]] {
+			seen[entry.TxIDs[0]] = true
+			pending = append(pending, entry)
+		}
+	}
+	sort.Slice(pending, func(a, b int) bool { return pending[a].TxIDs[0] < pending[b].TxIDs[0] })
+	return pending
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
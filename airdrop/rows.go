@@ -0,0 +1,92 @@
+package airdrop
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Row is a payment of an airdrop
+type Row struct {
+	// ID identifies the payment in the journal and derives its lease, so it
+	// must not change between runs of the same airdrop
+	ID       string
+	Receiver types.Address
+
+	// Amount is in base units: microAlgos, or units of the asset
+	Amount uint64
+
+	// AssetID is the asset to send, zero for Algos
+	AssetID uint64
+}
+
+// ReadCSV reads rows from r, one per record with the columns
+//
+//	address,amount[,asset[,id]]
+//
+// where amount is in base units and an empty or missing asset means Algos.
+// Records without an id are identified by their position, so such files
+// must only be appended to between runs. A first record starting with
+// "address" is a header, and lines starting with # are comments.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []Row
+	first := true
+	for n := 0; ; {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		header := first && strings.EqualFold(strings.TrimSpace(record[0]), "address")
+		first = false
+		if header {
+			continue
+		}
+		n++
+		row, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %v", n, err)
+		}
+		if row.ID == "" {
+			row.ID = "record " + strconv.Itoa(n)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseRecord(record []string) (Row, error) {
+	if len(record) < 2 || len(record) > 4 {
+		return Row{}, fmt.Errorf("%d columns instead of 2 to 4", len(record))
+	}
+	for i := range record {
+		record[i] = strings.TrimSpace(record[i])
+	}
+	var row Row
+	var err error
+	if row.Receiver, err = types.DecodeAddress(record[0]); err != nil {
+		return Row{}, fmt.Errorf("address: %v", err)
+	}
+	if row.Amount, err = strconv.ParseUint(record[1], 10, 64); err != nil {
+		return Row{}, fmt.Errorf("amount: %v", err)
+	}
+	if len(record) > 2 && record[2] != "" {
+		if row.AssetID, err = strconv.ParseUint(record[2], 10, 64); err != nil {
+			return Row{}, fmt.Errorf("asset: %v", err)
+		}
+	}
+	if len(record) > 3 {
+		row.ID = record[3]
+	}
+	return row, nil
+}
@@ -0,0 +1,357 @@
+// Package appcall encodes and decodes the arguments of application calls from
+// a JSON description of the methods of an application.
+//
+// Calls follow a simple dispatch convention. The first application argument
+// is the selector of the method, the first 4 bytes of the SHA512/256 hash of
+// its signature, e.g. "transfer(account,uint64)". Every method argument is
+// then passed as its own application argument:
+//
+//	uint64       8 bytes, big-endian, as read by btoi
+//	bool         a uint64 holding 0 or 1
+//	bytes        the bytes as is
+//	string       the UTF-8 bytes of the string
+//	address      the 32 bytes of the address
+//	account      1 byte, the index of the account in txn.Accounts. The sender
+//	             is index 0 and the referenced accounts start at 1.
+//	application  1 byte, the index of the application in txn.Applications.
+//	             The called application is index 0 and the referenced
+//	             applications start at 1.
+//	asset        1 byte, the index of the asset in txn.Assets, starting at 0
+//
+// References are added to the Accounts, ForeignApps and ForeignAssets fields
+// of the call, once per distinct value. The limits on the number of
+// arguments and references are not checked here; see package validate.
+//
+// Generate turns a description into Go client stubs with one typed function
+// per method.
+package appcall
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// ArgType is the type of a method argument
+type ArgType string
+
+const (
+	// Uint64 arguments are Go uint64 values
+	Uint64 ArgType = "uint64"
+
+	// Bool arguments are Go bool values
+	Bool ArgType = "bool"
+
+	// Bytes arguments are Go []byte values
+	Bytes ArgType = "bytes"
+
+	// String arguments are Go string values
+	String ArgType = "string"
+
+	// Address arguments are passed by value, as types.Address values
+	Address ArgType = "address"
+
+	// Account arguments are references to accounts, as types.Address values
+	Account ArgType = "account"
+
+	// Application arguments are references to applications, as uint64 application ids
+	Application ArgType = "application"
+
+	// Asset arguments are references to assets, as uint64 asset ids
+	Asset ArgType = "asset"
+)
+
+func (t ArgType) valid() bool {
+	switch t {
+	case Uint64, Bool, Bytes, String, Address, Account, Application, Asset:
+		return true
+	}
+	return false
+}
+
+// Encode returns the application call fields calling the method of
+// application appID with args. Each argument must have the Go type of its
+// ArgType; untyped integer constants are accepted for uint64, application and
+// asset arguments, and checksummed strings for address and account arguments.
+//
+// The sender is not known here, so every account argument is added to the
+// Accounts field; MakeCallTxn passes the sender as reference 0 instead.
+func (m Method) Encode(appID uint64, args ...interface{}) (types.ApplicationCallTxnFields, error) {
+	return m.encode(appID, nil, args)
+}
+
+func (m Method) encode(appID uint64, sender *types.Address, args []interface{}) (types.ApplicationCallTxnFields, error) {
+	var fields types.ApplicationCallTxnFields
+	if len(args) != len(m.Args) {
+		return fields, fmt.Errorf("method %s takes %d arguments, got %d", m.Name, len(m.Args), len(args))
+	}
+	onComplete, err := m.onCompletion()
+	if err != nil {
+		return fields, err
+	}
+	fields.ApplicationID = types.AppIndex(appID)
+	fields.OnCompletion = onComplete
+
+	selector := m.Selector()
+	fields.ApplicationArgs = append(fields.ApplicationArgs, selector[:])
+	for i, arg := range m.Args {
+		encoded, err := encodeArg(&fields, sender, arg.Type, args[i])
+		if err != nil {
+			return types.ApplicationCallTxnFields{}, fmt.Errorf("method %s: argument %s: %v", m.Name, arg.Name, err)
+		}
+		fields.ApplicationArgs = append(fields.ApplicationArgs, encoded)
+	}
+	return fields, nil
+}
+
+func encodeArg(fields *types.ApplicationCallTxnFields, sender *types.Address, argType ArgType, value interface{}) ([]byte, error) {
+	switch argType {
+	case Uint64:
+		v, err := toUint64(value)
+		if err != nil {
+			return nil, err
+		}
+		return encodeUint64(v), nil
+	case Bool:
+		v, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a bool, got %T", value)
+		}
+		if v {
+			return encodeUint64(1), nil
+		}
+		return encodeUint64(0), nil
+	case Bytes:
+		v, ok := value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("expected a []byte, got %T", value)
+		}
+		return v, nil
+	case String:
+		v, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", value)
+		}
+		return []byte(v), nil
+	case Address:
+		addr, err := toAddress(value)
+		if err != nil {
+			return nil, err
+		}
+		return addr[:], nil
+	case Account:
+		addr, err := toAddress(value)
+		if err != nil {
+			return nil, err
+		}
+		if sender != nil && addr == *sender {
+			return []byte{0}, nil
+		}
+		for i, account := range fields.Accounts {
+			if account == addr {
+				return []byte{byte(i + 1)}, nil
+			}
+		}
+		fields.Accounts = append(fields.Accounts, addr)
+		return referenceIndex(len(fields.Accounts))
+	case Application:
+		v, err := toUint64(value)
+		if err != nil {
+			return nil, err
+		}
+		if types.AppIndex(v) == fields.ApplicationID {
+			return []byte{0}, nil
+		}
+		for i, app := range fields.ForeignApps {
+			if app == types.AppIndex(v) {
+				return []byte{byte(i + 1)}, nil
+			}
+		}
+		fields.ForeignApps = append(fields.ForeignApps, types.AppIndex(v))
+		return referenceIndex(len(fields.ForeignApps))
+	case Asset:
+		v, err := toUint64(value)
+		if err != nil {
+			return nil, err
+		}
+		for i, asset := range fields.ForeignAssets {
+			if asset == types.AssetIndex(v) {
+				return []byte{byte(i)}, nil
+			}
+		}
+		fields.ForeignAssets = append(fields.ForeignAssets, types.AssetIndex(v))
+		return referenceIndex(len(fields.ForeignAssets) - 1)
+	}
+	return nil, fmt.Errorf("unknown type %q", argType)
+}
+
+func referenceIndex(i int) ([]byte, error) {
+	if i > 255 {
+		return nil, fmt.Errorf("too many references")
+	}
+	return []byte{byte(i)}, nil
+}
+
+func encodeUint64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func toUint64(value interface{}) (uint64, error) {
+	switch v := value.(type) {
+	case uint64:
+		return v, nil
+	case uint:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case int:
+		if v < 0 {
+			return 0, fmt.Errorf("negative value %d", v)
+		}
+		return uint64(v), nil
+	}
+	return 0, fmt.Errorf("expected a uint64, got %T", value)
+}
+
+func toAddress(value interface{}) (types.Address, error) {
+	switch v := value.(type) {
+	case types.Address:
+		return v, nil
+	case string:
+		return types.DecodeAddress(v)
+	}
+	return types.Address{}, fmt.Errorf("expected a types.Address, got %T", value)
+}
+
+// MakeCallTxn returns a transaction from sender calling the method of
+// application appID with args. See Encode for the accepted arguments.
+func (m Method) MakeCallTxn(sender types.Address, appID uint64, sp types.SuggestedParams, args ...interface{}) (types.Transaction, error) {
+	fields, err := m.encode(appID, &sender, args)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	accounts := make([]string, len(fields.Accounts))
+	for i, addr := range fields.Accounts {
+		accounts[i] = addr.String()
+	}
+	foreignApps := make([]uint64, len(fields.ForeignApps))
+	for i, app := range fields.ForeignApps {
+		foreignApps[i] = uint64(app)
+	}
+	foreignAssets := make([]uint64, len(fields.ForeignAssets))
+	for i, asset := range fields.ForeignAssets {
+		foreignAssets[i] = uint64(asset)
+	}
+
+	return future.MakeApplicationCallTx(appID, fields.ApplicationArgs, accounts, foreignApps, foreignAssets,
+		fields.OnCompletion, nil, nil, types.StateSchema{}, types.StateSchema{},
+		sp, sender, nil, types.Digest{}, [32]byte{}, types.Address{})
+}
+
+// Call is a decoded method call
+type Call struct {
+	Method Method
+
+	// Args holds the value of every argument, with the Go type of its ArgType.
+	// References are resolved to the address, application or asset they point to.
+	Args []interface{}
+}
+
+// DecodeCall finds the method called by an application call transaction and
+// decodes its arguments
+func (iface Interface) DecodeCall(txn types.Transaction) (Call, error) {
+	if txn.Type != types.ApplicationCallTx {
+		return Call{}, fmt.Errorf("transaction is not an application call")
+	}
+	if len(txn.ApplicationArgs) == 0 {
+		return Call{}, fmt.Errorf("application call has no arguments")
+	}
+	m, err := iface.MethodBySelector(txn.ApplicationArgs[0])
+	if err != nil {
+		return Call{}, err
+	}
+	encoded := txn.ApplicationArgs[1:]
+	if len(encoded) != len(m.Args) {
+		return Call{}, fmt.Errorf("method %s takes %d arguments, got %d", m.Name, len(m.Args), len(encoded))
+	}
+
+	call := Call{Method: m, Args: make([]interface{}, len(m.Args))}
+	for i, arg := range m.Args {
+		value, err := decodeArg(txn, arg.Type, encoded[i])
+		if err != nil {
+			return Call{}, fmt.Errorf("method %s: argument %s: %v", m.Name, arg.Name, err)
+		}
+		call.Args[i] = value
+	}
+	return call, nil
+}
+
+func decodeArg(txn types.Transaction, argType ArgType, encoded []byte) (interface{}, error) {
+	switch argType {
+	case Uint64, Bool:
+		if len(encoded) != 8 {
+			return nil, fmt.Errorf("expected 8 bytes, got %d", len(encoded))
+		}
+		v := binary.BigEndian.Uint64(encoded)
+		if argType == Uint64 {
+			return v, nil
+		}
+		if v > 1 {
+			return nil, fmt.Errorf("invalid bool %d", v)
+		}
+		return v == 1, nil
+	case Bytes:
+		return encoded, nil
+	case String:
+		if !utf8.Valid(encoded) {
+			return nil, fmt.Errorf("invalid UTF-8 string")
+		}
+		return string(encoded), nil
+	case Address:
+		var addr types.Address
+		if len(encoded) != len(addr) {
+			return nil, fmt.Errorf("expected %d bytes, got %d", len(addr), len(encoded))
+		}
+		copy(addr[:], encoded)
+		return addr, nil
+	}
+
+	if len(encoded) != 1 {
+		return nil, fmt.Errorf("expected a 1 byte reference, got %d bytes", len(encoded))
+	}
+	index := int(encoded[0])
+	switch argType {
+	case Account:
+		if index == 0 {
+			return txn.Sender, nil
+		}
+		if index > len(txn.Accounts) {
+			return nil, fmt.Errorf("account reference %d out of range", index)
+		}
+		return txn.Accounts[index-1], nil
+	case Application:
+		if index == 0 {
+			return uint64(txn.ApplicationID), nil
+		}
+		if index > len(txn.ForeignApps) {
+			return nil, fmt.Errorf("application reference %d out of range", index)
+		}
+		return uint64(txn.ForeignApps[index-1]), nil
+	case Asset:
+		if index >= len(txn.ForeignAssets) {
+			return nil, fmt.Errorf("asset reference %d out of range", index)
+		}
+		return uint64(txn.ForeignAssets[index]), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", argType)
+}
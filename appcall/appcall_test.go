@@ -0,0 +1,174 @@
+package appcall
+
+import (
+	"encoding/binary"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+const tokenInterface = `{
+	"name": "token",
+	"desc": "A fungible token held in local state",
+	"methods": [
+		{"name": "opt_in", "on-completion": "optin"},
+		{
+			"name": "transfer",
+			"desc": "Moves tokens to another holder",
+			"args": [
+				{"name": "to", "type": "account"},
+				{"name": "amount", "type": "uint64"},
+				{"name": "memo", "type": "string"}
+			]
+		},
+		{
+			"name": "swap",
+			"args": [
+				{"name": "pool", "type": "application"},
+				{"name": "asset", "type": "asset"},
+				{"name": "other", "type": "asset"},
+				{"name": "owner", "type": "address"},
+				{"name": "exact", "type": "bool"},
+				{"name": "type", "type": "bytes"}
+			]
+		}
+	]
+}`
+
+var (
+	alice = types.Address{1}
+	bob   = types.Address{2}
+)
+
+var testParams = types.SuggestedParams{
+	Fee:             10,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     make([]byte, 32),
+	FirstRoundValid: 1000,
+	LastRoundValid:  2000,
+}
+
+func TestParseInterface(t *testing.T) {
+	iface, err := ParseInterface([]byte(tokenInterface))
+	require.NoError(t, err)
+	require.Len(t, iface.Methods, 3)
+
+	transfer, err := iface.Method("transfer")
+	require.NoError(t, err)
+	require.Equal(t, "transfer(account,uint64,string)", transfer.Signature())
+
+	selector := transfer.Selector()
+	found, err := iface.MethodBySelector(selector[:])
+	require.NoError(t, err)
+	require.Equal(t, "transfer", found.Name)
+
+	_, err = iface.Method("burn")
+	require.Error(t, err)
+
+	invalid := []string{
+		`{"name": "t", "methods": [{"name": "a", "args": [{"name": "x", "type": "int"}]}]}`,
+		`{"name": "t", "methods": [{"name": "a"}, {"name": "a"}]}`,
+		`{"name": "t", "methods": [{"name": "a-b"}]}`,
+		`{"name": "t", "methods": [{"name": "a", "on-completion": "create"}]}`,
+		`{"name": "t", "methods": [{"name": "a", "args": [{"name": "x", "type": "uint64"}, {"name": "x", "type": "uint64"}]}]}`,
+	}
+	for _, description := range invalid {
+		_, err := ParseInterface([]byte(description))
+		require.Error(t, err, description)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	iface := MustParseInterface(tokenInterface)
+	swap, err := iface.Method("swap")
+	require.NoError(t, err)
+
+	fields, err := swap.Encode(7, uint64(7), 31566704, uint64(31566704), bob.String(), true, []byte{0xff})
+	require.NoError(t, err)
+	require.Equal(t, types.AppIndex(7), fields.ApplicationID)
+	require.Empty(t, fields.ForeignApps)
+	require.Equal(t, []types.AssetIndex{31566704}, fields.ForeignAssets)
+
+	selector := swap.Selector()
+	require.Equal(t, [][]byte{
+		selector[:],
+		{0},
+		{0},
+		{0},
+		bob[:],
+		{0, 0, 0, 0, 0, 0, 0, 1},
+		{0xff},
+	}, fields.ApplicationArgs)
+
+	txn := types.Transaction{Type: types.ApplicationCallTx, ApplicationFields: types.ApplicationFields{ApplicationCallTxnFields: fields}}
+	call, err := iface.DecodeCall(txn)
+	require.NoError(t, err)
+	require.Equal(t, "swap", call.Method.Name)
+	require.Equal(t, []interface{}{uint64(7), uint64(31566704), uint64(31566704), bob, true, []byte{0xff}}, call.Args)
+
+	_, err = swap.Encode(7, uint64(1))
+	require.Error(t, err)
+	_, err = swap.Encode(7, -1, uint64(1), uint64(1), bob, true, []byte{})
+	require.Error(t, err)
+	_, err = swap.Encode(7, uint64(1), uint64(1), uint64(1), bob, "yes", []byte{})
+	require.Error(t, err)
+}
+
+func TestMakeCallTxn(t *testing.T) {
+	iface := MustParseInterface(tokenInterface)
+	transfer, err := iface.Method("transfer")
+	require.NoError(t, err)
+
+	txn, err := transfer.MakeCallTxn(alice, 42, testParams, bob, 250, "rent")
+	require.NoError(t, err)
+	require.Equal(t, types.ApplicationCallTx, txn.Type)
+	require.Equal(t, types.NoOpOC, txn.OnCompletion)
+	require.Equal(t, []types.Address{bob}, txn.Accounts)
+	require.Equal(t, []byte{1}, txn.ApplicationArgs[1])
+	require.Equal(t, uint64(250), binary.BigEndian.Uint64(txn.ApplicationArgs[2]))
+	require.Equal(t, []byte("rent"), txn.ApplicationArgs[3])
+
+	// the sender is always reference 0
+	txn, err = transfer.MakeCallTxn(alice, 42, testParams, alice, 250, "")
+	require.NoError(t, err)
+	require.Empty(t, txn.Accounts)
+	require.Equal(t, []byte{0}, txn.ApplicationArgs[1])
+
+	call, err := iface.DecodeCall(txn)
+	require.NoError(t, err)
+	require.Equal(t, alice, call.Args[0])
+
+	optIn, err := iface.Method("opt_in")
+	require.NoError(t, err)
+	txn, err = optIn.MakeCallTxn(alice, 42, testParams)
+	require.NoError(t, err)
+	require.Equal(t, types.OptInOC, txn.OnCompletion)
+
+	txn.ApplicationArgs = [][]byte{{1, 2, 3, 4}}
+	_, err = iface.DecodeCall(txn)
+	require.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	iface := MustParseInterface(tokenInterface)
+	src, err := Generate(iface, "tokenclient")
+	require.NoError(t, err)
+
+	file, err := parser.ParseFile(token.NewFileSet(), "token.go", src, parser.ParseComments)
+	require.NoError(t, err)
+	require.Equal(t, "tokenclient", file.Name.Name)
+
+	code := string(src)
+	require.Contains(t, code, "type TokenClient struct")
+	require.Contains(t, code, "func (c TokenClient) OptIn(sp types.SuggestedParams) (types.Transaction, error)")
+	require.Contains(t, code, "func (c TokenClient) Transfer(sp types.SuggestedParams, to types.Address, amount uint64, memo string) (types.Transaction, error)")
+	require.Contains(t, code, "func (c TokenClient) Swap(sp types.SuggestedParams, pool uint64, asset uint64, other uint64, owner types.Address, exact bool, typeArg []byte) (types.Transaction, error)")
+	require.Contains(t, code, "// Transfer builds a call to the transfer method: Moves tokens to another holder")
+
+	collision := MustParseInterface(`{"name": "t", "methods": [{"name": "do_it"}, {"name": "doIt"}]}`)
+	_, err = Generate(collision, "t")
+	require.Error(t, err)
+}
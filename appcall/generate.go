@@ -0,0 +1,176 @@
+package appcall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// goTypes maps every ArgType to the Go type of its stub parameters
+var goTypes = map[ArgType]string{
+	Uint64:      "uint64",
+	Bool:        "bool",
+	Bytes:       "[]byte",
+	String:      "string",
+	Address:     "types.Address",
+	Account:     "types.Address",
+	Application: "uint64",
+	Asset:       "uint64",
+}
+
+var stubTemplate = template.Must(template.New("stub").Parse(`// Code generated by appcall from the {{.Name}} interface. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"github.com/jffp113/go-algorand-sdk/appcall"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var {{.Var}} = appcall.MustParseInterface({{.JSON}})
+
+// {{.Client}} builds calls to an application implementing the {{.Name}} interface
+{{- if .Desc}}: {{.Desc}}{{end}}
+type {{.Client}} struct {
+	AppID  uint64
+	Sender types.Address
+}
+{{range .Methods}}
+// {{.Func}} builds a call to the {{.Name}} method
+{{- if .Desc}}: {{.Desc}}{{end}}
+func (c {{$.Client}}) {{.Func}}(sp types.SuggestedParams{{range .Params}}, {{.Name}} {{.Type}}{{end}}) (types.Transaction, error) {
+	return {{$.Var}}.Methods[{{.Index}}].MakeCallTxn(c.Sender, c.AppID, sp{{range .Params}}, {{.Name}}{{end}})
+}
+{{end}}`))
+
+type stubParam struct {
+	Name string
+	Type string
+}
+
+type stubMethod struct {
+	Name   string
+	Desc   string
+	Func   string
+	Index  int
+	Params []stubParam
+}
+
+type stubFile struct {
+	Package string
+	Name    string
+	Desc    string
+	Var     string
+	Client  string
+	JSON    string
+	Methods []stubMethod
+}
+
+// Generate returns the source of a Go file in package pkg holding client stubs
+// for the interface. The file declares a <Name>Client type, with the id of the
+// application and the sender of the calls, and one method per interface
+// method taking the suggested params and the typed arguments, and returning
+// the call transaction.
+func Generate(iface Interface, pkg string) ([]byte, error) {
+	if err := iface.Validate(); err != nil {
+		return nil, err
+	}
+	description, err := json.Marshal(iface)
+	if err != nil {
+		return nil, err
+	}
+
+	file := stubFile{
+		Package: pkg,
+		Name:    iface.Name,
+		Desc:    oneLine(iface.Desc),
+		Var:     unexported(iface.Name) + "Interface",
+		Client:  exported(iface.Name) + "Client",
+		JSON:    strconv.Quote(string(description)),
+	}
+	funcs := make(map[string]string)
+	for i, m := range iface.Methods {
+		method := stubMethod{Name: m.Name, Desc: oneLine(m.Desc), Func: exported(m.Name), Index: i}
+		if other, ok := funcs[method.Func]; ok {
+			return nil, fmt.Errorf("methods %s and %s would both generate %s", other, m.Name, method.Func)
+		}
+		funcs[method.Func] = m.Name
+
+		params := make(map[string]string)
+		for _, arg := range m.Args {
+			param := stubParam{Name: paramName(arg.Name), Type: goTypes[arg.Type]}
+			if other, ok := params[param.Name]; ok {
+				return nil, fmt.Errorf("method %s: arguments %s and %s would both generate %s", m.Name, other, arg.Name, param.Name)
+			}
+			params[param.Name] = arg.Name
+			method.Params = append(method.Params, param)
+		}
+		file.Methods = append(file.Methods, method)
+	}
+
+	var buf bytes.Buffer
+	if err := stubTemplate.Execute(&buf, file); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// words splits snake_case and camelCase names
+func words(name string) []string {
+	var result []string
+	var current []rune
+	for i, r := range name {
+		if r == '_' {
+			if len(current) > 0 {
+				result = append(result, string(current))
+			}
+			current = nil
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && len(current) > 0 && !unicode.IsUpper(current[len(current)-1]) {
+			result = append(result, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		result = append(result, string(current))
+	}
+	return result
+}
+
+func exported(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if b.Len() == 0 || !unicode.IsLetter(rune(b.String()[0])) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+func unexported(name string) string {
+	s := exported(name)
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// paramName returns a parameter name that neither is a keyword nor collides
+// with the receiver and the suggested params of the stubs
+func paramName(name string) string {
+	s := unexported(name)
+	if token.Lookup(s).IsKeyword() || s == "c" || s == "sp" || s == "appcall" || s == "types" {
+		return s + "Arg"
+	}
+	return s
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
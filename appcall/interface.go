@@ -0,0 +1,180 @@
+package appcall
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// SelectorLength is the length of a method selector, the first argument of
+// every method call
+const SelectorLength = 4
+
+// Arg describes an argument of a method
+type Arg struct {
+	Name string  `json:"name"`
+	Type ArgType `json:"type"`
+	Desc string  `json:"desc,omitempty"`
+}
+
+// Method describes a method of an application
+type Method struct {
+	Name string `json:"name"`
+	Desc string `json:"desc,omitempty"`
+	Args []Arg  `json:"args,omitempty"`
+
+	// OnCompletion is the on-completion action the method is called with:
+	// "noop", which is the default, "optin", "closeout", "update" or "delete"
+	OnCompletion string `json:"on-completion,omitempty"`
+}
+
+// Signature returns the signature the selector of the method is derived from,
+// its name followed by the types of its arguments, e.g. "transfer(account,uint64)"
+func (m Method) Signature() string {
+	argTypes := make([]string, len(m.Args))
+	for i, arg := range m.Args {
+		argTypes[i] = string(arg.Type)
+	}
+	return fmt.Sprintf("%s(%s)", m.Name, strings.Join(argTypes, ","))
+}
+
+// Selector returns the first bytes of the SHA512/256 hash of the signature of
+// the method. Applications dispatch on it, passed as their first argument.
+func (m Method) Selector() [SelectorLength]byte {
+	var selector [SelectorLength]byte
+	hash := sha512.Sum512_256([]byte(m.Signature()))
+	copy(selector[:], hash[:])
+	return selector
+}
+
+// onCompletion returns the on-completion action of the method
+func (m Method) onCompletion() (types.OnCompletion, error) {
+	switch m.OnCompletion {
+	case "", "noop":
+		return types.NoOpOC, nil
+	case "optin":
+		return types.OptInOC, nil
+	case "closeout":
+		return types.CloseOutOC, nil
+	case "update":
+		return types.UpdateApplicationOC, nil
+	case "delete":
+		return types.DeleteApplicationOC, nil
+	}
+	return 0, fmt.Errorf("method %s: unknown on-completion %q", m.Name, m.OnCompletion)
+}
+
+func (m Method) validate() error {
+	if !isIdentifier(m.Name) {
+		return fmt.Errorf("invalid method name %q", m.Name)
+	}
+	if _, err := m.onCompletion(); err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for _, arg := range m.Args {
+		if !isIdentifier(arg.Name) {
+			return fmt.Errorf("method %s: invalid argument name %q", m.Name, arg.Name)
+		}
+		if names[arg.Name] {
+			return fmt.Errorf("method %s: duplicate argument %s", m.Name, arg.Name)
+		}
+		names[arg.Name] = true
+		if !arg.Type.valid() {
+			return fmt.Errorf("method %s: argument %s has unknown type %q", m.Name, arg.Name, arg.Type)
+		}
+	}
+	return nil
+}
+
+// Interface describes the methods of an application
+type Interface struct {
+	Name    string   `json:"name"`
+	Desc    string   `json:"desc,omitempty"`
+	Methods []Method `json:"methods"`
+}
+
+// ParseInterface parses and validates the JSON description of an interface
+func ParseInterface(data []byte) (Interface, error) {
+	var iface Interface
+	if err := json.Unmarshal(data, &iface); err != nil {
+		return Interface{}, err
+	}
+	if err := iface.Validate(); err != nil {
+		return Interface{}, err
+	}
+	return iface, nil
+}
+
+// MustParseInterface is like ParseInterface but panics on error. It is meant
+// for descriptions embedded in generated code.
+func MustParseInterface(data string) Interface {
+	iface, err := ParseInterface([]byte(data))
+	if err != nil {
+		panic(err)
+	}
+	return iface
+}
+
+// Validate checks names and types are valid, and that no two methods share a
+// name or a selector
+func (iface Interface) Validate() error {
+	if !isIdentifier(iface.Name) {
+		return fmt.Errorf("invalid interface name %q", iface.Name)
+	}
+	names := make(map[string]bool)
+	selectors := make(map[[SelectorLength]byte]string)
+	for _, m := range iface.Methods {
+		if err := m.validate(); err != nil {
+			return err
+		}
+		if names[m.Name] {
+			return fmt.Errorf("duplicate method %s", m.Name)
+		}
+		names[m.Name] = true
+		selector := m.Selector()
+		if other, ok := selectors[selector]; ok {
+			return fmt.Errorf("methods %s and %s have the same selector", other, m.Name)
+		}
+		selectors[selector] = m.Name
+	}
+	return nil
+}
+
+// Method returns the method named name
+func (iface Interface) Method(name string) (Method, error) {
+	for _, m := range iface.Methods {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return Method{}, fmt.Errorf("interface %s has no method %s", iface.Name, name)
+}
+
+// MethodBySelector returns the method whose selector is selector
+func (iface Interface) MethodBySelector(selector []byte) (Method, error) {
+	for _, m := range iface.Methods {
+		s := m.Selector()
+		if string(s[:]) == string(selector) {
+			return m, nil
+		}
+	}
+	return Method{}, fmt.Errorf("interface %s has no method with selector %x", iface.Name, selector)
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		digit := r >= '0' && r <= '9'
+		if !letter && !(digit && i > 0) {
+			return false
+		}
+	}
+	return true
+}
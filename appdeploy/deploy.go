@@ -0,0 +1,311 @@
+// Package appdeploy deploys stateful applications idempotently.
+//
+// A Deployer compiles the TEAL sources of an application and compares them
+// with the programs deployed on the network. It creates the application the
+// first time, updates it when its programs change and does nothing
+// otherwise. Deployments are recorded by name in a Manifest, per network
+// genesis hash, so the same sources can be deployed to several networks.
+//
+// The state schemas of an application cannot change once it is created, so a
+// deployment changing them fails with a *SchemaChangeError instead of
+// submitting an update the network would reject.
+package appdeploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/jffp113/go-algorand-sdk/validate"
+)
+
+// defaultWaitRounds is the number of rounds a deployment waits for its
+// transaction to be confirmed
+const defaultWaitRounds = 10
+
+// Spec describes an application to deploy
+type Spec struct {
+	// Name identifies the application in the manifest
+	Name string
+
+	ApprovalSource string
+	ClearSource    string
+
+	GlobalSchema types.StateSchema
+	LocalSchema  types.StateSchema
+
+	// CreateArgs are the application arguments of the creation call
+	CreateArgs [][]byte
+
+	// UpdateArgs are the application arguments of update calls, e.g. to let
+	// the new approval program migrate the state
+	UpdateArgs [][]byte
+
+	// OptIn opts the creator in the application when creating it
+	OptIn bool
+}
+
+// Action is what a deployment did
+type Action int
+
+const (
+	// Unchanged means the deployed programs already were the ones of the spec
+	Unchanged Action = iota
+
+	// Created means the application was created
+	Created
+
+	// Updated means the programs of the application were replaced
+	Updated
+)
+
+func (a Action) String() string {
+	switch a {
+	case Unchanged:
+		return "unchanged"
+	case Created:
+		return "created"
+	case Updated:
+		return "updated"
+	}
+	return fmt.Sprintf("unknown(%d)", int(a))
+}
+
+// Result is the outcome of a deployment
+type Result struct {
+	Action Action
+	AppID  uint64
+
+	// TxID and ConfirmedRound are set when a transaction was submitted
+	TxID           string
+	ConfirmedRound uint64
+}
+
+// SchemaChangeError is returned when a spec changes the state schemas of an
+// application that is already deployed
+type SchemaChangeError struct {
+	AppID        uint64
+	GlobalSchema types.StateSchema
+	LocalSchema  types.StateSchema
+}
+
+func (e *SchemaChangeError) Error() string {
+	return fmt.Sprintf("application %d has global schema %d uints %d byte slices and local schema %d uints %d byte slices, which cannot change",
+		e.AppID, e.GlobalSchema.NumUint, e.GlobalSchema.NumByteSlice, e.LocalSchema.NumUint, e.LocalSchema.NumByteSlice)
+}
+
+// Deployer deploys the applications of a creator and records them in a manifest
+type Deployer struct {
+	client   *algod.Client
+	creator  types.Address
+	signer   future.TransactionSigner
+	manifest *Manifest
+
+	// WaitRounds is the number of rounds to wait for deployment transactions
+	// to be confirmed
+	WaitRounds uint64
+}
+
+// NewDeployer returns a Deployer of applications created by creator, whose
+// transactions are signed by signer
+func NewDeployer(client *algod.Client, creator types.Address, signer future.TransactionSigner, manifest *Manifest) *Deployer {
+	return &Deployer{
+		client:     client,
+		creator:    creator,
+		signer:     signer,
+		manifest:   manifest,
+		WaitRounds: defaultWaitRounds,
+	}
+}
+
+// program is a compiled program
+type program struct {
+	bytes []byte
+	hash  string
+}
+
+func (d *Deployer) compile(ctx context.Context, source string) (program, error) {
+	response, err := d.client.TealCompile([]byte(source)).Do(ctx)
+	if err != nil {
+		return program{}, err
+	}
+	b, err := base64.StdEncoding.DecodeString(response.Result)
+	if err != nil {
+		return program{}, err
+	}
+	return program{bytes: b, hash: response.Hash}, nil
+}
+
+// Deploy makes the application described by spec deployed on the network
+// algod is connected to, creating or updating it as needed, and records it in
+// the manifest.
+//
+// The application is looked up in the manifest first. When the manifest has
+// no record of it, for instance because it was lost, an application of the
+// creator with the same programs and schemas is adopted instead of creating
+// another one.
+func (d *Deployer) Deploy(ctx context.Context, spec Spec) (Result, error) {
+	if spec.Name == "" {
+		return Result{}, fmt.Errorf("application spec has no name")
+	}
+	approval, err := d.compile(ctx, spec.ApprovalSource)
+	if err != nil {
+		return Result{}, fmt.Errorf("compiling approval program of %s: %v", spec.Name, err)
+	}
+	clear, err := d.compile(ctx, spec.ClearSource)
+	if err != nil {
+		return Result{}, fmt.Errorf("compiling clear program of %s: %v", spec.Name, err)
+	}
+	sp, err := d.client.SuggestedParams().Do(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	if deployment, ok := d.manifest.Lookup(sp.GenesisHash, spec.Name); ok {
+		if deployment.Creator != d.creator.String() {
+			return Result{}, fmt.Errorf("%s was deployed by %s, not %s", spec.Name, deployment.Creator, d.creator)
+		}
+		app, err := d.client.GetApplicationByID(deployment.AppID).Do(ctx)
+		if err != nil {
+			return Result{}, fmt.Errorf("fetching application %d of %s: %v", deployment.AppID, spec.Name, err)
+		}
+		result, err = d.update(ctx, spec, app, approval, clear, sp)
+		if err != nil {
+			return Result{}, err
+		}
+	} else {
+		appID, found, err := d.findDeployed(ctx, spec, approval, clear)
+		if err != nil {
+			return Result{}, err
+		}
+		if found {
+			result = Result{Action: Unchanged, AppID: appID}
+		} else if result, err = d.create(ctx, spec, approval, clear, sp); err != nil {
+			return Result{}, err
+		}
+	}
+
+	deployment, _ := d.manifest.Lookup(sp.GenesisHash, spec.Name)
+	deployment.AppID = result.AppID
+	deployment.Creator = d.creator.String()
+	deployment.ApprovalHash = approval.hash
+	deployment.ClearHash = clear.hash
+	if result.TxID != "" {
+		deployment.TxID = result.TxID
+		deployment.Round = result.ConfirmedRound
+	}
+	d.manifest.Record(sp.GenesisHash, spec.Name, deployment)
+	if err := d.manifest.Save(); err != nil {
+		return result, fmt.Errorf("%s %s but the manifest could not be saved: %v", spec.Name, result.Action, err)
+	}
+	return result, nil
+}
+
+func schemaOf(schema models.ApplicationStateSchema) types.StateSchema {
+	return types.StateSchema{NumUint: schema.NumUint, NumByteSlice: schema.NumByteSlice}
+}
+
+// findDeployed looks for an application of the creator with the programs and
+// schemas of the spec
+func (d *Deployer) findDeployed(ctx context.Context, spec Spec, approval, clear program) (uint64, bool, error) {
+	account, err := d.client.AccountInformation(d.creator.String()).Do(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, app := range account.CreatedApps {
+		if bytes.Equal(app.Params.ApprovalProgram, approval.bytes) &&
+			bytes.Equal(app.Params.ClearStateProgram, clear.bytes) &&
+			schemaOf(app.Params.GlobalStateSchema) == spec.GlobalSchema &&
+			schemaOf(app.Params.LocalStateSchema) == spec.LocalSchema {
+			return app.Id, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func (d *Deployer) create(ctx context.Context, spec Spec, approval, clear program, sp types.SuggestedParams) (Result, error) {
+	txn, err := future.MakeApplicationCreateTx(spec.OptIn, approval.bytes, clear.bytes, spec.GlobalSchema, spec.LocalSchema,
+		spec.CreateArgs, nil, nil, nil, sp, d.creator, nil, types.Digest{}, [32]byte{}, types.Address{})
+	if err != nil {
+		return Result{}, err
+	}
+	confirmed, err := d.execute(ctx, txn, sp, d.signer)
+	if err != nil {
+		return Result{}, fmt.Errorf("creating %s: %v", spec.Name, err)
+	}
+	if confirmed.ApplicationIndex == 0 {
+		return Result{}, fmt.Errorf("creating %s: confirmation has no application index", spec.Name)
+	}
+	return Result{Action: Created, AppID: confirmed.ApplicationIndex, TxID: confirmed.TxID, ConfirmedRound: confirmed.ConfirmedRound}, nil
+}
+
+func (d *Deployer) update(ctx context.Context, spec Spec, app models.Application, approval, clear program, sp types.SuggestedParams) (Result, error) {
+	if app.Params.Creator != d.creator.String() {
+		return Result{}, fmt.Errorf("application %d was created by %s, not %s", app.Id, app.Params.Creator, d.creator)
+	}
+	global := schemaOf(app.Params.GlobalStateSchema)
+	local := schemaOf(app.Params.LocalStateSchema)
+	if global != spec.GlobalSchema || local != spec.LocalSchema {
+		return Result{}, &SchemaChangeError{AppID: app.Id, GlobalSchema: global, LocalSchema: local}
+	}
+	if bytes.Equal(app.Params.ApprovalProgram, approval.bytes) && bytes.Equal(app.Params.ClearStateProgram, clear.bytes) {
+		return Result{Action: Unchanged, AppID: app.Id}, nil
+	}
+
+	txn, err := future.MakeApplicationUpdateTx(app.Id, spec.UpdateArgs, nil, nil, nil, approval.bytes, clear.bytes,
+		sp, d.creator, nil, types.Digest{}, [32]byte{}, types.Address{})
+	if err != nil {
+		return Result{}, err
+	}
+	confirmed, err := d.execute(ctx, txn, sp, d.signer)
+	if err != nil {
+		return Result{}, fmt.Errorf("updating %s: %v", spec.Name, err)
+	}
+	return Result{Action: Updated, AppID: app.Id, TxID: confirmed.TxID, ConfirmedRound: confirmed.ConfirmedRound}, nil
+}
+
+// execute validates, signs and submits txn, then waits for its confirmation
+func (d *Deployer) execute(ctx context.Context, txn types.Transaction, sp types.SuggestedParams, signer future.TransactionSigner) (future.TransactionResult, error) {
+	params, err := consensus.FromSuggestedParams(sp)
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	if err := validate.Transaction(txn, params); err != nil {
+		return future.TransactionResult{}, err
+	}
+
+	var atc future.AtomicTransactionComposer
+	if err := atc.AddTransaction(future.TransactionWithSigner{Txn: txn, Signer: signer}); err != nil {
+		return future.TransactionResult{}, err
+	}
+	executed, err := atc.Execute(ctx, d.client, d.WaitRounds)
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	return executed.Results[0], nil
+}
+
+// OptIn opts account in the application, signing with signer, and waits for
+// the transaction to be confirmed
+func (d *Deployer) OptIn(ctx context.Context, appID uint64, account types.Address, signer future.TransactionSigner) (future.TransactionResult, error) {
+	sp, err := d.client.SuggestedParams().Do(ctx)
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	txn, err := future.MakeApplicationOptInTx(appID, nil, nil, nil, nil, sp, account, nil, types.Digest{}, [32]byte{}, types.Address{})
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	confirmed, err := d.execute(ctx, txn, sp, signer)
+	if err != nil {
+		return future.TransactionResult{}, fmt.Errorf("opting %s in application %d: %v", account, appID, err)
+	}
+	return confirmed, nil
+}
@@ -0,0 +1,178 @@
+package appdeploy
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+var genesisHash = []byte("01234567890123456789012345678901")
+
+// network is a fake algod holding the application deployed through it,
+// which is given the index 100
+type network struct {
+	app       models.Application
+	submitted int
+}
+
+func (n *network) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/transactions/params":
+			json.NewEncoder(w).Encode(models.TransactionParams{GenesisID: "test", Genesishash: genesisHash, LastRound: 1000})
+		case r.URL.Path == "/v2/teal/compile":
+			source, _ := ioutil.ReadAll(r.Body)
+			program := append([]byte("compiled "), source...)
+			json.NewEncoder(w).Encode(models.CompileResponse{Result: base64.StdEncoding.EncodeToString(program)})
+		case r.URL.Path == "/v2/status":
+			json.NewEncoder(w).Encode(models.NodeStatus{LastRound: 1000})
+		case r.URL.Path == "/v2/transactions":
+			body, _ := ioutil.ReadAll(r.Body)
+			var stx types.SignedTxn
+			require.NoError(t, msgpack.Decode(body, &stx))
+			n.submitted++
+			if stx.Txn.ApplicationID == 0 {
+				n.app.Id = 100
+				n.app.Params.Creator = stx.Txn.Sender.String()
+				n.app.Params.GlobalStateSchema.NumUint = stx.Txn.GlobalStateSchema.NumUint
+			}
+			n.app.Params.ApprovalProgram = stx.Txn.ApprovalProgram
+			n.app.Params.ClearStateProgram = stx.Txn.ClearStateProgram
+			json.NewEncoder(w).Encode(map[string]string{"txId": "unused"})
+		case strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/"):
+			w.Write(msgpack.Encode(models.PendingTransactionInfoResponse{ConfirmedRound: 1001, ApplicationIndex: n.app.Id}))
+		case r.URL.Path == "/v2/applications/100":
+			json.NewEncoder(w).Encode(n.app)
+		case strings.HasPrefix(r.URL.Path, "/v2/accounts/"):
+			json.NewEncoder(w).Encode(models.Account{CreatedApps: []models.Application{n.app}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func testSpec() Spec {
+	return Spec{
+		Name:           "counter",
+		ApprovalSource: "#pragma version 2\nint 1",
+		ClearSource:    "#pragma version 2\nint 1",
+		GlobalSchema:   types.StateSchema{NumUint: 1},
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	creator := crypto.GenerateAccount()
+	net := &network{}
+	server := httptest.NewServer(net.handler(t))
+	defer server.Close()
+	client, err := algod.MakeClient(server.URL, "")
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "appdeploy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "deployments.json")
+
+	manifest, err := LoadManifest(path)
+	require.NoError(t, err)
+	signer := future.BasicAccountTransactionSigner{Account: creator}
+	deployer := NewDeployer(client, creator.Address, signer, manifest)
+	ctx := context.Background()
+
+	// the first deployment creates the application
+	result, err := deployer.Deploy(ctx, testSpec())
+	require.NoError(t, err)
+	require.Equal(t, Created, result.Action)
+	require.Equal(t, uint64(100), result.AppID)
+	require.Equal(t, uint64(1001), result.ConfirmedRound)
+	require.Equal(t, 1, net.submitted)
+
+	reloaded, err := LoadManifest(path)
+	require.NoError(t, err)
+	deployment, ok := reloaded.Lookup(genesisHash, "counter")
+	require.True(t, ok)
+	require.Equal(t, uint64(100), deployment.AppID)
+	require.Equal(t, creator.Address.String(), deployment.Creator)
+	require.Equal(t, result.TxID, deployment.TxID)
+
+	// deploying the same sources again does nothing
+	result, err = deployer.Deploy(ctx, testSpec())
+	require.NoError(t, err)
+	require.Equal(t, Unchanged, result.Action)
+	require.Equal(t, 1, net.submitted)
+
+	// new sources update the application
+	spec := testSpec()
+	spec.ApprovalSource = "#pragma version 2\nint 2"
+	result, err = deployer.Deploy(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, Updated, result.Action)
+	require.Equal(t, uint64(100), result.AppID)
+	require.Equal(t, 2, net.submitted)
+	require.Equal(t, []byte("compiled #pragma version 2\nint 2"), net.app.Params.ApprovalProgram)
+
+	// schemas cannot change
+	spec.GlobalSchema.NumByteSlice = 1
+	_, err = deployer.Deploy(ctx, spec)
+	require.IsType(t, &SchemaChangeError{}, err)
+	require.Equal(t, 2, net.submitted)
+
+	// without the manifest, the deployed application is adopted
+	lost, err := LoadManifest(filepath.Join(dir, "lost.json"))
+	require.NoError(t, err)
+	spec.GlobalSchema.NumByteSlice = 0
+	result, err = NewDeployer(client, creator.Address, signer, lost).Deploy(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, Unchanged, result.Action)
+	require.Equal(t, uint64(100), result.AppID)
+	require.Equal(t, 2, net.submitted)
+
+	// another creator cannot take over the recorded application
+	other := NewDeployer(client, crypto.GenerateAccount().Address, signer, manifest)
+	_, err = other.Deploy(ctx, spec)
+	require.Error(t, err)
+}
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appdeploy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "manifest.json")
+
+	manifest, err := LoadManifest(path)
+	require.NoError(t, err)
+	_, ok := manifest.Lookup(genesisHash, "counter")
+	require.False(t, ok)
+
+	manifest.Record(genesisHash, "counter", Deployment{AppID: 1})
+	manifest.Record([]byte("other"), "counter", Deployment{AppID: 2})
+	require.NoError(t, manifest.Save())
+
+	reloaded, err := LoadManifest(path)
+	require.NoError(t, err)
+	d, ok := reloaded.Lookup(genesisHash, "counter")
+	require.True(t, ok)
+	require.Equal(t, uint64(1), d.AppID)
+	d, ok = reloaded.Lookup([]byte("other"), "counter")
+	require.True(t, ok)
+	require.Equal(t, uint64(2), d.AppID)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
@@ -0,0 +1,98 @@
+package appdeploy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Deployment records an application deployed on a network
+type Deployment struct {
+	AppID   uint64 `json:"app-id"`
+	Creator string `json:"creator"`
+
+	// ApprovalHash and ClearHash are the hashes of the deployed programs, in
+	// the address form returned by TealCompile
+	ApprovalHash string `json:"approval-hash"`
+	ClearHash    string `json:"clear-hash"`
+
+	// TxID and Round identify the transaction that created or last updated
+	// the application
+	TxID  string `json:"txid,omitempty"`
+	Round uint64 `json:"round,omitempty"`
+}
+
+// Manifest records the applications deployed by name, per network. Networks
+// are identified by their genesis hash, so that a manifest can be shared by
+// deployments on several networks.
+type Manifest struct {
+	path string
+
+	// Networks maps base64 genesis hashes to the deployments on that network
+	Networks map[string]map[string]Deployment `json:"networks"`
+}
+
+// LoadManifest reads the manifest stored at path. A missing file is an empty
+// manifest, created when it is first saved.
+func LoadManifest(path string) (*Manifest, error) {
+	m := &Manifest{path: path, Networks: make(map[string]map[string]Deployment)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Networks == nil {
+		m.Networks = make(map[string]map[string]Deployment)
+	}
+	return m, nil
+}
+
+// Lookup returns the deployment of the application name on the network with
+// genesis hash genesisHash
+func (m *Manifest) Lookup(genesisHash []byte, name string) (Deployment, bool) {
+	d, ok := m.Networks[base64.StdEncoding.EncodeToString(genesisHash)][name]
+	return d, ok
+}
+
+// Record sets the deployment of the application name on the network with
+// genesis hash genesisHash. It is only persisted by Save.
+func (m *Manifest) Record(genesisHash []byte, name string, d Deployment) {
+	network := base64.StdEncoding.EncodeToString(genesisHash)
+	if m.Networks[network] == nil {
+		m.Networks[network] = make(map[string]Deployment)
+	}
+	m.Networks[network][name] = d
+}
+
+// Save writes the manifest back to its file. The file is replaced atomically,
+// so that an interrupted save never loses the deployments already recorded.
+func (m *Manifest) Save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(m.path), filepath.Base(m.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), m.path)
+}
@@ -0,0 +1,365 @@
+// Package appstate decodes the global and local state of applications, as
+// returned by algod and the indexer, into typed values.
+//
+// The REST API returns state as lists of key-value pairs with base64 keys and
+// a numeric type code, and the effects of a transaction as lists of deltas
+// with an action code. State maps keys, as raw strings, to values holding
+// either a uint64 or a byte slice. Deltas can be applied to a State to follow
+// an application without fetching its whole state again, and two States can
+// be compared with Diff.
+package appstate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+)
+
+// ValueType is the type of a TEAL value, as encoded in TealValue.Type
+type ValueType uint64
+
+const (
+	// TypeBytes is the type of byte slice values
+	TypeBytes ValueType = 1
+
+	// TypeUint is the type of uint64 values
+	TypeUint ValueType = 2
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case TypeBytes:
+		return "bytes"
+	case TypeUint:
+		return "uint"
+	}
+	return fmt.Sprintf("unknown(%d)", uint64(t))
+}
+
+// Delta actions, as encoded in EvalDelta.Action
+const (
+	actionSetBytes uint64 = 1
+	actionSetUint  uint64 = 2
+	actionDelete   uint64 = 3
+)
+
+// Value is a TEAL value held in application state
+type Value struct {
+	Type  ValueType
+	Uint  uint64
+	Bytes []byte
+}
+
+// UintValue returns a Value holding v
+func UintValue(v uint64) Value {
+	return Value{Type: TypeUint, Uint: v}
+}
+
+// BytesValue returns a Value holding v
+func BytesValue(v []byte) Value {
+	return Value{Type: TypeBytes, Bytes: v}
+}
+
+// Equal reports whether v and other hold the same typed value
+func (v Value) Equal(other Value) bool {
+	if v.Type != other.Type {
+		return false
+	}
+	if v.Type == TypeUint {
+		return v.Uint == other.Uint
+	}
+	return string(v.Bytes) == string(other.Bytes)
+}
+
+// String renders uints in decimal and byte slices as a quoted string when they
+// are printable, or as base64 otherwise
+func (v Value) String() string {
+	switch v.Type {
+	case TypeUint:
+		return strconv.FormatUint(v.Uint, 10)
+	case TypeBytes:
+		return formatBytes(v.Bytes)
+	}
+	return "<none>"
+}
+
+func formatBytes(b []byte) string {
+	if utf8.Valid(b) {
+		printable := true
+		for _, r := range string(b) {
+			if !strconv.IsPrint(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return strconv.Quote(string(b))
+		}
+	}
+	return "base64:" + base64.StdEncoding.EncodeToString(b)
+}
+
+// State is the global state of an application, or the local state of an
+// account in an application, indexed by raw key
+type State map[string]Value
+
+// Decode decodes the key-value pairs returned by the REST API
+func Decode(kvs []models.TealKeyValue) (State, error) {
+	state := make(State, len(kvs))
+	for _, kv := range kvs {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", kv.Key, err)
+		}
+		value, err := DecodeValue(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", formatBytes(key), err)
+		}
+		state[string(key)] = value
+	}
+	return state, nil
+}
+
+// DecodeValue decodes a TEAL value returned by the REST API, such as the
+// values of the stack and scratch space in dryrun traces
+func DecodeValue(tv models.TealValue) (Value, error) {
+	switch ValueType(tv.Type) {
+	case TypeUint:
+		return UintValue(tv.Uint), nil
+	case TypeBytes:
+		b, err := base64.StdEncoding.DecodeString(tv.Bytes)
+		if err != nil {
+			return Value{}, err
+		}
+		return BytesValue(b), nil
+	}
+	return Value{}, fmt.Errorf("unknown value type %d", tv.Type)
+}
+
+// Encode encodes the state as the key-value pairs of the REST API, sorted by key
+func (s State) Encode() []models.TealKeyValue {
+	kvs := make([]models.TealKeyValue, 0, len(s))
+	for _, key := range s.Keys() {
+		kvs = append(kvs, models.TealKeyValue{
+			Key:   base64.StdEncoding.EncodeToString([]byte(key)),
+			Value: EncodeValue(s[key]),
+		})
+	}
+	return kvs
+}
+
+// EncodeValue encodes a value as a TEAL value of the REST API
+func EncodeValue(v Value) models.TealValue {
+	tv := models.TealValue{Type: uint64(v.Type)}
+	if v.Type == TypeUint {
+		tv.Uint = v.Uint
+	} else {
+		tv.Bytes = base64.StdEncoding.EncodeToString(v.Bytes)
+	}
+	return tv
+}
+
+// GlobalState decodes the global state of an application
+func GlobalState(app models.Application) (State, error) {
+	return Decode(app.Params.GlobalState)
+}
+
+// LocalState decodes the local state of account in application appID. It
+// fails if the account has not opted in the application.
+func LocalState(account models.Account, appID uint64) (State, error) {
+	for _, local := range account.AppsLocalState {
+		if local.Id == appID {
+			return Decode(local.KeyValue)
+		}
+	}
+	return nil, fmt.Errorf("account %s has not opted in application %d", account.Address, appID)
+}
+
+// Uint returns the uint held under key, if any
+func (s State) Uint(key string) (uint64, bool) {
+	v, ok := s[key]
+	if !ok || v.Type != TypeUint {
+		return 0, false
+	}
+	return v.Uint, true
+}
+
+// Bytes returns the byte slice held under key, if any
+func (s State) Bytes(key string) ([]byte, bool) {
+	v, ok := s[key]
+	if !ok || v.Type != TypeBytes {
+		return nil, false
+	}
+	return v.Bytes, true
+}
+
+// Keys returns the keys of the state, sorted
+func (s State) Keys() []string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Clone returns a copy of the state that can be modified independently
+func (s State) Clone() State {
+	clone := make(State, len(s))
+	for key, value := range s {
+		if value.Bytes != nil {
+			value.Bytes = append([]byte{}, value.Bytes...)
+		}
+		clone[key] = value
+	}
+	return clone
+}
+
+// Apply updates the state in place with deltas, such as the global state delta
+// of a confirmed transaction
+func (s State) Apply(deltas []models.EvalDeltaKeyValue) error {
+	for _, delta := range deltas {
+		key, err := base64.StdEncoding.DecodeString(delta.Key)
+		if err != nil {
+			return fmt.Errorf("delta key %q: %v", delta.Key, err)
+		}
+		switch delta.Value.Action {
+		case actionSetUint:
+			s[string(key)] = UintValue(delta.Value.Uint)
+		case actionSetBytes:
+			b, err := base64.StdEncoding.DecodeString(delta.Value.Bytes)
+			if err != nil {
+				return fmt.Errorf("delta key %s: %v", formatBytes(key), err)
+			}
+			s[string(key)] = BytesValue(b)
+		case actionDelete:
+			delete(s, string(key))
+		default:
+			return fmt.Errorf("delta key %s: unknown action %d", formatBytes(key), delta.Value.Action)
+		}
+	}
+	return nil
+}
+
+// Snapshot is the state of an application: its global state and the local
+// state of the accounts being followed, indexed by address
+type Snapshot struct {
+	Global State
+	Local  map[string]State
+}
+
+// Clone returns a copy of the snapshot that can be modified independently
+func (s Snapshot) Clone() Snapshot {
+	clone := Snapshot{Global: s.Global.Clone(), Local: make(map[string]State, len(s.Local))}
+	for addr, state := range s.Local {
+		clone.Local[addr] = state.Clone()
+	}
+	return clone
+}
+
+// Apply updates the snapshot in place with the deltas of a transaction. Local
+// deltas of accounts missing from the snapshot start from an empty state.
+func (s *Snapshot) Apply(global []models.EvalDeltaKeyValue, local []models.AccountStateDelta) error {
+	if s.Global == nil {
+		s.Global = make(State)
+	}
+	if err := s.Global.Apply(global); err != nil {
+		return fmt.Errorf("global state: %v", err)
+	}
+	for _, delta := range local {
+		if s.Local == nil {
+			s.Local = make(map[string]State)
+		}
+		state, ok := s.Local[delta.Address]
+		if !ok {
+			state = make(State)
+			s.Local[delta.Address] = state
+		}
+		if err := state.Apply(delta.Delta); err != nil {
+			return fmt.Errorf("local state of %s: %v", delta.Address, err)
+		}
+	}
+	return nil
+}
+
+// ApplyPending updates the snapshot in place with the effects of a confirmed
+// transaction, as returned by PendingTransactionInformation
+func (s *Snapshot) ApplyPending(info models.PendingTransactionInfoResponse) error {
+	if info.ConfirmedRound == 0 {
+		return fmt.Errorf("transaction is not confirmed")
+	}
+	return s.Apply(info.GlobalStateDelta, info.LocalStateDelta)
+}
+
+// Change is the change of a single key between two states. Old is the zero
+// Value when the key was added and New is the zero Value when it was deleted.
+type Change struct {
+	Key string
+	Old Value
+	New Value
+}
+
+// Added reports whether the key did not exist in the old state
+func (c Change) Added() bool {
+	return c.Old.Type == 0
+}
+
+// Deleted reports whether the key does not exist in the new state
+func (c Change) Deleted() bool {
+	return c.New.Type == 0
+}
+
+func (c Change) String() string {
+	key := formatBytes([]byte(c.Key))
+	switch {
+	case c.Added():
+		return fmt.Sprintf("+ %s = %s", key, c.New)
+	case c.Deleted():
+		return fmt.Sprintf("- %s = %s", key, c.Old)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", key, c.Old, c.New)
+}
+
+// Diff returns the changes turning old into new, sorted by key
+func Diff(old, new State) []Change {
+	var changes []Change
+	for key, oldValue := range old {
+		newValue, ok := new[key]
+		if !ok {
+			changes = append(changes, Change{Key: key, Old: oldValue})
+		} else if !oldValue.Equal(newValue) {
+			changes = append(changes, Change{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+	for key, newValue := range new {
+		if _, ok := old[key]; !ok {
+			changes = append(changes, Change{Key: key, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// Deltas encodes changes as the deltas of the REST API, the inverse of Apply
+func Deltas(changes []Change) []models.EvalDeltaKeyValue {
+	deltas := make([]models.EvalDeltaKeyValue, 0, len(changes))
+	for _, change := range changes {
+		delta := models.EvalDeltaKeyValue{Key: base64.StdEncoding.EncodeToString([]byte(change.Key))}
+		switch {
+		case change.Deleted():
+			delta.Value.Action = actionDelete
+		case change.New.Type == TypeUint:
+			delta.Value.Action = actionSetUint
+			delta.Value.Uint = change.New.Uint
+		default:
+			delta.Value.Action = actionSetBytes
+			delta.Value.Bytes = base64.StdEncoding.EncodeToString(change.New.Bytes)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas
+}
@@ -0,0 +1,151 @@
+package appstate
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+var owner = types.Address{1, 2, 3}
+
+func testState() []models.TealKeyValue {
+	return []models.TealKeyValue{
+		{Key: b64("counter"), Value: models.TealValue{Type: 2, Uint: 7}},
+		{Key: b64("name"), Value: models.TealValue{Type: 1, Bytes: b64("token")}},
+		{Key: b64("owner"), Value: models.TealValue{Type: 1, Bytes: base64.StdEncoding.EncodeToString(owner[:])}},
+		{Key: b64("paused"), Value: models.TealValue{Type: 2, Uint: 1}},
+	}
+}
+
+func TestDecode(t *testing.T) {
+	state, err := Decode(testState())
+	require.NoError(t, err)
+	require.Equal(t, []string{"counter", "name", "owner", "paused"}, state.Keys())
+
+	counter, ok := state.Uint("counter")
+	require.True(t, ok)
+	require.Equal(t, uint64(7), counter)
+
+	name, ok := state.Bytes("name")
+	require.True(t, ok)
+	require.Equal(t, []byte("token"), name)
+
+	_, ok = state.Uint("name")
+	require.False(t, ok)
+
+	_, err = Decode([]models.TealKeyValue{{Key: b64("k"), Value: models.TealValue{Type: 9}}})
+	require.Error(t, err)
+}
+
+func TestLocalState(t *testing.T) {
+	account := models.Account{
+		Address: "ADDR",
+		AppsLocalState: []models.ApplicationLocalState{
+			{Id: 5, KeyValue: testState()},
+		},
+	}
+	state, err := LocalState(account, 5)
+	require.NoError(t, err)
+	require.Len(t, state, 4)
+
+	_, err = LocalState(account, 6)
+	require.Error(t, err)
+}
+
+func TestApplyAndDiff(t *testing.T) {
+	before, err := Decode(testState())
+	require.NoError(t, err)
+
+	snapshot := Snapshot{Global: before.Clone()}
+	info := models.PendingTransactionInfoResponse{
+		ConfirmedRound: 10,
+		GlobalStateDelta: []models.EvalDeltaKeyValue{
+			{Key: b64("counter"), Value: models.EvalDelta{Action: 2, Uint: 8}},
+			{Key: b64("paused"), Value: models.EvalDelta{Action: 3}},
+			{Key: b64("symbol"), Value: models.EvalDelta{Action: 1, Bytes: b64("TOK")}},
+		},
+		LocalStateDelta: []models.AccountStateDelta{
+			{Address: "ADDR", Delta: []models.EvalDeltaKeyValue{
+				{Key: b64("balance"), Value: models.EvalDelta{Action: 2, Uint: 100}},
+			}},
+		},
+	}
+	require.NoError(t, snapshot.ApplyPending(info))
+
+	// the original state is left untouched
+	counter, _ := before.Uint("counter")
+	require.Equal(t, uint64(7), counter)
+
+	changes := Diff(before, snapshot.Global)
+	require.Len(t, changes, 3)
+	require.Equal(t, "counter", changes[0].Key)
+	require.Equal(t, `~ "counter": 7 -> 8`, changes[0].String())
+	require.True(t, changes[1].Deleted())
+	require.Equal(t, `- "paused" = 1`, changes[1].String())
+	require.True(t, changes[2].Added())
+	require.Equal(t, `+ "symbol" = "TOK"`, changes[2].String())
+
+	balance, ok := snapshot.Local["ADDR"].Uint("balance")
+	require.True(t, ok)
+	require.Equal(t, uint64(100), balance)
+
+	require.Empty(t, Diff(before, before.Clone()))
+	require.Error(t, snapshot.ApplyPending(models.PendingTransactionInfoResponse{}))
+
+	// the deltas of the changes turn the old state into the new one
+	replayed := before.Clone()
+	require.NoError(t, replayed.Apply(Deltas(changes)))
+	require.Empty(t, Diff(snapshot.Global, replayed))
+
+	decoded, err := Decode(snapshot.Global.Encode())
+	require.NoError(t, err)
+	require.Empty(t, Diff(snapshot.Global, decoded))
+}
+
+func TestBind(t *testing.T) {
+	state, err := Decode(testState())
+	require.NoError(t, err)
+
+	var global struct {
+		Counter uint32        `appstate:"counter,required"`
+		Name    string        `appstate:"name"`
+		Raw     []byte        `appstate:"name"`
+		Owner   types.Address `appstate:"owner"`
+		Paused  bool          `appstate:"paused"`
+		Missing uint64        `appstate:"missing"`
+		Ignored string
+	}
+	global.Missing = 3
+	require.NoError(t, Bind(state, &global))
+	require.Equal(t, uint32(7), global.Counter)
+	require.Equal(t, "token", global.Name)
+	require.Equal(t, []byte("token"), global.Raw)
+	require.Equal(t, owner, global.Owner)
+	require.True(t, global.Paused)
+	require.Zero(t, global.Missing)
+
+	var wrongType struct {
+		Name uint64 `appstate:"name"`
+	}
+	require.Error(t, Bind(state, &wrongType))
+
+	var required struct {
+		Missing uint64 `appstate:"missing,required"`
+	}
+	require.Error(t, Bind(state, &required))
+
+	var overflow struct {
+		Counter uint8 `appstate:"counter"`
+	}
+	state["counter"] = UintValue(300)
+	require.Error(t, Bind(state, &overflow))
+
+	require.Error(t, Bind(state, global))
+}
@@ -0,0 +1,136 @@
+package appstate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// tagName is the struct tag naming the state key of a field
+const tagName = "appstate"
+
+var addressType = reflect.TypeOf(types.Address{})
+
+// Bind copies the state into the struct pointed to by objptr. Every field
+// tagged `appstate:"key"` receives the value held under key; untagged fields
+// and fields tagged "-" are left alone. Adding ",required" to the tag makes
+// Bind fail when the key is missing, otherwise the field is set to its zero
+// value.
+//
+// Unsigned integer and bool fields are bound to uint values, bool being true
+// for any nonzero value. []byte, string, types.Address and byte array fields
+// are bound to byte slice values; addresses and arrays require the value to
+// have their exact length.
+func Bind(state State, objptr interface{}) error {
+	v := reflect.ValueOf(objptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can only bind state into a pointer to a struct, got %T", objptr)
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, required, ok := parseTag(field)
+		if !ok {
+			continue
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("field %s is not exported", field.Name)
+		}
+		value, found := state[key]
+		if !found {
+			if required {
+				return fmt.Errorf("field %s: key %s is missing", field.Name, formatBytes([]byte(key)))
+			}
+			v.Field(i).Set(reflect.Zero(field.Type))
+			continue
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func parseTag(field reflect.StructField) (key string, required bool, ok bool) {
+	tag, ok := field.Tag.Lookup(tagName)
+	if !ok || tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "required" {
+			required = true
+		}
+	}
+	return parts[0], required, true
+}
+
+func setField(field reflect.Value, value Value) error {
+	switch {
+	case field.Type() == addressType:
+		if err := expectType(value, TypeBytes); err != nil {
+			return err
+		}
+		if len(value.Bytes) != len(types.Address{}) {
+			return fmt.Errorf("address must be %d bytes, got %d", len(types.Address{}), len(value.Bytes))
+		}
+		var addr types.Address
+		copy(addr[:], value.Bytes)
+		field.Set(reflect.ValueOf(addr))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if err := expectType(value, TypeUint); err != nil {
+			return err
+		}
+		if field.OverflowUint(value.Uint) {
+			return fmt.Errorf("value %d overflows %s", value.Uint, field.Type())
+		}
+		field.SetUint(value.Uint)
+	case reflect.Bool:
+		if err := expectType(value, TypeUint); err != nil {
+			return err
+		}
+		field.SetBool(value.Uint != 0)
+	case reflect.String:
+		if err := expectType(value, TypeBytes); err != nil {
+			return err
+		}
+		field.SetString(string(value.Bytes))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		if err := expectType(value, TypeBytes); err != nil {
+			return err
+		}
+		field.SetBytes(append([]byte{}, value.Bytes...))
+	case reflect.Array:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		if err := expectType(value, TypeBytes); err != nil {
+			return err
+		}
+		if len(value.Bytes) != field.Len() {
+			return fmt.Errorf("value must be %d bytes, got %d", field.Len(), len(value.Bytes))
+		}
+		reflect.Copy(field, reflect.ValueOf(value.Bytes))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+func expectType(value Value, expected ValueType) error {
+	if value.Type != expected {
+		return fmt.Errorf("expected a %s value, got %s", expected, value.Type)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+// Package assets provides workflows for issuers of Algorand Standard Assets.
+//
+// An Issuer acts on one asset with the accounts holding its roles: it
+// freezes, unfreezes and claws back holdings in bulk, batching the
+// transactions into groups, and reconfigures the roles of the asset without
+// clearing one by mistake. Cleared roles can never be set again, so
+// Reconfigure fails with a *ClearRoleError unless clearing is explicitly
+// allowed.
+//
+// Holders are read from the indexer, at the latest round or at a given one
+// with Snapshot. MetadataHash computes the commitment stored in the
+// MetadataHash of an asset from its metadata file.
+package assets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/client/v2/indexer"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/jffp113/go-algorand-sdk/validate"
+)
+
+// defaultWaitRounds is the number of rounds a group waits to be confirmed
+const defaultWaitRounds = 10
+
+// Issuer manages an asset. Its transactions are sent by the accounts holding
+// the roles of the asset, which the signer must be able to sign for.
+type Issuer struct {
+	algod   *algod.Client
+	indexer *indexer.Client
+	assetID uint64
+	signer  future.TransactionSigner
+
+	// WaitRounds is the number of rounds to wait for each group to be
+	// confirmed
+	WaitRounds uint64
+
+	// BatchSize is the number of transactions of each group submitted by bulk
+	// operations. Zero or a value above the maximum group size of the
+	// protocol means the maximum group size.
+	BatchSize int
+}
+
+// NewIssuer returns an Issuer of the asset assetID signing with signer
+func NewIssuer(algodClient *algod.Client, indexerClient *indexer.Client, assetID uint64, signer future.TransactionSigner) *Issuer {
+	return &Issuer{
+		algod:      algodClient,
+		indexer:    indexerClient,
+		assetID:    assetID,
+		signer:     signer,
+		WaitRounds: defaultWaitRounds,
+	}
+}
+
+// Batch is a group of transactions submitted by a bulk operation
+type Batch struct {
+	// Addresses are the accounts acted on, in the order of TxIDs
+	Addresses      []types.Address
+	TxIDs          []string
+	ConfirmedRound uint64
+}
+
+// Clawback is an amount to claw back from a holder
+type Clawback struct {
+	From   types.Address
+	Amount uint64
+}
+
+// params returns the current parameters of the asset
+func (is *Issuer) params(ctx context.Context) (models.AssetParams, error) {
+	asset, err := is.algod.GetAssetByID(is.assetID).Do(ctx)
+	if err != nil {
+		return models.AssetParams{}, fmt.Errorf("fetching asset %d: %v", is.assetID, err)
+	}
+	return asset.Params, nil
+}
+
+// role returns the address holding the role name of the asset, failing when
+// the role is cleared
+func role(assetID uint64, name, addr string) (string, error) {
+	if addr == "" {
+		return "", fmt.Errorf("asset %d has no %s", assetID, name)
+	}
+	return addr, nil
+}
+
+// Freeze freezes or unfreezes the holdings of addrs, in groups sent by the
+// freeze account of the asset. On failure, it returns the batches confirmed
+// before the failing one.
+func (is *Issuer) Freeze(ctx context.Context, addrs []types.Address, frozen bool) ([]Batch, error) {
+	params, err := is.params(ctx)
+	if err != nil {
+		return nil, err
+	}
+	freeze, err := role(is.assetID, "freeze account", params.Freeze)
+	if err != nil {
+		return nil, err
+	}
+	return is.submit(ctx, addrs, func(i int, sp types.SuggestedParams) (types.Transaction, error) {
+		return future.MakeAssetFreezeTxn(freeze, nil, sp, is.assetID, addrs[i].String(), frozen)
+	})
+}
+
+// issuerAccounts returns the creator of the asset and the accounts holding
+// its roles, which bulk operations never act on
+func (is *Issuer) issuerAccounts(ctx context.Context) (map[types.Address]bool, error) {
+	params, err := is.params(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := RolesOf(params)
+	if err != nil {
+		return nil, fmt.Errorf("asset %d: %v", is.assetID, err)
+	}
+	creator, err := decodeRole("creator", params.Creator)
+	if err != nil {
+		return nil, fmt.Errorf("asset %d: %v", is.assetID, err)
+	}
+	accounts := make(map[types.Address]bool)
+	for _, addr := range []types.Address{creator, roles.Manager, roles.Reserve, roles.Freeze, roles.Clawback} {
+		if !addr.IsZero() {
+			accounts[addr] = true
+		}
+	}
+	return accounts, nil
+}
+
+// holders returns the current holders selected by filter, or all holders when
+// filter is nil, except the creator of the asset and the accounts holding its
+// roles
+func (is *Issuer) holders(ctx context.Context, filter func(Holder) bool) ([]Holder, error) {
+	excluded, err := is.issuerAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := is.Snapshot(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	var holders []Holder
+	for _, h := range snapshot.Holders {
+		if !excluded[h.Address] && (filter == nil || filter(h)) {
+			holders = append(holders, h)
+		}
+	}
+	return holders, nil
+}
+
+// FreezeHolders freezes or unfreezes the holdings of the current holders
+// selected by filter, or of all holders when filter is nil. The creator of the
+// asset and the accounts holding its roles are never selected, so that the
+// supply of the issuer is not frozen by mistake: use Freeze to act on them.
+// Holders already in the requested state are skipped.
+func (is *Issuer) FreezeHolders(ctx context.Context, frozen bool, filter func(Holder) bool) ([]Batch, error) {
+	holders, err := is.holders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var addrs []types.Address
+	for _, h := range holders {
+		if h.Frozen != frozen {
+			addrs = append(addrs, h.Address)
+		}
+	}
+	return is.Freeze(ctx, addrs, frozen)
+}
+
+// Clawback claws back amounts from holders to recipient, in groups sent by
+// the clawback account of the asset. On failure, it returns the batches
+// confirmed before the failing one.
+func (is *Issuer) Clawback(ctx context.Context, clawbacks []Clawback, recipient types.Address) ([]Batch, error) {
+	params, err := is.params(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clawback, err := role(is.assetID, "clawback account", params.Clawback)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]types.Address, len(clawbacks))
+	for i, c := range clawbacks {
+		addrs[i] = c.From
+	}
+	return is.submit(ctx, addrs, func(i int, sp types.SuggestedParams) (types.Transaction, error) {
+		c := clawbacks[i]
+		return future.MakeAssetRevocationTxn(clawback, c.From.String(), c.Amount, recipient.String(), nil, sp, is.assetID)
+	})
+}
+
+// ClawbackHolders claws back the whole holdings of the current holders
+// selected by filter, or of all holders when filter is nil, to recipient. The
+// creator of the asset and the accounts holding its roles are never selected:
+// use Clawback to act on them. Empty holdings and the holding of recipient are
+// skipped.
+func (is *Issuer) ClawbackHolders(ctx context.Context, recipient types.Address, filter func(Holder) bool) ([]Batch, error) {
+	holders, err := is.holders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var clawbacks []Clawback
+	for _, h := range holders {
+		if h.Amount > 0 && h.Address != recipient {
+			clawbacks = append(clawbacks, Clawback{From: h.Address, Amount: h.Amount})
+		}
+	}
+	return is.Clawback(ctx, clawbacks, recipient)
+}
+
+// submit builds with build the transaction acting on each of addrs and
+// submits them in groups of BatchSize transactions
+func (is *Issuer) submit(ctx context.Context, addrs []types.Address, build func(i int, sp types.SuggestedParams) (types.Transaction, error)) ([]Batch, error) {
+	var batches []Batch
+	for start := 0; start < len(addrs); {
+		// suggested params are fetched for every group, so that long
+		// operations do not outlive the validity window of their transactions
+		sp, err := is.algod.SuggestedParams().Do(ctx)
+		if err != nil {
+			return batches, err
+		}
+		params, err := consensus.FromSuggestedParams(sp)
+		if err != nil {
+			return batches, err
+		}
+		size := is.BatchSize
+		if size <= 0 || size > params.MaxTxGroupSize {
+			size = params.MaxTxGroupSize
+		}
+		end := start + size
+		if end > len(addrs) {
+			end = len(addrs)
+		}
+
+		batch, err := is.execute(ctx, start, end, sp, params, build)
+		if err != nil {
+			return batches, fmt.Errorf("batch of transactions %d to %d: %v", start, end-1, err)
+		}
+		batch.Addresses = addrs[start:end]
+		batches = append(batches, batch)
+		start = end
+	}
+	return batches, nil
+}
+
+func (is *Issuer) execute(ctx context.Context, start, end int, sp types.SuggestedParams, params consensus.Params, build func(i int, sp types.SuggestedParams) (types.Transaction, error)) (Batch, error) {
+	var atc future.AtomicTransactionComposer
+	for i := start; i < end; i++ {
+		txn, err := build(i, sp)
+		if err != nil {
+			return Batch{}, err
+		}
+		if err := atc.AddTransaction(future.TransactionWithSigner{Txn: txn, Signer: is.signer}); err != nil {
+			return Batch{}, err
+		}
+	}
+	group, err := atc.BuildGroup()
+	if err != nil {
+		return Batch{}, err
+	}
+	txns := make([]types.Transaction, len(group))
+	for i, tws := range group {
+		txns[i] = tws.Txn
+	}
+	if err := validate.Group(txns, params); err != nil {
+		return Batch{}, err
+	}
+	executed, err := atc.Execute(ctx, is.algod, is.WaitRounds)
+	if err != nil {
+		return Batch{}, err
+	}
+	return Batch{TxIDs: executed.TxIDs, ConfirmedRound: executed.ConfirmedRound}, nil
+}
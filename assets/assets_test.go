@@ -0,0 +1,265 @@
+package assets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/client/v2/indexer"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+const assetID = 42
+
+// network is a fake algod and indexer keeping the holders of an asset
+type network struct {
+	asset   models.Asset
+	holders []models.MiniAssetHolding
+	groups  [][]types.Transaction
+	rounds  []string
+}
+
+func (n *network) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/transactions/params":
+			json.NewEncoder(w).Encode(models.TransactionParams{GenesisID: "test", Genesishash: []byte("01234567890123456789012345678901"), LastRound: 1000})
+		case r.URL.Path == "/v2/status":
+			json.NewEncoder(w).Encode(models.NodeStatus{LastRound: 1000})
+		case r.URL.Path == "/v2/transactions":
+			dec := msgpack.NewDecoder(r.Body)
+			var group []types.Transaction
+			for {
+				var stx types.SignedTxn
+				err := dec.Decode(&stx)
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				group = append(group, stx.Txn)
+				n.apply(stx.Txn)
+			}
+			n.groups = append(n.groups, group)
+			json.NewEncoder(w).Encode(map[string]string{"txId": "unused"})
+		case strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/"):
+			w.Write(msgpack.Encode(models.PendingTransactionInfoResponse{ConfirmedRound: 1001}))
+		case r.URL.Path == "/v2/assets/42":
+			json.NewEncoder(w).Encode(n.asset)
+		case r.URL.Path == "/v2/assets/42/balances":
+			// pages of 8 holders, the next token being the offset of the page
+			n.rounds = append(n.rounds, r.URL.Query().Get("round"))
+			start, _ := strconv.Atoi(r.URL.Query().Get("next"))
+			response := models.AssetBalancesResponse{CurrentRound: 999, Balances: n.holders[start:]}
+			if len(response.Balances) > 8 {
+				response.Balances = response.Balances[:8]
+				response.NextToken = strconv.Itoa(start + 8)
+			}
+			json.NewEncoder(w).Encode(response)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func (n *network) holder(addr types.Address) *models.MiniAssetHolding {
+	for i := range n.holders {
+		if n.holders[i].Address == addr.String() {
+			return &n.holders[i]
+		}
+	}
+	return nil
+}
+
+func (n *network) apply(txn types.Transaction) {
+	switch txn.Type {
+	case types.AssetFreezeTx:
+		n.holder(txn.FreezeAccount).IsFrozen = txn.AssetFrozen
+	case types.AssetTransferTx:
+		n.holder(txn.AssetSender).Amount -= txn.AssetAmount
+		n.holder(txn.AssetReceiver).Amount += txn.AssetAmount
+	case types.AssetConfigTx:
+		n.asset.Params.Manager = roleString(txn.AssetParams.Manager)
+		n.asset.Params.Reserve = roleString(txn.AssetParams.Reserve)
+		n.asset.Params.Freeze = roleString(txn.AssetParams.Freeze)
+		n.asset.Params.Clawback = roleString(txn.AssetParams.Clawback)
+	}
+}
+
+func newIssuer(t *testing.T, holders int) (*Issuer, *network, crypto.Account, func()) {
+	issuer := crypto.GenerateAccount()
+	addr := issuer.Address.String()
+	net := &network{asset: models.Asset{Index: assetID, Params: models.AssetParams{
+		Creator: addr, Manager: addr, Reserve: addr, Freeze: addr, Clawback: addr, Total: 1000000,
+	}}}
+	net.holders = append(net.holders, models.MiniAssetHolding{Address: addr, Amount: 1000000 - uint64(holders)*10})
+	for i := 0; i < holders; i++ {
+		net.holders = append(net.holders, models.MiniAssetHolding{Address: crypto.GenerateAccount().Address.String(), Amount: 10})
+	}
+
+	server := httptest.NewServer(net.handler(t))
+	algodClient, err := algod.MakeClient(server.URL, "")
+	require.NoError(t, err)
+	indexerClient, err := indexer.MakeClient(server.URL, "")
+	require.NoError(t, err)
+	return NewIssuer(algodClient, indexerClient, assetID, future.BasicAccountTransactionSigner{Account: issuer}), net, issuer, server.Close
+}
+
+func TestSnapshot(t *testing.T) {
+	is, net, issuer, closeServer := newIssuer(t, 20)
+	defer closeServer()
+
+	snapshot, err := is.Snapshot(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(999), snapshot.Round)
+	require.Len(t, snapshot.Holders, 21)
+	require.Equal(t, uint64(1000000), snapshot.Total())
+	h, ok := snapshot.Holder(issuer.Address)
+	require.True(t, ok)
+	require.Equal(t, uint64(999800), h.Amount)
+
+	// the pages after the first are read at the round of the first
+	require.Equal(t, []string{"", "999", "999"}, net.rounds)
+
+	net.rounds = nil
+	snapshot, err = is.Snapshot(context.Background(), 500)
+	require.NoError(t, err)
+	require.Equal(t, uint64(500), snapshot.Round)
+	require.Equal(t, []string{"500", "500", "500"}, net.rounds)
+}
+
+func TestBulkFreezeAndClawback(t *testing.T) {
+	is, net, issuer, closeServer := newIssuer(t, 20)
+	defer closeServer()
+	ctx := context.Background()
+
+	// holders other than the issuer are frozen in groups of 16, the issuer
+	// being excluded without a filter
+	batches, err := is.FreezeHolders(ctx, true, nil)
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0].TxIDs, 16)
+	require.Len(t, batches[1].Addresses, 4)
+	require.Equal(t, uint64(1001), batches[0].ConfirmedRound)
+	for _, txn := range net.groups[0] {
+		require.Equal(t, types.AssetFreezeTx, txn.Type)
+		require.Equal(t, net.groups[0][0].Group, txn.Group)
+	}
+	require.NotEqual(t, types.Digest{}, net.groups[0][0].Group)
+	for _, h := range net.holders[1:] {
+		require.True(t, h.IsFrozen)
+	}
+
+	require.False(t, net.holders[0].IsFrozen)
+
+	// holders already frozen are skipped
+	groups := len(net.groups)
+	batches, err = is.FreezeHolders(ctx, true, nil)
+	require.NoError(t, err)
+	require.Empty(t, batches)
+	require.Len(t, net.groups, groups)
+
+	// the holdings are clawed back to the issuer, except the one of the
+	// reserve account
+	reserve := net.holders[20]
+	net.asset.Params.Reserve = reserve.Address
+	is.BatchSize = 5
+	batches, err = is.ClawbackHolders(ctx, issuer.Address, nil)
+	require.NoError(t, err)
+	require.Len(t, batches, 4)
+	require.Equal(t, uint64(1000000-10), net.holders[0].Amount)
+	for _, h := range net.holders[1:20] {
+		require.Zero(t, h.Amount)
+	}
+	require.Equal(t, uint64(10), net.holders[20].Amount)
+
+	// nothing is left to claw back
+	groups = len(net.groups)
+	batches, err = is.ClawbackHolders(ctx, issuer.Address, nil)
+	require.NoError(t, err)
+	require.Empty(t, batches)
+	require.Len(t, net.groups, groups)
+
+	// without a clawback account, nothing can be clawed back
+	net.asset.Params.Clawback = ""
+	_, err = is.Clawback(ctx, []Clawback{{From: issuer.Address, Amount: 1}}, issuer.Address)
+	require.Error(t, err)
+}
+
+func TestReconfigure(t *testing.T) {
+	is, net, issuer, closeServer := newIssuer(t, 0)
+	defer closeServer()
+	ctx := context.Background()
+
+	current, err := RolesOf(net.asset.Params)
+	require.NoError(t, err)
+	require.Equal(t, issuer.Address, current.Clawback)
+
+	// clearing the clawback role has to be allowed
+	next := current
+	next.Clawback = types.Address{}
+	require.Equal(t, []string{"clawback"}, ClearedRoles(current, next))
+	_, err = is.Reconfigure(ctx, next, false)
+	require.IsType(t, &ClearRoleError{}, err)
+	require.Empty(t, net.groups)
+
+	_, err = is.Reconfigure(ctx, next, true)
+	require.NoError(t, err)
+	require.Equal(t, "", net.asset.Params.Clawback)
+	require.Equal(t, issuer.Address.String(), net.asset.Params.Freeze)
+
+	// a cleared role cannot be set again
+	next.Clawback = issuer.Address
+	_, err = is.Reconfigure(ctx, next, true)
+	require.Error(t, err)
+
+	// roles move to other accounts
+	other := crypto.GenerateAccount().Address
+	next = current
+	next.Clawback = types.Address{}
+	next.Reserve = other
+	_, err = is.Reconfigure(ctx, next, false)
+	require.NoError(t, err)
+	require.Equal(t, other.String(), net.asset.Params.Reserve)
+
+	// clearing every role would destroy the asset
+	_, err = is.Reconfigure(ctx, Roles{}, true)
+	require.Error(t, err)
+}
+
+func TestMetadataHash(t *testing.T) {
+	metadata := []byte(`{"name":"test"}`)
+	hash, err := MetadataHash(bytes.NewReader(metadata))
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "assets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "metadata.json")
+	require.NoError(t, ioutil.WriteFile(path, metadata, 0644))
+	fromFile, err := MetadataHashFile(path)
+	require.NoError(t, err)
+	require.Equal(t, hash, fromFile)
+
+	addr := crypto.GenerateAccount().Address.String()
+	txn, err := future.MakeAssetCreateTxn(addr, nil, types.SuggestedParams{GenesisHash: make([]byte, 32)}, 1, 0, false, "", "", "", "", "T", "test", "", string(hash[:]))
+	require.NoError(t, err)
+	require.Equal(t, hash, txn.AssetParams.MetadataHash)
+
+	_, err = MetadataHashFile(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/jffp113/go-algorand-sdk/validate"
+)
+
+// Roles are the accounts allowed to manage an asset. A zero address is a
+// cleared role.
+type Roles struct {
+	Manager  types.Address
+	Reserve  types.Address
+	Freeze   types.Address
+	Clawback types.Address
+}
+
+// RolesOf returns the roles of an asset with params
+func RolesOf(params models.AssetParams) (Roles, error) {
+	var roles Roles
+	addrs := []string{params.Manager, params.Reserve, params.Freeze, params.Clawback}
+	for i, r := range roles.fields() {
+		addr, err := decodeRole(r.name, addrs[i])
+		if err != nil {
+			return Roles{}, err
+		}
+		*r.addr = addr
+	}
+	return roles, nil
+}
+
+type roleField struct {
+	name string
+	addr *types.Address
+}
+
+// fields returns the roles in the order manager, reserve, freeze, clawback
+func (r *Roles) fields() []roleField {
+	return []roleField{
+		{"manager", &r.Manager},
+		{"reserve", &r.Reserve},
+		{"freeze", &r.Freeze},
+		{"clawback", &r.Clawback},
+	}
+}
+
+func decodeRole(name, addr string) (types.Address, error) {
+	if addr == "" {
+		return types.Address{}, nil
+	}
+	decoded, err := types.DecodeAddress(addr)
+	if err != nil {
+		return types.Address{}, fmt.Errorf("%s: %v", name, err)
+	}
+	return decoded, nil
+}
+
+func roleString(addr types.Address) string {
+	if addr.IsZero() {
+		return ""
+	}
+	return addr.String()
+}
+
+// ClearedRoles returns the names of the roles set in current and cleared in
+// next. A cleared role can never be set again.
+func ClearedRoles(current, next Roles) []string {
+	var cleared []string
+	nextFields := next.fields()
+	for i, r := range current.fields() {
+		if !r.addr.IsZero() && nextFields[i].addr.IsZero() {
+			cleared = append(cleared, r.name)
+		}
+	}
+	return cleared
+}
+
+// ClearRoleError is returned by Reconfigure when the new roles would clear
+// roles of the asset
+type ClearRoleError struct {
+	AssetID uint64
+	Roles   []string
+}
+
+func (e *ClearRoleError) Error() string {
+	return fmt.Sprintf("reconfiguring asset %d would permanently clear its %s", e.AssetID, strings.Join(e.Roles, ", "))
+}
+
+// Reconfigure replaces the roles of the asset with roles, in a transaction
+// sent by its manager. Every role must be given, as roles left zero are
+// cleared: unless allowClear is set, Reconfigure fails with a
+// *ClearRoleError instead of clearing a role. Roles already cleared cannot
+// be set again.
+func (is *Issuer) Reconfigure(ctx context.Context, roles Roles, allowClear bool) (future.TransactionResult, error) {
+	params, err := is.params(ctx)
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	current, err := RolesOf(params)
+	if err != nil {
+		return future.TransactionResult{}, fmt.Errorf("asset %d: %v", is.assetID, err)
+	}
+	if roles == (Roles{}) {
+		// a configuration without any role destroys the asset
+		return future.TransactionResult{}, fmt.Errorf("reconfiguring asset %d would clear all its roles, destroying it", is.assetID)
+	}
+	if current.Manager.IsZero() {
+		return future.TransactionResult{}, fmt.Errorf("asset %d has no manager and cannot be reconfigured", is.assetID)
+	}
+	if set := ClearedRoles(roles, current); len(set) > 0 {
+		return future.TransactionResult{}, fmt.Errorf("asset %d has no %s, which cannot be set again", is.assetID, strings.Join(set, ", "))
+	}
+	if cleared := ClearedRoles(current, roles); len(cleared) > 0 && !allowClear {
+		return future.TransactionResult{}, &ClearRoleError{AssetID: is.assetID, Roles: cleared}
+	}
+
+	sp, err := is.algod.SuggestedParams().Do(ctx)
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	// empty roles were checked above, strict checking would reject the roles
+	// that are already cleared
+	txn, err := future.MakeAssetConfigTxn(current.Manager.String(), nil, sp, is.assetID,
+		roleString(roles.Manager), roleString(roles.Reserve), roleString(roles.Freeze), roleString(roles.Clawback), false)
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	consensusParams, err := consensus.FromSuggestedParams(sp)
+	if err != nil {
+		return future.TransactionResult{}, err
+	}
+	if err := validate.Transaction(txn, consensusParams); err != nil {
+		return future.TransactionResult{}, err
+	}
+
+	var atc future.AtomicTransactionComposer
+	if err := atc.AddTransaction(future.TransactionWithSigner{Txn: txn, Signer: is.signer}); err != nil {
+		return future.TransactionResult{}, err
+	}
+	executed, err := atc.Execute(ctx, is.algod, is.WaitRounds)
+	if err != nil {
+		return future.TransactionResult{}, fmt.Errorf("reconfiguring asset %d: %v", is.assetID, err)
+	}
+	return executed.Results[0], nil
+}
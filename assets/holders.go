@@ -0,0 +1,73 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Holder is an account opted in the asset
+type Holder struct {
+	Address types.Address
+	Amount  uint64
+	Frozen  bool
+}
+
+// Snapshot is the list of the holders of an asset at a round
+type Snapshot struct {
+	AssetID uint64
+	Round   uint64
+	Holders []Holder
+}
+
+// Total returns the amount held by all the holders of the snapshot
+func (s Snapshot) Total() uint64 {
+	var total uint64
+	for _, h := range s.Holders {
+		total += h.Amount
+	}
+	return total
+}
+
+// Holder returns the holder at addr
+func (s Snapshot) Holder(addr types.Address) (Holder, bool) {
+	for _, h := range s.Holders {
+		if h.Address == addr {
+			return h, true
+		}
+	}
+	return Holder{}, false
+}
+
+// Snapshot returns the holders of the asset at round, or at the latest round
+// of the indexer when round is zero. All the pages of holders are read at
+// the same round, so the snapshot is consistent.
+func (is *Issuer) Snapshot(ctx context.Context, round uint64) (Snapshot, error) {
+	snapshot := Snapshot{AssetID: is.assetID, Round: round}
+	next := ""
+	for {
+		query := is.indexer.LookupAssetBalances(is.assetID).NextToken(next)
+		if snapshot.Round != 0 {
+			query.Round(snapshot.Round)
+		}
+		response, err := query.Do(ctx)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("looking up holders of asset %d: %v", is.assetID, err)
+		}
+		if snapshot.Round == 0 {
+			snapshot.Round = response.CurrentRound
+		}
+		for _, balance := range response.Balances {
+			addr, err := types.DecodeAddress(balance.Address)
+			if err != nil {
+				return Snapshot{}, fmt.Errorf("holder of asset %d: %v", is.assetID, err)
+			}
+			snapshot.Holders = append(snapshot.Holders, Holder{Address: addr, Amount: balance.Amount, Frozen: balance.IsFrozen})
+		}
+		if response.NextToken == "" || len(response.Balances) == 0 {
+			return snapshot, nil
+		}
+		next = response.NextToken
+	}
+}
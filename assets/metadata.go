@@ -0,0 +1,32 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"io"
+	"os"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// MetadataHash returns the SHA-256 hash of the metadata read from r, the
+// commitment ARC-3 assets store in their MetadataHash. Pass it to
+// future.MakeAssetCreateTxn as string(hash[:]).
+func MetadataHash(r io.Reader) ([types.AssetMetadataHashLen]byte, error) {
+	var hash [types.AssetMetadataHashLen]byte
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return hash, err
+	}
+	copy(hash[:], h.Sum(nil))
+	return hash, nil
+}
+
+// MetadataHashFile returns the MetadataHash of the file at path
+func MetadataHashFile(path string) ([types.AssetMetadataHashLen]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return [types.AssetMetadataHashLen]byte{}, err
+	}
+	defer f.Close()
+	return MetadataHash(f)
+}
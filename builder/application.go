@@ -0,0 +1,159 @@
+package builder
+
+import (
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// ApplicationCallBuilder builds application call transactions
+type ApplicationCallBuilder struct {
+	base
+	fields types.ApplicationCallTxnFields
+}
+
+func newApplicationCall(appIdx uint64, onComplete types.OnCompletion) *ApplicationCallBuilder {
+	b := &ApplicationCallBuilder{}
+	b.fields.ApplicationID = types.AppIndex(appIdx)
+	b.fields.OnCompletion = onComplete
+	return b
+}
+
+// NewApplicationCreate starts building a transaction creating an application
+func NewApplicationCreate() *ApplicationCallBuilder {
+	return newApplicationCall(0, types.NoOpOC)
+}
+
+// NewApplicationNoOp starts building a call to an existing application
+func NewApplicationNoOp(appIdx uint64) *ApplicationCallBuilder {
+	return newApplicationCall(appIdx, types.NoOpOC)
+}
+
+// NewApplicationOptIn starts building a transaction opting the sender in to an application
+func NewApplicationOptIn(appIdx uint64) *ApplicationCallBuilder {
+	return newApplicationCall(appIdx, types.OptInOC)
+}
+
+// NewApplicationCloseOut starts building a transaction closing the sender out of an application
+func NewApplicationCloseOut(appIdx uint64) *ApplicationCallBuilder {
+	return newApplicationCall(appIdx, types.CloseOutOC)
+}
+
+// NewApplicationClearState starts building a transaction clearing the sender's state of an application
+func NewApplicationClearState(appIdx uint64) *ApplicationCallBuilder {
+	return newApplicationCall(appIdx, types.ClearStateOC)
+}
+
+// NewApplicationUpdate starts building a transaction replacing the programs of an application
+func NewApplicationUpdate(appIdx uint64) *ApplicationCallBuilder {
+	return newApplicationCall(appIdx, types.UpdateApplicationOC)
+}
+
+// NewApplicationDelete starts building a transaction deleting an application
+func NewApplicationDelete(appIdx uint64) *ApplicationCallBuilder {
+	return newApplicationCall(appIdx, types.DeleteApplicationOC)
+}
+
+// OnCompletion overrides the on-completion action, e.g. to opt in while creating
+func (b *ApplicationCallBuilder) OnCompletion(onComplete types.OnCompletion) *ApplicationCallBuilder {
+	b.fields.OnCompletion = onComplete
+	return b
+}
+
+// Args appends application arguments
+func (b *ApplicationCallBuilder) Args(args ...[]byte) *ApplicationCallBuilder {
+	b.fields.ApplicationArgs = append(b.fields.ApplicationArgs, args...)
+	return b
+}
+
+// Accounts appends accounts the application may access
+func (b *ApplicationCallBuilder) Accounts(accounts ...types.Address) *ApplicationCallBuilder {
+	b.fields.Accounts = append(b.fields.Accounts, accounts...)
+	return b
+}
+
+// ForeignApps appends applications whose global state may be read
+func (b *ApplicationCallBuilder) ForeignApps(appIdxs ...uint64) *ApplicationCallBuilder {
+	for _, idx := range appIdxs {
+		b.fields.ForeignApps = append(b.fields.ForeignApps, types.AppIndex(idx))
+	}
+	return b
+}
+
+// ForeignAssets appends assets whose parameters may be read
+func (b *ApplicationCallBuilder) ForeignAssets(assetIdxs ...uint64) *ApplicationCallBuilder {
+	for _, idx := range assetIdxs {
+		b.fields.ForeignAssets = append(b.fields.ForeignAssets, types.AssetIndex(idx))
+	}
+	return b
+}
+
+// ApprovalProgram sets the compiled approval program, for creation and update
+func (b *ApplicationCallBuilder) ApprovalProgram(program []byte) *ApplicationCallBuilder {
+	b.fields.ApprovalProgram = program
+	return b
+}
+
+// ClearStateProgram sets the compiled clear state program, for creation and update
+func (b *ApplicationCallBuilder) ClearStateProgram(program []byte) *ApplicationCallBuilder {
+	b.fields.ClearStateProgram = program
+	return b
+}
+
+// GlobalSchema sets the immutable global state schema, for creation
+func (b *ApplicationCallBuilder) GlobalSchema(schema types.StateSchema) *ApplicationCallBuilder {
+	b.fields.GlobalStateSchema = schema
+	return b
+}
+
+// LocalSchema sets the immutable local state schema, for creation
+func (b *ApplicationCallBuilder) LocalSchema(schema types.StateSchema) *ApplicationCallBuilder {
+	b.fields.LocalStateSchema = schema
+	return b
+}
+
+// Build validates and returns the application call transaction
+func (b *ApplicationCallBuilder) Build() (types.Transaction, error) {
+	tx := types.Transaction{Type: types.ApplicationCallTx}
+	tx.Header = b.header()
+
+	creating := b.fields.ApplicationID == 0
+	hasPrograms := b.fields.ApprovalProgram != nil || b.fields.ClearStateProgram != nil
+	hasSchemas := b.fields.GlobalStateSchema != (types.StateSchema{}) || b.fields.LocalStateSchema != (types.StateSchema{})
+	switch {
+	case creating:
+		if b.fields.ApprovalProgram == nil || b.fields.ClearStateProgram == nil {
+			b.fail("application creation requires approval and clear state programs")
+		}
+		if b.fields.OnCompletion != types.NoOpOC && b.fields.OnCompletion != types.OptInOC {
+			b.fail("application creation must be a no-op or an opt-in")
+		}
+	case b.fields.OnCompletion == types.UpdateApplicationOC:
+		if b.fields.ApprovalProgram == nil || b.fields.ClearStateProgram == nil {
+			b.fail("application update requires approval and clear state programs")
+		}
+		if hasSchemas {
+			b.fail("application schemas are immutable")
+		}
+	default:
+		if hasPrograms {
+			b.fail("programs can only be set when creating or updating an application")
+		}
+		if hasSchemas {
+			b.fail("schemas can only be set when creating an application")
+		}
+	}
+	proto := b.protocol()
+	if len(b.fields.ApplicationArgs) > proto.MaxAppArgs {
+		b.fail("too many application args: %d > %d", len(b.fields.ApplicationArgs), proto.MaxAppArgs)
+	}
+	if len(b.fields.Accounts) > proto.MaxAppTxnAccounts {
+		b.fail("too many accounts: %d > %d", len(b.fields.Accounts), proto.MaxAppTxnAccounts)
+	}
+	if len(b.fields.ForeignApps) > proto.MaxAppTxnForeignApps {
+		b.fail("too many foreign apps: %d > %d", len(b.fields.ForeignApps), proto.MaxAppTxnForeignApps)
+	}
+	if len(b.fields.ForeignAssets) > proto.MaxAppTxnForeignAssets {
+		b.fail("too many foreign assets: %d > %d", len(b.fields.ForeignAssets), proto.MaxAppTxnForeignAssets)
+	}
+	tx.ApplicationCallTxnFields = b.fields
+	return b.finish(&tx)
+}
@@ -0,0 +1,282 @@
+package builder
+
+import (
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// AssetCreateBuilder builds asset creation transactions
+type AssetCreateBuilder struct {
+	base
+	asset types.AssetParams
+}
+
+// NewAssetCreate starts building an asset creation transaction
+func NewAssetCreate() *AssetCreateBuilder {
+	return &AssetCreateBuilder{}
+}
+
+// Total sets the total number of base units of the asset
+func (b *AssetCreateBuilder) Total(total uint64) *AssetCreateBuilder {
+	b.asset.Total = total
+	return b
+}
+
+// Decimals sets the number of digits to display after the decimal point
+func (b *AssetCreateBuilder) Decimals(decimals uint32) *AssetCreateBuilder {
+	b.asset.Decimals = decimals
+	return b
+}
+
+// DefaultFrozen sets whether holdings of the asset are frozen by default
+func (b *AssetCreateBuilder) DefaultFrozen(frozen bool) *AssetCreateBuilder {
+	b.asset.DefaultFrozen = frozen
+	return b
+}
+
+// UnitName sets the name of a unit of the asset
+func (b *AssetCreateBuilder) UnitName(unitName string) *AssetCreateBuilder {
+	b.asset.UnitName = unitName
+	return b
+}
+
+// AssetName sets the name of the asset
+func (b *AssetCreateBuilder) AssetName(assetName string) *AssetCreateBuilder {
+	b.asset.AssetName = assetName
+	return b
+}
+
+// URL sets where more information about the asset can be retrieved
+func (b *AssetCreateBuilder) URL(url string) *AssetCreateBuilder {
+	b.asset.URL = url
+	return b
+}
+
+// MetadataHash sets the commitment to the asset metadata
+func (b *AssetCreateBuilder) MetadataHash(hash [types.AssetMetadataHashLen]byte) *AssetCreateBuilder {
+	b.asset.MetadataHash = hash
+	return b
+}
+
+// Manager sets the account allowed to reconfigure and destroy the asset
+func (b *AssetCreateBuilder) Manager(manager types.Address) *AssetCreateBuilder {
+	b.asset.Manager = manager
+	return b
+}
+
+// Reserve sets the account holding the non-minted units of the asset
+func (b *AssetCreateBuilder) Reserve(reserve types.Address) *AssetCreateBuilder {
+	b.asset.Reserve = reserve
+	return b
+}
+
+// Freeze sets the account allowed to freeze holdings of the asset
+func (b *AssetCreateBuilder) Freeze(freeze types.Address) *AssetCreateBuilder {
+	b.asset.Freeze = freeze
+	return b
+}
+
+// Clawback sets the account allowed to revoke holdings of the asset
+func (b *AssetCreateBuilder) Clawback(clawback types.Address) *AssetCreateBuilder {
+	b.asset.Clawback = clawback
+	return b
+}
+
+// Build validates and returns the asset creation transaction
+func (b *AssetCreateBuilder) Build() (types.Transaction, error) {
+	tx := types.Transaction{Type: types.AssetConfigTx}
+	tx.Header = b.header()
+	proto := b.protocol()
+	if b.asset.Decimals > proto.MaxAssetDecimals {
+		b.fail("number of decimals %d is more than maximum %d", b.asset.Decimals, proto.MaxAssetDecimals)
+	}
+	if len(b.asset.AssetName) > proto.MaxAssetNameBytes {
+		b.fail("asset name too long: %d > %d", len(b.asset.AssetName), proto.MaxAssetNameBytes)
+	}
+	if len(b.asset.UnitName) > proto.MaxAssetUnitNameBytes {
+		b.fail("asset unit name too long: %d > %d", len(b.asset.UnitName), proto.MaxAssetUnitNameBytes)
+	}
+	if len(b.asset.URL) > proto.MaxAssetURLBytes {
+		b.fail("asset url too long: %d > %d", len(b.asset.URL), proto.MaxAssetURLBytes)
+	}
+	tx.AssetParams = b.asset
+	return b.finish(&tx)
+}
+
+// AssetConfigBuilder builds asset reconfiguration and destruction transactions
+type AssetConfigBuilder struct {
+	base
+	index   types.AssetIndex
+	asset   types.AssetParams
+	destroy bool
+	strict  bool
+}
+
+// NewAssetConfig starts building a transaction reconfiguring the roles of an asset.
+// Every role not set again is cleared, and a cleared role can never be set again.
+func NewAssetConfig(index uint64) *AssetConfigBuilder {
+	return &AssetConfigBuilder{index: types.AssetIndex(index)}
+}
+
+// NewAssetDestroy starts building a transaction destroying an asset
+func NewAssetDestroy(index uint64) *AssetConfigBuilder {
+	return &AssetConfigBuilder{index: types.AssetIndex(index), destroy: true}
+}
+
+// Manager sets the new manager of the asset
+func (b *AssetConfigBuilder) Manager(manager types.Address) *AssetConfigBuilder {
+	b.asset.Manager = manager
+	return b
+}
+
+// Reserve sets the new reserve of the asset
+func (b *AssetConfigBuilder) Reserve(reserve types.Address) *AssetConfigBuilder {
+	b.asset.Reserve = reserve
+	return b
+}
+
+// Freeze sets the new freeze account of the asset
+func (b *AssetConfigBuilder) Freeze(freeze types.Address) *AssetConfigBuilder {
+	b.asset.Freeze = freeze
+	return b
+}
+
+// Clawback sets the new clawback account of the asset
+func (b *AssetConfigBuilder) Clawback(clawback types.Address) *AssetConfigBuilder {
+	b.asset.Clawback = clawback
+	return b
+}
+
+// StrictEmptyAddressChecking makes Build fail if any role would be cleared
+func (b *AssetConfigBuilder) StrictEmptyAddressChecking(strict bool) *AssetConfigBuilder {
+	b.strict = strict
+	return b
+}
+
+// Build validates and returns the asset configuration transaction
+func (b *AssetConfigBuilder) Build() (types.Transaction, error) {
+	tx := types.Transaction{Type: types.AssetConfigTx}
+	tx.Header = b.header()
+	if b.index == 0 {
+		b.fail("asset index is not set")
+	}
+	if b.destroy && !b.asset.IsZero() {
+		b.fail("asset destruction cannot set roles")
+	}
+	if !b.destroy && b.asset.IsZero() {
+		b.fail("asset configuration clears every role, use NewAssetDestroy to destroy the asset")
+	}
+	if b.strict && (b.asset.Manager.IsZero() || b.asset.Reserve.IsZero() || b.asset.Freeze.IsZero() || b.asset.Clawback.IsZero()) {
+		b.fail("strict empty address checking requested but one or more roles would be cleared")
+	}
+	tx.ConfigAsset = b.index
+	tx.AssetParams = b.asset
+	return b.finish(&tx)
+}
+
+// AssetTransferBuilder builds asset transfer, opt-in and revocation transactions
+type AssetTransferBuilder struct {
+	base
+	index    types.AssetIndex
+	receiver types.Address
+	amount   uint64
+	closeTo  types.Address
+	revoked  types.Address
+	optIn    bool
+}
+
+// NewAssetTransfer starts building an asset transfer transaction
+func NewAssetTransfer(index uint64) *AssetTransferBuilder {
+	return &AssetTransferBuilder{index: types.AssetIndex(index)}
+}
+
+// NewAssetOptIn starts building a transaction that lets the sender accept the asset
+func NewAssetOptIn(index uint64) *AssetTransferBuilder {
+	return &AssetTransferBuilder{index: types.AssetIndex(index), optIn: true}
+}
+
+// NewAssetRevocation starts building a transaction in which the clawback
+// account (the sender) takes units of the asset from target
+func NewAssetRevocation(index uint64, target types.Address) *AssetTransferBuilder {
+	return &AssetTransferBuilder{index: types.AssetIndex(index), revoked: target}
+}
+
+// To sets the receiver of the asset units
+func (b *AssetTransferBuilder) To(receiver types.Address) *AssetTransferBuilder {
+	b.receiver = receiver
+	return b
+}
+
+// Amount sets the number of base units to transfer
+func (b *AssetTransferBuilder) Amount(amount uint64) *AssetTransferBuilder {
+	b.amount = amount
+	return b
+}
+
+// CloseTo removes the asset from the sender account, sending the remaining units to closeTo
+func (b *AssetTransferBuilder) CloseTo(closeTo types.Address) *AssetTransferBuilder {
+	b.closeTo = closeTo
+	return b
+}
+
+// Build validates and returns the asset transfer transaction
+func (b *AssetTransferBuilder) Build() (types.Transaction, error) {
+	tx := types.Transaction{Type: types.AssetTransferTx}
+	tx.Header = b.header()
+	if b.index == 0 {
+		b.fail("asset index is not set")
+	}
+	receiver := b.receiver
+	if b.optIn {
+		if !b.receiver.IsZero() || b.amount != 0 || !b.closeTo.IsZero() {
+			b.fail("asset opt-in cannot set a receiver, amount or close-to")
+		}
+		receiver = b.sender
+	}
+	if receiver.IsZero() {
+		b.fail("receiver is not set")
+	}
+	if !b.revoked.IsZero() && !b.closeTo.IsZero() {
+		b.fail("asset revocation cannot close out")
+	}
+	tx.XferAsset = b.index
+	tx.AssetReceiver = receiver
+	tx.AssetAmount = b.amount
+	tx.AssetCloseTo = b.closeTo
+	tx.AssetSender = b.revoked
+	return b.finish(&tx)
+}
+
+// AssetFreezeBuilder builds asset freeze transactions
+type AssetFreezeBuilder struct {
+	base
+	index  types.AssetIndex
+	target types.Address
+	frozen bool
+}
+
+// NewAssetFreeze starts building a transaction setting the frozen state of target's holding
+func NewAssetFreeze(index uint64, target types.Address) *AssetFreezeBuilder {
+	return &AssetFreezeBuilder{index: types.AssetIndex(index), target: target}
+}
+
+// Frozen sets the new frozen state of the holding
+func (b *AssetFreezeBuilder) Frozen(frozen bool) *AssetFreezeBuilder {
+	b.frozen = frozen
+	return b
+}
+
+// Build validates and returns the asset freeze transaction
+func (b *AssetFreezeBuilder) Build() (types.Transaction, error) {
+	tx := types.Transaction{Type: types.AssetFreezeTx}
+	tx.Header = b.header()
+	if b.index == 0 {
+		b.fail("asset index is not set")
+	}
+	if b.target.IsZero() {
+		b.fail("freeze target is not set")
+	}
+	tx.FreezeAsset = b.index
+	tx.FreezeAccount = b.target
+	tx.AssetFrozen = b.frozen
+	return b.finish(&tx)
+}
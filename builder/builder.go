@@ -0,0 +1,121 @@
+// Package builder provides typed, fluent constructors for every transaction
+// type, as an alternative to the positional Make*Txn functions of the
+// transaction and future packages. For example:
+//
+//	tx, err := builder.NewPayment().
+//		From(sender).
+//		To(receiver).
+//		Amount(1000000).
+//		Params(sp).
+//		Build()
+//
+// Setters never fail; Build validates the whole transaction and reports
+// every problem it found.
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/transaction"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// ValidationError lists every problem Build found with a transaction
+type ValidationError struct {
+	TxType   types.TxType
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s transaction: %s", e.TxType, strings.Join(e.Problems, "; "))
+}
+
+//go:generate go run gen_header.go
+
+// base is the header builder shared by every builder, which embeds it. The
+// setters of its fields are generated for each builder in header.go, so that
+// they return the builder and calls can be chained.
+type base struct {
+	sender  types.Address
+	params  *types.SuggestedParams
+	note    []byte
+	lease   [32]byte
+	rekeyTo types.Address
+
+	problems []string
+}
+
+func (b *base) fail(format string, args ...interface{}) {
+	b.problems = append(b.problems, fmt.Sprintf(format, args...))
+}
+
+// protocol returns the parameters of the protocol version of the suggested
+// params. header reports an unknown version, for which the Latest limits are
+// checked so that every other problem is reported along with it.
+func (b *base) protocol() consensus.Params {
+	if b.params == nil {
+		return consensus.LookupOrLatest(consensus.Latest)
+	}
+	return consensus.LookupOrLatest(b.params.ConsensusVersion)
+}
+
+// header validates and returns the common transaction header
+func (b *base) header() types.Header {
+	var h types.Header
+	if b.sender.IsZero() {
+		b.fail("sender is not set")
+	}
+	if b.params == nil {
+		b.fail("suggested params are not set")
+		return h
+	}
+	if len(b.params.GenesisHash) == 0 {
+		b.fail("genesis hash is not set")
+	}
+	if _, err := consensus.FromSuggestedParams(*b.params); err != nil {
+		b.fail("%v", err)
+	}
+	proto := b.protocol()
+	if len(b.note) > proto.MaxTxnNoteBytes {
+		b.fail("note too long: %d > %d", len(b.note), proto.MaxTxnNoteBytes)
+	}
+	if b.params.FirstRoundValid > b.params.LastRoundValid {
+		b.fail("first valid round %d is after last valid round %d", b.params.FirstRoundValid, b.params.LastRoundValid)
+	} else if uint64(b.params.LastRoundValid-b.params.FirstRoundValid) > proto.MaxTxnLife {
+		b.fail("validity window too long: %d > %d rounds", b.params.LastRoundValid-b.params.FirstRoundValid, proto.MaxTxnLife)
+	}
+
+	h.Sender = b.sender
+	h.Fee = b.params.Fee
+	h.FirstValid = b.params.FirstRoundValid
+	h.LastValid = b.params.LastRoundValid
+	h.Note = b.note
+	h.GenesisID = b.params.GenesisID
+	copy(h.GenesisHash[:], b.params.GenesisHash)
+	h.Lease = b.lease
+	h.RekeyTo = b.rekeyTo
+	return h
+}
+
+// finish sets the fee of tx and returns the accumulated validation problems, if any
+func (b *base) finish(tx *types.Transaction) (types.Transaction, error) {
+	if len(b.problems) != 0 {
+		err := &ValidationError{TxType: tx.Type, Problems: b.problems}
+		b.problems = nil
+		return types.Transaction{}, err
+	}
+
+	if !b.params.FlatFee {
+		eSize, err := transaction.EstimateSize(*tx)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+		tx.Fee = types.MicroAlgos(eSize * uint64(b.params.Fee))
+	}
+	if minFee := types.MicroAlgos(b.protocol().MinTxnFee); tx.Fee < minFee {
+		tx.Fee = minFee
+	}
+	return *tx, nil
+}
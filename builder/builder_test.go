@@ -0,0 +1,169 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var testParams = types.SuggestedParams{
+	Fee:             4,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     []byte("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	FirstRoundValid: 12466,
+	LastRoundValid:  13466,
+}
+
+const (
+	testSender   = "7ZUECA7HFLZTXENRV24SHLU4AVPUTMTTDUFUBNBD64C73F3UHRTHAIOF6Q"
+	testReceiver = "PNWOET7LLOWMBMLE4KOCELCX6X3D3Q4H2Q4QJASYIEOF7YIPPQBG3YQ5YI"
+)
+
+func decode(t *testing.T, addr string) types.Address {
+	a, err := types.DecodeAddress(addr)
+	require.NoError(t, err)
+	return a
+}
+
+func TestPaymentMatchesFuture(t *testing.T) {
+	lease := [32]byte{1, 2, 3}
+	flat := testParams
+	flat.FlatFee, flat.Fee = true, 2500
+	for _, sp := range []types.SuggestedParams{testParams, flat} {
+		tx, err := NewPayment().
+			From(decode(t, testSender)).
+			To(decode(t, testReceiver)).
+			Amount(1000).
+			Note([]byte("note")).
+			Params(sp).
+			Lease(lease).
+			Build()
+		require.NoError(t, err)
+
+		expected, err := future.MakePaymentTxn(testSender, testReceiver, 1000, []byte("note"), "", sp)
+		require.NoError(t, err)
+		if sp.FlatFee {
+			expected.AddLeaseWithFlatFee(lease, uint64(sp.Fee))
+		} else {
+			expected.AddLease(lease, uint64(sp.Fee))
+		}
+		require.Equal(t, expected, tx)
+	}
+}
+
+func TestAssetCreateMatchesFuture(t *testing.T) {
+	tx, err := NewAssetCreate().
+		From(decode(t, testSender)).
+		Total(100).
+		Decimals(2).
+		UnitName("tst").
+		AssetName("testcoin").
+		Manager(decode(t, testSender)).
+		Clawback(decode(t, testReceiver)).
+		Params(testParams).
+		Build()
+	require.NoError(t, err)
+
+	expected, err := future.MakeAssetCreateTxn(testSender, nil, testParams, 100, 2, false, testSender, "", "", testReceiver, "tst", "testcoin", "", "")
+	require.NoError(t, err)
+	require.Equal(t, expected, tx)
+}
+
+func TestKeyRegNonparticipation(t *testing.T) {
+	tx, err := NewKeyRegOffline().
+		From(decode(t, testSender)).
+		Nonparticipation(true).
+		Params(testParams).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, types.KeyRegistrationTx, tx.Type)
+	require.True(t, tx.Nonparticipation)
+
+	_, err = NewKeyReg().
+		From(decode(t, testSender)).
+		Nonparticipation(true).
+		VoteKeyDilution(10000).
+		Params(testParams).
+		Build()
+	require.Error(t, err)
+}
+
+func TestApplicationCall(t *testing.T) {
+	sp := testParams
+	sp.FlatFee = true
+	sp.Fee = 2000
+	tx, err := NewApplicationCreate().
+		From(decode(t, testSender)).
+		ApprovalProgram([]byte{0x02, 0x20, 0x01, 0x01, 0x22}).
+		ClearStateProgram([]byte{0x02, 0x20, 0x01, 0x01, 0x22}).
+		GlobalSchema(types.StateSchema{NumUint: 1}).
+		OnCompletion(types.OptInOC).
+		Args([]byte("init")).
+		Accounts(decode(t, testReceiver)).
+		ForeignAssets(7).
+		Params(sp).
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(2000), tx.Fee)
+	require.Equal(t, types.OptInOC, tx.OnCompletion)
+	require.Equal(t, []types.AssetIndex{7}, tx.ForeignAssets)
+
+	// a flat fee below the minimum of the protocol is raised to it
+	sp.Fee = 10
+	tx, err = NewApplicationNoOp(5).From(decode(t, testSender)).Params(sp).Build()
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(1000), tx.Fee)
+
+	_, err = NewApplicationNoOp(5).
+		From(decode(t, testSender)).
+		GlobalSchema(types.StateSchema{NumUint: 1}).
+		Params(testParams).
+		Build()
+	require.Error(t, err)
+}
+
+func TestValidationReportsAllProblems(t *testing.T) {
+	_, err := NewAssetFreeze(0, types.Address{}).Build()
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, types.AssetFreezeTx, verr.TxType)
+	require.Len(t, verr.Problems, 4)
+}
+
+func TestLimitsFollowConsensusVersion(t *testing.T) {
+	url := "https://example.com/a/rather/long/asset/url"
+	sp := testParams
+	sp.ConsensusVersion = consensus.V24
+	_, err := NewAssetCreate().From(decode(t, testSender)).Total(1).URL(url).Params(sp).Build()
+	require.Error(t, err)
+
+	sp.ConsensusVersion = consensus.V28
+	_, err = NewAssetCreate().From(decode(t, testSender)).Total(1).URL(url).Params(sp).Build()
+	require.NoError(t, err)
+
+	// an unknown version fails rather than guessing the minimum fee
+	sp.ConsensusVersion = "https://example.com/unknown"
+	_, err = NewPayment().From(decode(t, testSender)).To(decode(t, testReceiver)).Params(sp).Build()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown consensus version")
+}
+
+func TestBuildTwice(t *testing.T) {
+	b := NewAssetOptIn(31566704).From(decode(t, testSender)).Params(testParams)
+	first, err := b.Build()
+	require.NoError(t, err)
+	require.Equal(t, decode(t, testSender), first.AssetReceiver)
+
+	second, err := b.Build()
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	expected, err := future.MakeAssetAcceptanceTxn(testSender, nil, testParams, 31566704)
+	require.NoError(t, err)
+	require.Equal(t, expected, first)
+}
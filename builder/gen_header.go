@@ -0,0 +1,79 @@
+//go:build ignore
+// +build ignore
+
+// gen_header generates header.go: the setters of the transaction header, which
+// every builder exposes returning its own type so that calls can be chained.
+// Run it with go generate after adding a builder.
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"text/template"
+)
+
+// builders are the builder types embedding base
+var builders = []string{
+	"PaymentBuilder",
+	"KeyRegBuilder",
+	"AssetCreateBuilder",
+	"AssetConfigBuilder",
+	"AssetTransferBuilder",
+	"AssetFreezeBuilder",
+	"ApplicationCallBuilder",
+}
+
+var headerTemplate = template.Must(template.New("header").Parse(`// Code generated by gen_header.go. DO NOT EDIT.
+
+package builder
+
+import (
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+{{range .}}
+// From sets the sender of the transaction
+func (b *{{.}}) From(sender types.Address) *{{.}} {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *{{.}}) Params(params types.SuggestedParams) *{{.}} {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *{{.}}) Note(note []byte) *{{.}} {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *{{.}}) Lease(lease [32]byte) *{{.}} {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *{{.}}) RekeyTo(rekeyTo types.Address) *{{.}} {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
+{{end}}`))
+
+func main() {
+	var buf bytes.Buffer
+	if err := headerTemplate.Execute(&buf, builders); err != nil {
+		log.Fatal(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("header.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,217 @@
+// Code generated by gen_header.go. DO NOT EDIT.
+
+package builder
+
+import (
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// From sets the sender of the transaction
+func (b *PaymentBuilder) From(sender types.Address) *PaymentBuilder {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *PaymentBuilder) Params(params types.SuggestedParams) *PaymentBuilder {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *PaymentBuilder) Note(note []byte) *PaymentBuilder {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *PaymentBuilder) Lease(lease [32]byte) *PaymentBuilder {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *PaymentBuilder) RekeyTo(rekeyTo types.Address) *PaymentBuilder {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
+
+// From sets the sender of the transaction
+func (b *KeyRegBuilder) From(sender types.Address) *KeyRegBuilder {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *KeyRegBuilder) Params(params types.SuggestedParams) *KeyRegBuilder {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *KeyRegBuilder) Note(note []byte) *KeyRegBuilder {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *KeyRegBuilder) Lease(lease [32]byte) *KeyRegBuilder {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *KeyRegBuilder) RekeyTo(rekeyTo types.Address) *KeyRegBuilder {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
+
+// From sets the sender of the transaction
+func (b *AssetCreateBuilder) From(sender types.Address) *AssetCreateBuilder {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *AssetCreateBuilder) Params(params types.SuggestedParams) *AssetCreateBuilder {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *AssetCreateBuilder) Note(note []byte) *AssetCreateBuilder {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *AssetCreateBuilder) Lease(lease [32]byte) *AssetCreateBuilder {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *AssetCreateBuilder) RekeyTo(rekeyTo types.Address) *AssetCreateBuilder {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
+
+// From sets the sender of the transaction
+func (b *AssetConfigBuilder) From(sender types.Address) *AssetConfigBuilder {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *AssetConfigBuilder) Params(params types.SuggestedParams) *AssetConfigBuilder {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *AssetConfigBuilder) Note(note []byte) *AssetConfigBuilder {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *AssetConfigBuilder) Lease(lease [32]byte) *AssetConfigBuilder {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *AssetConfigBuilder) RekeyTo(rekeyTo types.Address) *AssetConfigBuilder {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
+
+// From sets the sender of the transaction
+func (b *AssetTransferBuilder) From(sender types.Address) *AssetTransferBuilder {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *AssetTransferBuilder) Params(params types.SuggestedParams) *AssetTransferBuilder {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *AssetTransferBuilder) Note(note []byte) *AssetTransferBuilder {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *AssetTransferBuilder) Lease(lease [32]byte) *AssetTransferBuilder {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *AssetTransferBuilder) RekeyTo(rekeyTo types.Address) *AssetTransferBuilder {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
+
+// From sets the sender of the transaction
+func (b *AssetFreezeBuilder) From(sender types.Address) *AssetFreezeBuilder {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *AssetFreezeBuilder) Params(params types.SuggestedParams) *AssetFreezeBuilder {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *AssetFreezeBuilder) Note(note []byte) *AssetFreezeBuilder {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *AssetFreezeBuilder) Lease(lease [32]byte) *AssetFreezeBuilder {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *AssetFreezeBuilder) RekeyTo(rekeyTo types.Address) *AssetFreezeBuilder {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
+
+// From sets the sender of the transaction
+func (b *ApplicationCallBuilder) From(sender types.Address) *ApplicationCallBuilder {
+	b.base.sender = sender
+	return b
+}
+
+// Params sets the fee and validity window, typically from algod SuggestedParams
+func (b *ApplicationCallBuilder) Params(params types.SuggestedParams) *ApplicationCallBuilder {
+	b.base.params = &params
+	return b
+}
+
+// Note sets the note of the transaction
+func (b *ApplicationCallBuilder) Note(note []byte) *ApplicationCallBuilder {
+	b.base.note = note
+	return b
+}
+
+// Lease sets the lease of the transaction, see types.Header
+func (b *ApplicationCallBuilder) Lease(lease [32]byte) *ApplicationCallBuilder {
+	b.base.lease = lease
+	return b
+}
+
+// RekeyTo sets the address the sender account is rekeyed to
+func (b *ApplicationCallBuilder) RekeyTo(rekeyTo types.Address) *ApplicationCallBuilder {
+	b.base.rekeyTo = rekeyTo
+	return b
+}
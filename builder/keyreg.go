@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// KeyRegBuilder builds key registration transactions
+type KeyRegBuilder struct {
+	base
+	fields  types.KeyregTxnFields
+	offline bool
+}
+
+// NewKeyReg starts building a key registration transaction bringing the sender online
+func NewKeyReg() *KeyRegBuilder {
+	return &KeyRegBuilder{}
+}
+
+// NewKeyRegOffline starts building a key registration transaction taking the sender offline
+func NewKeyRegOffline() *KeyRegBuilder {
+	return &KeyRegBuilder{offline: true}
+}
+
+// VoteKey sets the root participation public key
+func (b *KeyRegBuilder) VoteKey(votePK types.VotePK) *KeyRegBuilder {
+	b.fields.VotePK = votePK
+	return b
+}
+
+// SelectionKey sets the VRF public key
+func (b *KeyRegBuilder) SelectionKey(selectionPK types.VRFPK) *KeyRegBuilder {
+	b.fields.SelectionPK = selectionPK
+	return b
+}
+
+// VoteRounds sets the first and last rounds the participation key is valid
+func (b *KeyRegBuilder) VoteRounds(first, last types.Round) *KeyRegBuilder {
+	b.fields.VoteFirst = first
+	b.fields.VoteLast = last
+	return b
+}
+
+// VoteKeyDilution sets the dilution of the 2-level participation key
+func (b *KeyRegBuilder) VoteKeyDilution(dilution uint64) *KeyRegBuilder {
+	b.fields.VoteKeyDilution = dilution
+	return b
+}
+
+// Nonparticipation marks the sender as permanently not participating in consensus.
+// The sender goes offline and stops earning rewards; this cannot be undone.
+func (b *KeyRegBuilder) Nonparticipation(nonparticipation bool) *KeyRegBuilder {
+	b.fields.Nonparticipation = nonparticipation
+	return b
+}
+
+// Build validates and returns the key registration transaction
+func (b *KeyRegBuilder) Build() (types.Transaction, error) {
+	tx := types.Transaction{Type: types.KeyRegistrationTx}
+	tx.Header = b.header()
+
+	online := b.fields.VotePK != (types.VotePK{}) || b.fields.SelectionPK != (types.VRFPK{})
+	switch {
+	case b.offline || b.fields.Nonparticipation:
+		if online || b.fields.VoteFirst != 0 || b.fields.VoteLast != 0 || b.fields.VoteKeyDilution != 0 {
+			b.fail("offline key registration cannot set participation keys")
+		}
+	default:
+		if b.fields.VotePK == (types.VotePK{}) {
+			b.fail("vote key is not set")
+		}
+		if b.fields.SelectionPK == (types.VRFPK{}) {
+			b.fail("selection key is not set")
+		}
+		if b.fields.VoteFirst > b.fields.VoteLast {
+			b.fail("vote first round %d is after vote last round %d", b.fields.VoteFirst, b.fields.VoteLast)
+		}
+		if b.fields.VoteKeyDilution == 0 {
+			b.fail("vote key dilution is not set")
+		}
+	}
+	tx.KeyregTxnFields = b.fields
+	return b.finish(&tx)
+}
@@ -0,0 +1,49 @@
+package builder
+
+import (
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// PaymentBuilder builds payment transactions
+type PaymentBuilder struct {
+	base
+	receiver         types.Address
+	amount           uint64
+	closeRemainderTo types.Address
+}
+
+// NewPayment starts building a payment transaction
+func NewPayment() *PaymentBuilder {
+	return &PaymentBuilder{}
+}
+
+// To sets the receiver of the payment
+func (b *PaymentBuilder) To(receiver types.Address) *PaymentBuilder {
+	b.receiver = receiver
+	return b
+}
+
+// Amount sets the amount to pay, in microAlgos
+func (b *PaymentBuilder) Amount(amount uint64) *PaymentBuilder {
+	b.amount = amount
+	return b
+}
+
+// CloseRemainderTo closes the sender account, sending all remaining funds to closeTo
+func (b *PaymentBuilder) CloseRemainderTo(closeTo types.Address) *PaymentBuilder {
+	b.closeRemainderTo = closeTo
+	return b
+}
+
+// Build validates and returns the payment transaction
+func (b *PaymentBuilder) Build() (types.Transaction, error) {
+	tx := types.Transaction{Type: types.PaymentTx}
+	tx.Header = b.header()
+	if b.receiver.IsZero() && b.closeRemainderTo.IsZero() {
+		b.fail("receiver is not set")
+	}
+	tx.Receiver = b.receiver
+	tx.Amount = types.MicroAlgos(b.amount)
+	tx.CloseRemainderTo = b.closeRemainderTo
+	return b.finish(&tx)
+}
@@ -29,6 +29,13 @@ func (c *Client) TealCompile(source []byte) *TealCompile {
 // Executes TEAL program(s) in context and returns debugging information about the
 // execution.
 func (c *Client) TealDryrun(request models.DryrunRequest) *TealDryrun {
-	return &TealDryrun{c: c, request: request}
+	return &TealDryrun{c: c, rawRequest: EncodeDryrunRequest(request)}
+}
+
+// /v2/teal/dryrun
+// Same as TealDryrun, for a request that is already msgpack-encoded, such as
+// one dumped by goal clerk dryrun --dryrun-dump.
+func (c *Client) TealDryrunRaw(rawRequest []byte) *TealDryrun {
+	return &TealDryrun{c: c, rawRequest: rawRequest}
 }
 
@@ -11,15 +11,30 @@ import (
 // TealDryrun /v2/teal/dryrun
 // Executes TEAL program(s) in context and returns debugging information about the
 // execution.
+// The request is always sent msgpack-encoded: the JSON encoding of the
+// transactions of models.DryrunRequest is not the one algod expects.
 type TealDryrun struct {
-	c       *Client
-	request models.DryrunRequest
+	c          *Client
+	rawRequest []byte
+}
+
+// EncodeDryrunRequest returns the msgpack encoding of request, as sent by
+// TealDryrun and written by goal clerk dryrun --dryrun-dump
+func EncodeDryrunRequest(request models.DryrunRequest) []byte {
+	return msgpack.Encode(&request)
+}
+
+// DecodeDryrunRequest decodes a msgpack-encoded dryrun request
+func DecodeDryrunRequest(rawRequest []byte) (request models.DryrunRequest, err error) {
+	err = msgpack.Decode(rawRequest, &request)
+	return
 }
 
 // Do performs HTTP request
 func (s *TealDryrun) Do(ctx context.Context,
 	headers ...*common.Header) (response models.DryrunResponse, err error) {
+	headers = append([]*common.Header{{Key: "Content-Type", Value: "application/msgpack"}}, headers...)
 	err = s.c.post(ctx, &response,
-		"/v2/teal/dryrun", msgpack.Encode(&s.request), headers)
+		"/v2/teal/dryrun", s.rawRequest, headers)
 	return
 }
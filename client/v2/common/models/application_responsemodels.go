@@ -93,6 +93,10 @@ type ApplicationParams struct {
 	// parameters and global state for this application can be found.
 	Creator string `json:"creator,omitempty"`
 
+	// ExtraProgramPages (epp) the amount of extra program pages available to this
+	// app.
+	ExtraProgramPages uint64 `json:"extra-program-pages,omitempty"`
+
 	// GlobalState [\gs) global schema
 	GlobalState []TealKeyValue `json:"global-state,omitempty"`
 
@@ -25,6 +25,10 @@ type Account struct {
 	// Note: the raw account uses `StateSchema` for this type.
 	AppsTotalSchema ApplicationStateSchema `json:"apps-total-schema,omitempty"`
 
+	// AppsTotalExtraPages (teap) the sum of all extra application program pages for
+	// this account.
+	AppsTotalExtraPages uint64 `json:"apps-total-extra-pages,omitempty"`
+
 	// Assets (asset) assets held by this account.
 	// Note the raw object uses `map[int] -> AssetHolding` for this type.
 	Assets []AssetHolding `json:"assets,omitempty"`
@@ -0,0 +1,177 @@
+package witness
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// DefaultTimeout bounds a share collection when Collector.Timeout is not set
+const DefaultTimeout = 10 * time.Second
+
+// Collector requests signature shares from a set of witnesses and combines
+// them into a GroupEnvelop once the threshold of the group public key is met.
+// Witnesses that fail, time out or return invalid shares are skipped.
+type Collector struct {
+	scheme     crypto.GroupSignatureScheme
+	schemeName string
+	publicKey  []byte
+	threshold  int
+	witnesses  []Witness
+
+	// Timeout bounds the whole collection, on top of the passed context
+	Timeout time.Duration
+}
+
+// ThresholdError is returned when not enough valid shares could be collected
+type ThresholdError struct {
+	// Collected is the number of valid shares gathered
+	Collected int
+	// Threshold is the number of shares required
+	Threshold int
+	// Failures maps the index of each misbehaving or unreachable witness to its error
+	Failures map[int]error
+}
+
+func (e *ThresholdError) Error() string {
+	indexes := make([]int, 0, len(e.Failures))
+	for i := range e.Failures {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	var failures []string
+	for _, i := range indexes {
+		failures = append(failures, fmt.Sprintf("witness %d: %v", i, e.Failures[i]))
+	}
+	return fmt.Sprintf("collected %d of %d required group signature shares [%s]",
+		e.Collected, e.Threshold, strings.Join(failures, "; "))
+}
+
+// MakeCollector builds a Collector for the passed scheme and group public key
+func MakeCollector(scheme string, publicKey []byte, witnesses []Witness) (*Collector, error) {
+	s, err := crypto.GetGroupSignatureScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := s.Threshold(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if threshold > len(witnesses) {
+		return nil, fmt.Errorf("threshold %d cannot be met by %d witnesses", threshold, len(witnesses))
+	}
+	c := &Collector{
+		scheme:     s,
+		schemeName: scheme,
+		publicKey:  publicKey,
+		threshold:  threshold,
+		witnesses:  witnesses,
+		Timeout:    DefaultTimeout,
+	}
+	return c, nil
+}
+
+type shareResult struct {
+	index int
+	share crypto.GroupSignatureShare
+	err   error
+}
+
+// Collect requests shares over txgroup from every witness in parallel, and
+// returns the combined GroupEnvelop as soon as enough valid shares arrived
+func (c *Collector) Collect(ctx context.Context, txgroup []types.Transaction) (env types.GroupEnvelop, err error) {
+	message, err := crypto.GroupEnvelopBytesToSign(txgroup)
+	if err != nil {
+		return
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	// releasing the context also stops the witnesses we no longer need
+	defer cancel()
+
+	results := make(chan shareResult, len(c.witnesses))
+	for i, w := range c.witnesses {
+		go func(i int, w Witness) {
+			share, err := w.Sign(ctx, txgroup)
+			results <- shareResult{index: i, share: share, err: err}
+		}(i, w)
+	}
+
+	var shares []crypto.GroupSignatureShare
+	failures := make(map[int]error)
+	responded := make(map[int]bool)
+	for len(responded) < len(c.witnesses) && len(shares) < c.threshold {
+		select {
+		case res := <-results:
+			responded[res.index] = true
+			if res.err == nil {
+				res.err = c.checkShare(message, res.share, shares)
+			}
+			if res.err != nil {
+				failures[res.index] = res.err
+				continue
+			}
+			shares = append(shares, res.share)
+		case <-ctx.Done():
+			for i := range c.witnesses {
+				if !responded[i] {
+					failures[i] = ctx.Err()
+				}
+			}
+			return env, &ThresholdError{Collected: len(shares), Threshold: c.threshold, Failures: failures}
+		}
+	}
+	if len(shares) < c.threshold {
+		return env, &ThresholdError{Collected: len(shares), Threshold: c.threshold, Failures: failures}
+	}
+
+	signature, err := c.scheme.Combine(c.publicKey, message, shares)
+	if err != nil {
+		return
+	}
+	env.PublicKey = c.publicKey
+	env.Signature = signature
+	env.Scheme = c.schemeName
+	return
+}
+
+// checkShare rejects shares that do not verify or that repeat a signer
+func (c *Collector) checkShare(message []byte, share crypto.GroupSignatureShare, collected []crypto.GroupSignatureShare) error {
+	for _, other := range collected {
+		if bytes.Equal(other.PublicKey, share.PublicKey) {
+			return fmt.Errorf("duplicate share for signer %x", share.PublicKey)
+		}
+	}
+	return c.scheme.VerifyShare(c.publicKey, message, share)
+}
+
+// SignGroup collects a GroupEnvelop over the transactions of stxns and attaches it to each of them
+func (c *Collector) SignGroup(ctx context.Context, stxns []types.SignedTxn) error {
+	txgroup := make([]types.Transaction, len(stxns))
+	for i := range stxns {
+		txgroup[i] = stxns[i].Txn
+	}
+	env, err := c.Collect(ctx, txgroup)
+	if err != nil {
+		return err
+	}
+	AttachGroupEnvelop(stxns, env)
+	return nil
+}
+
+// AttachGroupEnvelop sets the GroupSignature of every passed signed transaction
+func AttachGroupEnvelop(stxns []types.SignedTxn, env types.GroupEnvelop) {
+	for i := range stxns {
+		stxns[i].GroupSignature = env
+	}
+}
@@ -0,0 +1,33 @@
+package witness
+
+import (
+	"context"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// LocalWitness is an in-memory Witness holding its secret key in process.
+// It is meant as a stand-in for signer nodes in tests and local networks.
+type LocalWitness struct {
+	scheme string
+	sk     []byte
+}
+
+// MakeLocalWitness returns a LocalWitness signing with sk under the given scheme
+func MakeLocalWitness(scheme string, sk []byte) (*LocalWitness, error) {
+	if _, err := crypto.GetGroupSignatureScheme(scheme); err != nil {
+		return nil, err
+	}
+	return &LocalWitness{scheme: scheme, sk: sk}, nil
+}
+
+// Sign returns the share of this witness over txgroup
+func (w *LocalWitness) Sign(ctx context.Context, txgroup []types.Transaction) (crypto.GroupSignatureShare, error) {
+	select {
+	case <-ctx.Done():
+		return crypto.GroupSignatureShare{}, ctx.Err()
+	default:
+	}
+	return crypto.SignGroupEnvelopShare(w.scheme, w.sk, txgroup)
+}
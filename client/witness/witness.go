@@ -0,0 +1,115 @@
+package witness
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+const (
+	timeoutSecs        = 30
+	witnessTokenHeader = "X-Witness-API-Token"
+	signPath           = "v1/group/sign"
+)
+
+// Witness is a signer node that produces a share of the GroupEnvelop
+// signature for a transaction group
+type Witness interface {
+	// Sign returns the witness' share over the passed transaction group
+	Sign(ctx context.Context, txgroup []types.Transaction) (crypto.GroupSignatureShare, error)
+}
+
+// SignRequest is the body of a signature share request sent to a witness
+type SignRequest struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// Scheme is the group signature scheme the share is requested for
+	Scheme string `codec:"scheme"`
+
+	// Txns is the transaction group to sign
+	Txns []types.Transaction `codec:"txns"`
+}
+
+// SignResponse is the body returned by a witness
+type SignResponse struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// Share is the witness' signature share, if no error occurred
+	Share crypto.GroupSignatureShare `codec:"share"`
+
+	// Error is set when the witness refused to sign
+	Error string `codec:"error"`
+}
+
+// Client is the client used to request shares from a remote witness over HTTP
+type Client struct {
+	httpClient http.Client
+	apiToken   string
+	address    string
+	scheme     string
+}
+
+// MakeClient instantiates a Client for the witness at address, requesting
+// shares for the given group signature scheme
+func MakeClient(address string, apiToken string, scheme string) (*Client, error) {
+	if _, err := crypto.GetGroupSignatureScheme(scheme); err != nil {
+		return nil, err
+	}
+	c := &Client{
+		httpClient: http.Client{
+			Timeout: timeoutSecs * time.Second,
+		},
+		apiToken: apiToken,
+		address:  address,
+		scheme:   scheme,
+	}
+	return c, nil
+}
+
+// Address returns the address of the witness this client talks to
+func (c *Client) Address() string {
+	return c.address
+}
+
+// Sign posts the transaction group to the witness and returns its share
+func (c *Client) Sign(ctx context.Context, txgroup []types.Transaction) (share crypto.GroupSignatureShare, err error) {
+	body := msgpack.Encode(SignRequest{Scheme: c.scheme, Txns: txgroup})
+	fullPath := fmt.Sprintf("%s/%s", c.address, signPath)
+	hreq, err := http.NewRequest("POST", fullPath, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	hreq = hreq.WithContext(ctx)
+	hreq.Header.Add(witnessTokenHeader, c.apiToken)
+	hreq.Header.Add("Content-Type", "application/msgpack")
+
+	hresp, err := c.httpClient.Do(hreq)
+	if err != nil {
+		return
+	}
+	defer hresp.Body.Close()
+
+	if hresp.StatusCode != http.StatusOK {
+		errorBuf, _ := ioutil.ReadAll(hresp.Body) // ignore returned error
+		err = fmt.Errorf("witness %s: HTTP %v: %s", c.address, hresp.Status, errorBuf)
+		return
+	}
+
+	var resp SignResponse
+	err = msgpack.NewDecoder(hresp.Body).Decode(&resp)
+	if err != nil {
+		return
+	}
+	if resp.Error != "" {
+		err = fmt.Errorf("witness %s: %s", c.address, resp.Error)
+		return
+	}
+	return resp.Share, nil
+}
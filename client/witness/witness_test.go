@@ -0,0 +1,121 @@
+package witness
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// forgingWitness returns a share that does not verify
+type forgingWitness struct{}
+
+func (forgingWitness) Sign(ctx context.Context, txgroup []types.Transaction) (crypto.GroupSignatureShare, error) {
+	acct := crypto.GenerateAccount()
+	return crypto.GroupSignatureShare{PublicKey: acct.PublicKey, Signature: make([]byte, ed25519.SignatureSize)}, nil
+}
+
+// stalledWitness never answers before the context expires
+type stalledWitness struct{}
+
+func (stalledWitness) Sign(ctx context.Context, txgroup []types.Transaction) (crypto.GroupSignatureShare, error) {
+	<-ctx.Done()
+	return crypto.GroupSignatureShare{}, ctx.Err()
+}
+
+func makeTestSetup(t *testing.T, threshold uint8, n int) ([]Witness, []byte) {
+	var witnesses []Witness
+	var pks []ed25519.PublicKey
+	for i := 0; i < n; i++ {
+		acct := crypto.GenerateAccount()
+		w, err := MakeLocalWitness(crypto.Ed25519ThresholdScheme, acct.PrivateKey)
+		require.NoError(t, err)
+		witnesses = append(witnesses, w)
+		pks = append(pks, acct.PublicKey)
+	}
+	groupPk, err := crypto.MakeEd25519ThresholdPublicKey(threshold, pks)
+	require.NoError(t, err)
+	return witnesses, groupPk
+}
+
+func makeTestSignedGroup(t *testing.T) []types.SignedTxn {
+	sender := crypto.GenerateAccount()
+	stxns := make([]types.SignedTxn, 2)
+	for i := range stxns {
+		stxns[i].Txn = types.Transaction{
+			Type: types.PaymentTx,
+			Header: types.Header{
+				Sender:     sender.Address,
+				Fee:        1000,
+				FirstValid: 1,
+				LastValid:  1001,
+				Note:       []byte{byte(i)},
+			},
+			PaymentTxnFields: types.PaymentTxnFields{Receiver: sender.Address},
+		}
+	}
+	return stxns
+}
+
+func TestCollectorSkipsMisbehavingWitnesses(t *testing.T) {
+	witnesses, groupPk := makeTestSetup(t, 2, 2)
+	witnesses = append([]Witness{forgingWitness{}}, witnesses...)
+
+	c, err := MakeCollector(crypto.Ed25519ThresholdScheme, groupPk, witnesses)
+	require.NoError(t, err)
+
+	stxns := makeTestSignedGroup(t)
+	require.NoError(t, c.SignGroup(context.Background(), stxns))
+	require.True(t, crypto.VerifyGroupEnvelop(stxns))
+}
+
+func TestCollectorTimeout(t *testing.T) {
+	witnesses, groupPk := makeTestSetup(t, 3, 3)
+	witnesses[2] = stalledWitness{}
+
+	c, err := MakeCollector(crypto.Ed25519ThresholdScheme, groupPk, witnesses)
+	require.NoError(t, err)
+	c.Timeout = 50 * time.Millisecond
+
+	stxns := makeTestSignedGroup(t)
+	err = c.SignGroup(context.Background(), stxns)
+	var thresholdErr *ThresholdError
+	require.True(t, errors.As(err, &thresholdErr))
+	require.Equal(t, 2, thresholdErr.Collected)
+	require.Equal(t, context.DeadlineExceeded, thresholdErr.Failures[2])
+	require.Equal(t, types.GroupEnvelop{}, stxns[0].GroupSignature)
+}
+
+func TestClientSign(t *testing.T) {
+	witnesses, groupPk := makeTestSetup(t, 1, 1)
+	local := witnesses[0]
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/"+signPath, r.URL.Path)
+		require.Equal(t, "token", r.Header.Get(witnessTokenHeader))
+		var req SignRequest
+		require.NoError(t, msgpack.NewDecoder(r.Body).Decode(&req))
+		share, err := local.Sign(r.Context(), req.Txns)
+		require.NoError(t, err)
+		w.Write(msgpack.Encode(SignResponse{Share: share}))
+	}))
+	defer server.Close()
+
+	client, err := MakeClient(server.URL, "token", crypto.Ed25519ThresholdScheme)
+	require.NoError(t, err)
+	c, err := MakeCollector(crypto.Ed25519ThresholdScheme, groupPk, []Witness{client})
+	require.NoError(t, err)
+
+	stxns := makeTestSignedGroup(t)
+	require.NoError(t, c.SignGroup(context.Background(), stxns))
+	require.True(t, crypto.VerifyGroupEnvelop(stxns))
+}
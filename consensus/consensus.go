@@ -0,0 +1,244 @@
+// Package consensus holds the protocol parameters of each consensus version,
+// as reported by algod in SuggestedParams.ConsensusVersion and in the
+// CurrentProtocol field of block headers.
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Known consensus protocol versions
+const (
+	V24 = "https://github.com/algorandfoundation/specs/tree/3a83c4c743f8b17adfd73944b4319c25722a6782"
+	V25 = "https://github.com/algorandfoundation/specs/tree/bea19289bf41217d2c0af30522fa222ef1366466"
+	V26 = "https://github.com/algorandfoundation/specs/tree/ac2255d586c4474d4ebcf3809acccb59b7ef34ff"
+	V27 = "https://github.com/algorandfoundation/specs/tree/d050b3cade6d5c664df8bd729bf219f179812595"
+	V28 = "https://github.com/algorandfoundation/specs/tree/65b4ab3266c52c56a0fa7d591754887d68faad0a"
+)
+
+// Latest is the most recent protocol version known to this SDK
+const Latest = V28
+
+// Params are the consensus parameters of a protocol version that matter to clients
+type Params struct {
+	// MinTxnFee is the minimum fee of a transaction, in microAlgos
+	MinTxnFee uint64
+
+	// EnableFeePooling is whether the fees of a group are pooled: the group
+	// pays at least MinTxnFee per transaction in total, so a transaction may
+	// pay the fees of the others
+	EnableFeePooling bool
+
+	// MaxTxnLife is the longest validity window of a transaction, in rounds
+	MaxTxnLife uint64
+
+	// MaxTxnNoteBytes is the maximum length of a transaction note
+	MaxTxnNoteBytes int
+
+	// MaxTxGroupSize is the maximum number of transactions in a group
+	MaxTxGroupSize int
+
+	// SupportRekeying is whether transactions may set RekeyTo
+	SupportRekeying bool
+
+	// SupportBecomeNonParticipatingTransactions is whether keyreg transactions may set Nonparticipation
+	SupportBecomeNonParticipatingTransactions bool
+
+	// LogicSigMaxSize is the maximum size of a LogicSig program and its arguments
+	LogicSigMaxSize uint64
+
+	// LogicSigMaxCost is the maximum execution cost of a LogicSig program
+	LogicSigMaxCost uint64
+
+	// MaxAssetNameBytes is the maximum length of an asset name
+	MaxAssetNameBytes int
+
+	// MaxAssetUnitNameBytes is the maximum length of an asset unit name
+	MaxAssetUnitNameBytes int
+
+	// MaxAssetURLBytes is the maximum length of an asset url
+	MaxAssetURLBytes int
+
+	// MaxAssetDecimals is the maximum number of decimals of an asset
+	MaxAssetDecimals uint32
+
+	// MaxAppArgs is the maximum number of arguments of an application call
+	MaxAppArgs int
+
+	// MaxAppTotalArgLen is the maximum total length of the arguments of an application call
+	MaxAppTotalArgLen int
+
+	// MaxAppTxnAccounts is the maximum number of accounts an application call may reference
+	MaxAppTxnAccounts int
+
+	// MaxAppTxnForeignApps is the maximum number of applications an application call may reference
+	MaxAppTxnForeignApps int
+
+	// MaxAppTxnForeignAssets is the maximum number of assets an application call may reference
+	MaxAppTxnForeignAssets int
+
+	// MaxAppTotalTxnReferences is the maximum number of accounts, applications and
+	// assets an application call may reference together. Zero means no combined limit.
+	MaxAppTotalTxnReferences int
+
+	// MaxAppProgramLen is the maximum length of an approval or clear state program
+	MaxAppProgramLen int
+
+	// MaxGlobalSchemaEntries is the maximum number of global state entries of an application
+	MaxGlobalSchemaEntries uint64
+
+	// MaxLocalSchemaEntries is the maximum number of local state entries of an application
+	MaxLocalSchemaEntries uint64
+
+	// MaxExtraAppProgramPages is the maximum number of extra pages of MaxAppProgramLen
+	// bytes an application may request for its programs
+	MaxExtraAppProgramPages int
+
+	// MaxAppKeyLen is the maximum length of an application state key
+	MaxAppKeyLen int
+
+	// MaxAppBytesValueLen is the maximum length of an application state bytes value
+	MaxAppBytesValueLen int
+
+	// MaxAppProgramCost is the maximum execution cost of an application program
+	MaxAppProgramCost uint64
+
+	// MaxAppsCreated is the maximum number of applications an account may create
+	MaxAppsCreated int
+
+	// MaxAppsOptedIn is the maximum number of applications an account may opt in to
+	MaxAppsOptedIn int
+
+	// MaxAssetsPerAccount is the maximum number of assets an account may hold or create
+	MaxAssetsPerAccount int
+
+	// LogicSigVersion is the highest TEAL version supported
+	LogicSigVersion uint64
+
+	// MinBalance is the minimum balance of an account, in microAlgos
+	MinBalance uint64
+
+	// AppFlatParamsMinBalance is the minimum balance increase for each created application
+	AppFlatParamsMinBalance uint64
+
+	// AppFlatOptInMinBalance is the minimum balance increase for each application opted in to
+	AppFlatOptInMinBalance uint64
+
+	// SchemaMinBalancePerEntry is the minimum balance increase for each state schema entry
+	SchemaMinBalancePerEntry uint64
+
+	// SchemaUintMinBalance is the additional minimum balance for each uint schema entry
+	SchemaUintMinBalance uint64
+
+	// SchemaBytesMinBalance is the additional minimum balance for each byte slice schema entry
+	SchemaBytesMinBalance uint64
+}
+
+var table = make(map[string]Params)
+
+func init() {
+	v24 := Params{
+		MinTxnFee:              1000,
+		MaxTxnLife:             1000,
+		MaxTxnNoteBytes:        1024,
+		MaxTxGroupSize:         16,
+		SupportRekeying:        true,
+		LogicSigMaxSize:        1000,
+		LogicSigMaxCost:        20000,
+		MaxAssetNameBytes:      32,
+		MaxAssetUnitNameBytes:  8,
+		MaxAssetURLBytes:       32,
+		MaxAssetDecimals:       19,
+		MaxAppArgs:             16,
+		MaxAppTotalArgLen:      2048,
+		MaxAppTxnAccounts:      4,
+		MaxAppTxnForeignApps:   2,
+		MaxAppTxnForeignAssets: 2,
+		MaxAppProgramLen:       1024,
+		MaxGlobalSchemaEntries: 64,
+		MaxLocalSchemaEntries:  16,
+
+		MaxAppKeyLen:        64,
+		MaxAppBytesValueLen: 64,
+		MaxAppProgramCost:   700,
+		MaxAppsCreated:      10,
+		MaxAppsOptedIn:      10,
+		MaxAssetsPerAccount: 1000,
+		LogicSigVersion:     2,
+
+		MinBalance:               100000,
+		AppFlatParamsMinBalance:  100000,
+		AppFlatOptInMinBalance:   100000,
+		SchemaMinBalancePerEntry: 25000,
+		SchemaUintMinBalance:     3500,
+		SchemaBytesMinBalance:    25000,
+	}
+	table[V24] = v24
+
+	// v25 changes how asset close amounts are recorded only
+	v25 := v24
+	table[V25] = v25
+
+	v26 := v25
+	v26.SupportBecomeNonParticipatingTransactions = true
+	v26.MaxAppTxnForeignApps = 8
+	v26.MaxAppTxnForeignAssets = 8
+	v26.MaxAppTotalTxnReferences = 8
+	v26.LogicSigVersion = 3
+	table[V26] = v26
+
+	v27 := v26
+	table[V27] = v27
+
+	v28 := v27
+	v28.MaxAssetURLBytes = 96
+	v28.MaxAppProgramLen = 2048
+	v28.MaxExtraAppProgramPages = 3
+	v28.MaxAppBytesValueLen = 128
+	v28.LogicSigVersion = 4
+	v28.EnableFeePooling = true
+	table[V28] = v28
+}
+
+// Lookup returns the parameters of a protocol version
+func Lookup(version string) (Params, error) {
+	params, ok := table[version]
+	if !ok {
+		return Params{}, fmt.Errorf("unknown consensus version %q", version)
+	}
+	return params, nil
+}
+
+// LookupOrLatest returns the parameters of a protocol version, or the Latest
+// parameters when the version is empty or newer than this SDK. Any unknown
+// version falls back to Latest silently: callers which must not guess, such
+// as fee computations, use Lookup or FromSuggestedParams instead.
+func LookupOrLatest(version string) Params {
+	params, ok := table[version]
+	if !ok {
+		return table[Latest]
+	}
+	return params
+}
+
+// Versions returns the protocol versions known to this SDK, oldest first
+func Versions() []string {
+	return []string{V24, V25, V26, V27, V28}
+}
+
+// FromSuggestedParams returns the parameters of the protocol version reported
+// by algod along with the suggested params. When no version was reported, the
+// Latest parameters are returned.
+func FromSuggestedParams(sp types.SuggestedParams) (Params, error) {
+	if sp.ConsensusVersion == "" {
+		return Lookup(Latest)
+	}
+	return Lookup(sp.ConsensusVersion)
+}
+
+// FromBlockHeader returns the parameters of the protocol a block was produced under
+func FromBlockHeader(header types.BlockHeader) (Params, error) {
+	return Lookup(header.CurrentProtocol)
+}
@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+func TestTableMatchesSDKConstants(t *testing.T) {
+	for _, version := range Versions() {
+		params, err := Lookup(version)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1000), params.MinTxnFee)
+		require.Equal(t, types.MaxTxGroupSize, params.MaxTxGroupSize)
+		require.Equal(t, uint64(types.LogicSigMaxSize), params.LogicSigMaxSize)
+		require.Equal(t, uint64(types.LogicSigMaxCost), params.LogicSigMaxCost)
+	}
+	latest, err := Lookup(Latest)
+	require.NoError(t, err)
+	require.Equal(t, Latest, Versions()[len(Versions())-1])
+	require.Equal(t, uint64(4), latest.LogicSigVersion)
+}
+
+func TestLookup(t *testing.T) {
+	_, err := Lookup("https://example.com/unknown")
+	require.Error(t, err)
+
+	v24 := LookupOrLatest(V24)
+	require.Equal(t, uint64(2), v24.LogicSigVersion)
+	require.Equal(t, 2, v24.MaxAppTxnForeignApps)
+	require.Equal(t, LookupOrLatest(Latest), LookupOrLatest("https://example.com/unknown"))
+
+	params, err := FromSuggestedParams(types.SuggestedParams{ConsensusVersion: V26})
+	require.NoError(t, err)
+	require.Equal(t, 8, params.MaxAppTotalTxnReferences)
+
+	var header types.BlockHeader
+	header.CurrentProtocol = V28
+	params, err = FromBlockHeader(header)
+	require.NoError(t, err)
+	require.Equal(t, 96, params.MaxAssetURLBytes)
+}
@@ -62,7 +62,8 @@ func SignTransaction(sk ed25519.PrivateKey, tx types.Transaction) (txid string,
 	return SignTransactionWithGroupSignature(sk,tx,types.GroupEnvelop{})
 }
 
-
+// SignTransactionWithGroupSignature is like SignTransaction, but also attaches the passed
+// GroupEnvelop (see MakeGroupEnvelop) produced by the signer node witnesses of the group
 func SignTransactionWithGroupSignature(sk ed25519.PrivateKey, tx types.Transaction, groupSig types.GroupEnvelop) (txid string, stxBytes []byte, err error) {
 	s, txid, err := rawSignTransaction(sk, tx)
 	if err != nil {
@@ -616,3 +617,10 @@ func TealSignFromProgram(sk ed25519.PrivateKey, data []byte, program []byte) (ra
 	addr := AddressFromProgram(program)
 	return TealSign(sk, data, addr)
 }
+
+// VerifyTealSign verifies a signature made by TealSign, as the ed25519verify opcode does
+func VerifyTealSign(pk ed25519.PublicKey, data []byte, contractAddress types.Address, rawSig types.Signature) bool {
+	msgParts := [][]byte{programDataPrefix, contractAddress[:], data}
+	toBeVerified := bytes.Join(msgParts, nil)
+	return ed25519.Verify(pk, toBeVerified, rawSig[:])
+}
@@ -15,3 +15,13 @@ var errMsigMergeInvalidDups = errors.New("mismatched duplicate signatures")
 var errLsigInvalidSignature = errors.New("invalid logicsig signature")
 var errLsigInvalidProgram = errors.New("invalid logicsig program")
 var errLsigEmptyMsig = errors.New("empty multisig in logicsig")
+var errGroupSigSchemeEmptyName = errors.New("group signature scheme name cannot be empty")
+var errGroupSigSchemeRegistered = errors.New("group signature scheme already registered")
+var errGroupSigSchemeUnknown = errors.New("unknown group signature scheme")
+var errGroupSigEmptyGroup = errors.New("cannot sign or verify an empty transaction group")
+var errGroupSigInvalidThreshold = errors.New("invalid group signature threshold")
+var errGroupSigInvalidKey = errors.New("invalid group signature public key")
+var errGroupSigUnknownSigner = errors.New("group signature share signer is not part of the group public key")
+var errGroupSigDuplicateSigner = errors.New("duplicate group signature share signer")
+var errGroupSigInvalidShare = errors.New("invalid group signature share")
+var errGroupSigNotEnoughShares = errors.New("not enough group signature shares to reach threshold")
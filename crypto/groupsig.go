@@ -0,0 +1,344 @@
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// groupEnvelopPrefix is prepended to the transaction ids of a group when
+// computing the message signed by signer node witnesses
+var groupEnvelopPrefix = []byte("GE")
+
+// Ed25519ThresholdScheme is the name of the reference ed25519 threshold scheme.
+// A t-of-n key with t == n behaves as a plain aggregate of n ed25519 signatures.
+const Ed25519ThresholdScheme = "ed25519-threshold"
+
+/* Group signature support */
+
+// GroupSignatureShare is the contribution of a single witness to a GroupEnvelop signature
+type GroupSignatureShare struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// PublicKey identifies the witness that produced the share
+	PublicKey []byte `codec:"pk"`
+
+	// Signature is the partial signature produced by the witness
+	Signature []byte `codec:"sig"`
+}
+
+// GroupSignatureScheme produces and checks the signatures carried in a
+// types.GroupEnvelop. Implementations are looked up by GroupEnvelop.Scheme.
+type GroupSignatureScheme interface {
+	// SignShare produces the share of the witness holding sk over message
+	SignShare(sk []byte, message []byte) (GroupSignatureShare, error)
+
+	// VerifyShare checks a single share against the group public key
+	VerifyShare(publicKey []byte, message []byte, share GroupSignatureShare) error
+
+	// Combine merges enough valid shares into the final group signature
+	Combine(publicKey []byte, message []byte, shares []GroupSignatureShare) ([]byte, error)
+
+	// Verify checks a combined group signature
+	Verify(publicKey []byte, message []byte, signature []byte) error
+
+	// Threshold returns how many shares are needed to sign under publicKey
+	Threshold(publicKey []byte) (int, error)
+}
+
+var groupSigSchemes = struct {
+	sync.RWMutex
+	byName map[string]GroupSignatureScheme
+}{byName: make(map[string]GroupSignatureScheme)}
+
+func init() {
+	err := RegisterGroupSignatureScheme(Ed25519ThresholdScheme, ed25519ThresholdScheme{})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegisterGroupSignatureScheme makes a scheme available under the given name,
+// which is the value expected in GroupEnvelop.Scheme
+func RegisterGroupSignatureScheme(name string, scheme GroupSignatureScheme) error {
+	if name == "" {
+		return errGroupSigSchemeEmptyName
+	}
+	groupSigSchemes.Lock()
+	defer groupSigSchemes.Unlock()
+	if _, ok := groupSigSchemes.byName[name]; ok {
+		return fmt.Errorf("%v: %s", errGroupSigSchemeRegistered, name)
+	}
+	groupSigSchemes.byName[name] = scheme
+	return nil
+}
+
+// GetGroupSignatureScheme returns the scheme registered under name
+func GetGroupSignatureScheme(name string) (GroupSignatureScheme, error) {
+	groupSigSchemes.RLock()
+	defer groupSigSchemes.RUnlock()
+	scheme, ok := groupSigSchemes.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%v: %q", errGroupSigSchemeUnknown, name)
+	}
+	return scheme, nil
+}
+
+// GroupEnvelopBytesToSign returns the message witnesses sign for a group:
+// "GE" || txid(txgroup[0]) || txid(txgroup[1]) || ...
+func GroupEnvelopBytesToSign(txgroup []types.Transaction) ([]byte, error) {
+	if len(txgroup) == 0 {
+		return nil, errGroupSigEmptyGroup
+	}
+	msgParts := [][]byte{groupEnvelopPrefix}
+	for _, tx := range txgroup {
+		msgParts = append(msgParts, TransactionID(tx))
+	}
+	return bytes.Join(msgParts, nil), nil
+}
+
+// SignGroupEnvelopShare produces the share of a witness holding sk over the passed group
+func SignGroupEnvelopShare(scheme string, sk []byte, txgroup []types.Transaction) (share GroupSignatureShare, err error) {
+	s, err := GetGroupSignatureScheme(scheme)
+	if err != nil {
+		return
+	}
+	message, err := GroupEnvelopBytesToSign(txgroup)
+	if err != nil {
+		return
+	}
+	return s.SignShare(sk, message)
+}
+
+// MakeGroupEnvelop combines the witness shares over the passed group into a GroupEnvelop
+// ready to be attached to every SignedTxn of the group
+func MakeGroupEnvelop(scheme string, publicKey []byte, txgroup []types.Transaction, shares []GroupSignatureShare) (env types.GroupEnvelop, err error) {
+	s, err := GetGroupSignatureScheme(scheme)
+	if err != nil {
+		return
+	}
+	message, err := GroupEnvelopBytesToSign(txgroup)
+	if err != nil {
+		return
+	}
+	sig, err := s.Combine(publicKey, message, shares)
+	if err != nil {
+		return
+	}
+	env.PublicKey = publicKey
+	env.Signature = sig
+	env.Scheme = scheme
+	return
+}
+
+// VerifyGroupEnvelop verifies the GroupEnvelop carried by a group of signed transactions.
+// Every transaction must carry the same envelop, and its signature must cover the
+// transaction ids of the whole group, in order.
+func VerifyGroupEnvelop(stxns []types.SignedTxn) bool {
+	if len(stxns) == 0 {
+		return false
+	}
+	env := stxns[0].GroupSignature
+	txgroup := make([]types.Transaction, len(stxns))
+	for i, stxn := range stxns {
+		if !groupEnvelopEqual(env, stxn.GroupSignature) {
+			return false
+		}
+		txgroup[i] = stxn.Txn
+	}
+
+	scheme, err := GetGroupSignatureScheme(env.Scheme)
+	if err != nil {
+		return false
+	}
+	message, err := GroupEnvelopBytesToSign(txgroup)
+	if err != nil {
+		return false
+	}
+	return scheme.Verify(env.PublicKey, message, env.Signature) == nil
+}
+
+func groupEnvelopEqual(a, b types.GroupEnvelop) bool {
+	return a.Scheme == b.Scheme &&
+		bytes.Equal(a.PublicKey, b.PublicKey) &&
+		bytes.Equal(a.Signature, b.Signature)
+}
+
+/* ed25519 threshold reference scheme */
+
+// ed25519ThresholdKey is the group public key of the reference scheme
+type ed25519ThresholdKey struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Threshold uint8               `codec:"thr"`
+	Pks       []ed25519.PublicKey `codec:"pks"`
+}
+
+// ed25519ThresholdSig is the combined signature of the reference scheme
+type ed25519ThresholdSig struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Subsigs []types.MultisigSubsig `codec:"subsig"`
+}
+
+// MakeEd25519ThresholdPublicKey builds the group public key of the reference
+// ed25519 scheme: any threshold of the passed witness keys can sign for the group
+func MakeEd25519ThresholdPublicKey(threshold uint8, pks []ed25519.PublicKey) ([]byte, error) {
+	key := ed25519ThresholdKey{Threshold: threshold, Pks: pks}
+	if err := key.validate(); err != nil {
+		return nil, err
+	}
+	return msgpack.Encode(key), nil
+}
+
+func (key ed25519ThresholdKey) validate() error {
+	if key.Threshold == 0 || len(key.Pks) == 0 || int(key.Threshold) > len(key.Pks) {
+		return errGroupSigInvalidThreshold
+	}
+	for i, pk := range key.Pks {
+		if len(pk) != ed25519.PublicKeySize {
+			return errGroupSigInvalidKey
+		}
+		for _, other := range key.Pks[:i] {
+			if bytes.Equal(pk, other) {
+				return errGroupSigInvalidKey
+			}
+		}
+	}
+	return nil
+}
+
+// indexOf returns the position of pk in the key, or -1
+func (key ed25519ThresholdKey) indexOf(pk []byte) int {
+	for i := range key.Pks {
+		if bytes.Equal(key.Pks[i], pk) {
+			return i
+		}
+	}
+	return -1
+}
+
+func decodeEd25519ThresholdKey(publicKey []byte) (key ed25519ThresholdKey, err error) {
+	err = msgpack.Decode(publicKey, &key)
+	if err != nil {
+		return
+	}
+	err = key.validate()
+	return
+}
+
+type ed25519ThresholdScheme struct{}
+
+func (ed25519ThresholdScheme) SignShare(sk []byte, message []byte) (share GroupSignatureShare, err error) {
+	if len(sk) != ed25519.PrivateKeySize {
+		err = errGroupSigInvalidKey
+		return
+	}
+	edsk := ed25519.PrivateKey(sk)
+	share.PublicKey = edsk.Public().(ed25519.PublicKey)
+	share.Signature = ed25519.Sign(edsk, message)
+	return
+}
+
+func (s ed25519ThresholdScheme) VerifyShare(publicKey []byte, message []byte, share GroupSignatureShare) error {
+	key, err := decodeEd25519ThresholdKey(publicKey)
+	if err != nil {
+		return err
+	}
+	return verifyEd25519Share(key, message, share)
+}
+
+func verifyEd25519Share(key ed25519ThresholdKey, message []byte, share GroupSignatureShare) error {
+	if key.indexOf(share.PublicKey) < 0 {
+		return errGroupSigUnknownSigner
+	}
+	if len(share.Signature) != ed25519.SignatureSize || !ed25519.Verify(share.PublicKey, message, share.Signature) {
+		return errGroupSigInvalidShare
+	}
+	return nil
+}
+
+func (s ed25519ThresholdScheme) Combine(publicKey []byte, message []byte, shares []GroupSignatureShare) ([]byte, error) {
+	key, err := decodeEd25519ThresholdKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// place each valid share in the slot of its key, so the combined
+	// signature is independent of the order shares arrived in
+	slots := make([]*GroupSignatureShare, len(key.Pks))
+	collected := 0
+	for i := range shares {
+		if err := verifyEd25519Share(key, message, shares[i]); err != nil {
+			return nil, err
+		}
+		idx := key.indexOf(shares[i].PublicKey)
+		if slots[idx] != nil {
+			return nil, errGroupSigDuplicateSigner
+		}
+		slots[idx] = &shares[i]
+		collected++
+	}
+	if collected < int(key.Threshold) {
+		return nil, errGroupSigNotEnoughShares
+	}
+
+	var sig ed25519ThresholdSig
+	for _, share := range slots {
+		if share == nil {
+			continue
+		}
+		var subsig types.MultisigSubsig
+		subsig.Key = share.PublicKey
+		copy(subsig.Sig[:], share.Signature)
+		sig.Subsigs = append(sig.Subsigs, subsig)
+		if len(sig.Subsigs) == int(key.Threshold) {
+			break
+		}
+	}
+	return msgpack.Encode(sig), nil
+}
+
+func (s ed25519ThresholdScheme) Verify(publicKey []byte, message []byte, signature []byte) error {
+	key, err := decodeEd25519ThresholdKey(publicKey)
+	if err != nil {
+		return err
+	}
+	var sig ed25519ThresholdSig
+	err = msgpack.Decode(signature, &sig)
+	if err != nil {
+		return err
+	}
+
+	seen := make([]bool, len(key.Pks))
+	for _, subsig := range sig.Subsigs {
+		idx := key.indexOf(subsig.Key)
+		if idx < 0 {
+			return errGroupSigUnknownSigner
+		}
+		if seen[idx] {
+			return errGroupSigDuplicateSigner
+		}
+		seen[idx] = true
+		if !ed25519.Verify(subsig.Key, message, subsig.Sig[:]) {
+			return errGroupSigInvalidShare
+		}
+	}
+	if len(sig.Subsigs) < int(key.Threshold) {
+		return errGroupSigNotEnoughShares
+	}
+	return nil
+}
+
+func (s ed25519ThresholdScheme) Threshold(publicKey []byte) (int, error) {
+	key, err := decodeEd25519ThresholdKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	return int(key.Threshold), nil
+}
@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+func makeTestGroup(t *testing.T, sender Account) []types.Transaction {
+	receiver := GenerateAccount()
+	txgroup := make([]types.Transaction, 2)
+	for i := range txgroup {
+		txgroup[i] = types.Transaction{
+			Type: types.PaymentTx,
+			Header: types.Header{
+				Sender:     sender.Address,
+				Fee:        1000,
+				FirstValid: 1,
+				LastValid:  1001,
+				GenesisID:  "testnet-v1.0",
+			},
+			PaymentTxnFields: types.PaymentTxnFields{
+				Receiver: receiver.Address,
+				Amount:   types.MicroAlgos(1000 * (i + 1)),
+			},
+		}
+	}
+	gid, err := ComputeGroupID(txgroup)
+	require.NoError(t, err)
+	for i := range txgroup {
+		txgroup[i].Group = gid
+	}
+	return txgroup
+}
+
+func makeTestWitnesses(t *testing.T, threshold uint8) ([]Account, []byte) {
+	witnesses := []Account{GenerateAccount(), GenerateAccount(), GenerateAccount()}
+	pks := make([]ed25519.PublicKey, len(witnesses))
+	for i, w := range witnesses {
+		pks[i] = w.PublicKey
+	}
+	groupPk, err := MakeEd25519ThresholdPublicKey(threshold, pks)
+	require.NoError(t, err)
+	return witnesses, groupPk
+}
+
+func TestGroupEnvelopRoundTrip(t *testing.T) {
+	sender := GenerateAccount()
+	txgroup := makeTestGroup(t, sender)
+	witnesses, groupPk := makeTestWitnesses(t, 2)
+
+	// shares arrive out of key order
+	var shares []GroupSignatureShare
+	for _, w := range []Account{witnesses[2], witnesses[0]} {
+		share, err := SignGroupEnvelopShare(Ed25519ThresholdScheme, w.PrivateKey, txgroup)
+		require.NoError(t, err)
+		shares = append(shares, share)
+	}
+	env, err := MakeGroupEnvelop(Ed25519ThresholdScheme, groupPk, txgroup, shares)
+	require.NoError(t, err)
+
+	stxns := make([]types.SignedTxn, len(txgroup))
+	for i, tx := range txgroup {
+		_, stxBytes, err := SignTransactionWithGroupSignature(sender.PrivateKey, tx, env)
+		require.NoError(t, err)
+		require.NoError(t, msgpack.Decode(stxBytes, &stxns[i]))
+	}
+	require.Equal(t, env, stxns[0].GroupSignature)
+	require.True(t, VerifyGroupEnvelop(stxns))
+
+	// a different group must not verify under the same envelop
+	tampered := append([]types.SignedTxn(nil), stxns...)
+	tampered[1].Txn.Amount++
+	require.False(t, VerifyGroupEnvelop(tampered))
+
+	// every member must carry the envelop
+	tampered = append([]types.SignedTxn(nil), stxns...)
+	tampered[0].GroupSignature = types.GroupEnvelop{}
+	require.False(t, VerifyGroupEnvelop(tampered))
+}
+
+func TestGroupEnvelopThreshold(t *testing.T) {
+	sender := GenerateAccount()
+	txgroup := makeTestGroup(t, sender)
+	witnesses, groupPk := makeTestWitnesses(t, 2)
+
+	share, err := SignGroupEnvelopShare(Ed25519ThresholdScheme, witnesses[0].PrivateKey, txgroup)
+	require.NoError(t, err)
+
+	_, err = MakeGroupEnvelop(Ed25519ThresholdScheme, groupPk, txgroup, []GroupSignatureShare{share})
+	require.Equal(t, errGroupSigNotEnoughShares, err)
+
+	_, err = MakeGroupEnvelop(Ed25519ThresholdScheme, groupPk, txgroup, []GroupSignatureShare{share, share})
+	require.Equal(t, errGroupSigDuplicateSigner, err)
+
+	outsider := GenerateAccount()
+	bad, err := SignGroupEnvelopShare(Ed25519ThresholdScheme, outsider.PrivateKey, txgroup)
+	require.NoError(t, err)
+	_, err = MakeGroupEnvelop(Ed25519ThresholdScheme, groupPk, txgroup, []GroupSignatureShare{share, bad})
+	require.Equal(t, errGroupSigUnknownSigner, err)
+
+	_, err = MakeGroupEnvelop("unknown", groupPk, txgroup, []GroupSignatureShare{share})
+	require.Error(t, err)
+
+	_, err = MakeEd25519ThresholdPublicKey(4, []ed25519.PublicKey{witnesses[0].PublicKey})
+	require.Equal(t, errGroupSigInvalidThreshold, err)
+}
@@ -0,0 +1,271 @@
+// Package dryrun assembles dryrun requests from the state of a live network,
+// so that a failing group of transactions can be reproduced locally with
+// TealDryrun, and analyzes the responses.
+//
+// A Report classifies every transaction as passed, rejected or failed,
+// computes the cost of its programs, renders their traces step by step and
+// summarizes their state changes. Its assertions return descriptive errors,
+// traces included, meant for Go tests.
+package dryrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Source field names, as expected in DryrunSource.FieldName
+const (
+	approvalSource = "approv"
+	clearSource    = "clearp"
+	logicSigSource = "lsig"
+)
+
+// RequestBuilder assembles a DryrunRequest for a group of signed transactions,
+// fetching from algod the accounts and applications they reference
+type RequestBuilder struct {
+	client   *algod.Client
+	txns     []types.SignedTxn
+	sources  []models.DryrunSource
+	accounts []types.Address
+	apps     []uint64
+
+	round     uint64
+	timestamp uint64
+	protocol  string
+}
+
+// NewRequest starts building a dryrun request for the group txns
+func NewRequest(client *algod.Client, txns ...types.SignedTxn) *RequestBuilder {
+	return &RequestBuilder{client: client, txns: txns}
+}
+
+// ApprovalSource replaces the approval program of application appIdx by the
+// given TEAL source, which algod compiles. appIdx is 0 for an application
+// being created.
+func (b *RequestBuilder) ApprovalSource(appIdx uint64, source string) *RequestBuilder {
+	b.sources = append(b.sources, models.DryrunSource{FieldName: approvalSource, AppIndex: appIdx, Source: source})
+	return b
+}
+
+// ClearSource replaces the clear state program of application appIdx by the
+// given TEAL source
+func (b *RequestBuilder) ClearSource(appIdx uint64, source string) *RequestBuilder {
+	b.sources = append(b.sources, models.DryrunSource{FieldName: clearSource, AppIndex: appIdx, Source: source})
+	return b
+}
+
+// LogicSigSource replaces the LogicSig program of the transaction at txnIndex
+// in the group by the given TEAL source
+func (b *RequestBuilder) LogicSigSource(txnIndex int, source string) *RequestBuilder {
+	b.sources = append(b.sources, models.DryrunSource{FieldName: logicSigSource, TxnIndex: uint64(txnIndex), Source: source})
+	return b
+}
+
+// Accounts adds accounts to fetch besides the ones the transactions reference
+func (b *RequestBuilder) Accounts(addrs ...types.Address) *RequestBuilder {
+	b.accounts = append(b.accounts, addrs...)
+	return b
+}
+
+// Apps adds applications to fetch besides the ones the transactions reference
+func (b *RequestBuilder) Apps(appIdxs ...uint64) *RequestBuilder {
+	b.apps = append(b.apps, appIdxs...)
+	return b
+}
+
+// Round sets the round the programs see instead of the last round of the node
+func (b *RequestBuilder) Round(round uint64) *RequestBuilder {
+	b.round = round
+	return b
+}
+
+// LatestTimestamp sets the timestamp the programs see instead of the one of
+// the block of the round
+func (b *RequestBuilder) LatestTimestamp(timestamp uint64) *RequestBuilder {
+	b.timestamp = timestamp
+	return b
+}
+
+// ProtocolVersion sets the consensus version the programs run under instead
+// of the one of the block of the round
+func (b *RequestBuilder) ProtocolVersion(version string) *RequestBuilder {
+	b.protocol = version
+	return b
+}
+
+// references collects, in order of first appearance, the accounts, the
+// applications and the assets the group references
+type references struct {
+	accounts []types.Address
+	apps     []uint64
+	assets   []uint64
+
+	seenAccounts map[types.Address]bool
+	seenApps     map[uint64]bool
+	seenAssets   map[uint64]bool
+}
+
+func newReferences() *references {
+	return &references{
+		seenAccounts: make(map[types.Address]bool),
+		seenApps:     make(map[uint64]bool),
+		seenAssets:   make(map[uint64]bool),
+	}
+}
+
+func (r *references) addAccount(addr types.Address) {
+	if addr.IsZero() || r.seenAccounts[addr] {
+		return
+	}
+	r.seenAccounts[addr] = true
+	r.accounts = append(r.accounts, addr)
+}
+
+func (r *references) addApp(appIdx uint64) {
+	if appIdx == 0 || r.seenApps[appIdx] {
+		return
+	}
+	r.seenApps[appIdx] = true
+	r.apps = append(r.apps, appIdx)
+}
+
+func (r *references) addAsset(assetIdx uint64) {
+	if assetIdx == 0 || r.seenAssets[assetIdx] {
+		return
+	}
+	r.seenAssets[assetIdx] = true
+	r.assets = append(r.assets, assetIdx)
+}
+
+func (r *references) addTxn(txn types.Transaction) {
+	r.addAccount(txn.Sender)
+	switch txn.Type {
+	case types.ApplicationCallTx:
+		r.addApp(uint64(txn.ApplicationID))
+		for _, addr := range txn.Accounts {
+			r.addAccount(addr)
+		}
+		for _, app := range txn.ForeignApps {
+			r.addApp(uint64(app))
+		}
+		for _, asset := range txn.ForeignAssets {
+			r.addAsset(uint64(asset))
+		}
+	case types.AssetTransferTx:
+		r.addAsset(uint64(txn.XferAsset))
+	case types.AssetConfigTx:
+		r.addAsset(uint64(txn.ConfigAsset))
+	case types.AssetFreezeTx:
+		r.addAsset(uint64(txn.FreezeAsset))
+	}
+}
+
+// Build fetches the state the group references and returns the request. The
+// accounts are the senders, the accounts referenced by application calls,
+// the creators of the referenced applications and assets, and the ones added
+// with Accounts. The applications are the called and referenced ones, and the
+// ones added with Apps.
+func (b *RequestBuilder) Build(ctx context.Context) (models.DryrunRequest, error) {
+	if len(b.txns) == 0 {
+		return models.DryrunRequest{}, fmt.Errorf("dryrun request has no transactions")
+	}
+	for _, source := range b.sources {
+		if source.FieldName == logicSigSource && source.TxnIndex >= uint64(len(b.txns)) {
+			return models.DryrunRequest{}, fmt.Errorf("logicsig source for transaction %d of a group of %d", source.TxnIndex, len(b.txns))
+		}
+	}
+
+	refs := newReferences()
+	for _, stx := range b.txns {
+		refs.addTxn(stx.Txn)
+	}
+	for _, addr := range b.accounts {
+		refs.addAccount(addr)
+	}
+	for _, appIdx := range b.apps {
+		refs.addApp(appIdx)
+	}
+
+	request := models.DryrunRequest{
+		Txns:            b.txns,
+		Sources:         b.sources,
+		Round:           b.round,
+		LatestTimestamp: b.timestamp,
+		ProtocolVersion: b.protocol,
+	}
+
+	for _, appIdx := range refs.apps {
+		app, err := b.client.GetApplicationByID(appIdx).Do(ctx)
+		if err != nil {
+			return models.DryrunRequest{}, fmt.Errorf("fetching application %d: %v", appIdx, err)
+		}
+		request.Apps = append(request.Apps, app)
+		if creator, err := types.DecodeAddress(app.Params.Creator); err == nil {
+			refs.addAccount(creator)
+		}
+	}
+	for _, assetIdx := range refs.assets {
+		asset, err := b.client.GetAssetByID(assetIdx).Do(ctx)
+		if err != nil {
+			return models.DryrunRequest{}, fmt.Errorf("fetching asset %d: %v", assetIdx, err)
+		}
+		// asset parameters are looked up in the account of the creator
+		if creator, err := types.DecodeAddress(asset.Params.Creator); err == nil {
+			refs.addAccount(creator)
+		}
+	}
+	for _, addr := range refs.accounts {
+		account, err := b.client.AccountInformation(addr.String()).Do(ctx)
+		if err != nil {
+			return models.DryrunRequest{}, fmt.Errorf("fetching account %s: %v", addr, err)
+		}
+		request.Accounts = append(request.Accounts, account)
+	}
+
+	if err := b.fillRound(ctx, &request); err != nil {
+		return models.DryrunRequest{}, err
+	}
+	return request, nil
+}
+
+// fillRound defaults the round of the request to the last round of the node,
+// then its timestamp and protocol to the ones of the block of that round
+func (b *RequestBuilder) fillRound(ctx context.Context, request *models.DryrunRequest) error {
+	if request.Round != 0 && request.LatestTimestamp != 0 && request.ProtocolVersion != "" {
+		return nil
+	}
+	if request.Round == 0 {
+		status, err := b.client.Status().Do(ctx)
+		if err != nil {
+			return fmt.Errorf("fetching node status: %v", err)
+		}
+		request.Round = status.LastRound
+	}
+	if request.LatestTimestamp != 0 && request.ProtocolVersion != "" {
+		return nil
+	}
+	block, err := b.client.Block(request.Round).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching block %d: %v", request.Round, err)
+	}
+	if request.ProtocolVersion == "" {
+		request.ProtocolVersion = block.CurrentProtocol
+	}
+	if request.LatestTimestamp == 0 && block.TimeStamp > 0 {
+		request.LatestTimestamp = uint64(block.TimeStamp)
+	}
+	return nil
+}
+
+// Run builds the request and submits it to the TealDryrun endpoint of the node
+func (b *RequestBuilder) Run(ctx context.Context) (models.DryrunResponse, error) {
+	request, err := b.Build(ctx)
+	if err != nil {
+		return models.DryrunResponse{}, err
+	}
+	return b.client.TealDryrun(request).Do(ctx)
+}
@@ -0,0 +1,179 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	sender  = types.Address{1}
+	other   = types.Address{2}
+	creator = types.Address{3}
+	issuer  = types.Address{4}
+)
+
+// mockAlgod serves the state the builder fetches and records the requested paths
+func mockAlgod(t *testing.T, requested *[]string) (*algod.Client, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requested = append(*requested, r.URL.Path)
+		switch {
+		case r.URL.Path == "/v2/status":
+			json.NewEncoder(w).Encode(models.NodeStatus{LastRound: 500, LastVersion: "next"})
+		case r.URL.Path == "/v2/blocks/500":
+			block := types.Block{}
+			block.TimeStamp = 1600000000
+			block.CurrentProtocol = "future"
+			w.Write(msgpack.Encode(map[string]interface{}{"block": block}))
+		case r.URL.Path == "/v2/blocks/10":
+			block := types.Block{}
+			block.TimeStamp = 1500000000
+			block.CurrentProtocol = "v1"
+			w.Write(msgpack.Encode(map[string]interface{}{"block": block}))
+		case strings.HasPrefix(r.URL.Path, "/v2/applications/"):
+			json.NewEncoder(w).Encode(models.Application{
+				Id:     42,
+				Params: models.ApplicationParams{Creator: creator.String(), ApprovalProgram: []byte{0x02}},
+			})
+		case strings.HasPrefix(r.URL.Path, "/v2/assets/"):
+			json.NewEncoder(w).Encode(models.Asset{Index: 7, Params: models.AssetParams{Creator: issuer.String()}})
+		case strings.HasPrefix(r.URL.Path, "/v2/accounts/"):
+			addr := strings.TrimPrefix(r.URL.Path, "/v2/accounts/")
+			json.NewEncoder(w).Encode(models.Account{Address: addr, Amount: 1000000})
+		case r.URL.Path == "/v2/teal/dryrun":
+			json.NewEncoder(w).Encode(models.DryrunResponse{ProtocolVersion: "future"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	client, err := algod.MakeClient(server.URL, "")
+	require.NoError(t, err)
+	return client, server.Close
+}
+
+func appCall() types.SignedTxn {
+	var stx types.SignedTxn
+	stx.Txn.Type = types.ApplicationCallTx
+	stx.Txn.Sender = sender
+	stx.Txn.ApplicationID = 42
+	stx.Txn.Accounts = []types.Address{other, sender}
+	stx.Txn.ForeignAssets = []types.AssetIndex{7}
+	return stx
+}
+
+func TestBuild(t *testing.T) {
+	var requested []string
+	client, closeServer := mockAlgod(t, &requested)
+	defer closeServer()
+
+	request, err := NewRequest(client, appCall(), appCall()).
+		ApprovalSource(42, "#pragma version 2\nint 1").
+		Build(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, request.Txns, 2)
+	require.Len(t, request.Apps, 1)
+	require.Equal(t, uint64(42), request.Apps[0].Id)
+
+	var addrs []string
+	for _, account := range request.Accounts {
+		addrs = append(addrs, account.Address)
+	}
+	require.Equal(t, []string{sender.String(), other.String(), creator.String(), issuer.String()}, addrs)
+
+	require.Equal(t, []models.DryrunSource{{FieldName: "approv", AppIndex: 42, Source: "#pragma version 2\nint 1"}}, request.Sources)
+	require.Equal(t, uint64(500), request.Round)
+	require.Equal(t, uint64(1600000000), request.LatestTimestamp)
+	require.Equal(t, "future", request.ProtocolVersion)
+
+	// every piece of state is fetched once
+	require.Equal(t, 1, strings.Count(strings.Join(requested, " "), "/v2/applications/42"))
+}
+
+func TestBuildOverrides(t *testing.T) {
+	var requested []string
+	client, closeServer := mockAlgod(t, &requested)
+	defer closeServer()
+
+	var pay types.SignedTxn
+	pay.Txn.Type = types.PaymentTx
+	pay.Txn.Sender = sender
+
+	request, err := NewRequest(client, pay).
+		Accounts(other).
+		Round(10).
+		LatestTimestamp(20).
+		ProtocolVersion("v1").
+		Build(context.Background())
+	require.NoError(t, err)
+	require.Len(t, request.Accounts, 2)
+	require.Empty(t, request.Apps)
+	require.Equal(t, uint64(10), request.Round)
+	require.Equal(t, uint64(20), request.LatestTimestamp)
+	require.Equal(t, "v1", request.ProtocolVersion)
+	require.NotContains(t, requested, "/v2/status")
+
+	// the timestamp and protocol are the ones of the block of the round
+	request, err = NewRequest(client, pay).Round(10).Build(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1500000000), request.LatestTimestamp)
+	require.Equal(t, "v1", request.ProtocolVersion)
+	require.NotContains(t, requested, "/v2/status")
+
+	_, err = NewRequest(client, pay).LogicSigSource(1, "int 1").Build(context.Background())
+	require.Error(t, err)
+
+	_, err = NewRequest(client).Build(context.Background())
+	require.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	var requested []string
+	client, closeServer := mockAlgod(t, &requested)
+	defer closeServer()
+
+	response, err := NewRequest(client, appCall()).Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "future", response.ProtocolVersion)
+	require.Contains(t, requested, "/v2/teal/dryrun")
+}
+
+func TestRunSendsMsgpack(t *testing.T) {
+	stx := appCall()
+	stx.Sig = types.Signature{1, 2, 3}
+	stx.Txn.Note = []byte("exact")
+
+	var received models.DryrunRequest
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		contentType = r.Header.Get("Content-Type")
+		received, err = algod.DecodeDryrunRequest(body)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(models.DryrunResponse{})
+	}))
+	defer server.Close()
+	client, err := algod.MakeClient(server.URL, "")
+	require.NoError(t, err)
+
+	request := models.DryrunRequest{Txns: []types.SignedTxn{stx}, Round: 3}
+	_, err = client.TealDryrun(request).Do(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "application/msgpack", contentType)
+	require.Equal(t, request, received)
+
+	_, err = client.TealDryrunRaw(algod.EncodeDryrunRequest(request)).Do(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, request, received)
+}
@@ -0,0 +1,466 @@
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Status is the outcome of the programs of a transaction
+type Status int
+
+const (
+	// Pass means every program of the transaction approved it, or that it ran
+	// no program
+	Pass Status = iota
+
+	// Reject means a program completed without approving the transaction
+	Reject
+
+	// Error means a program failed, e.g. on an invalid opcode or an assertion
+	Error
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Reject:
+		return "REJECT"
+	case Error:
+		return "ERROR"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// opcodeCosts holds the opcodes costing more than 1, by program version.
+// The cost of programs of later versions is not computed, see
+// TxnReport.AppCostKnown.
+var opcodeCosts = []map[string]int{
+	1: {"sha256": 7, "keccak256": 26, "sha512_256": 9, "ed25519verify": 1900},
+	2: {"sha256": 35, "keccak256": 130, "sha512_256": 45, "ed25519verify": 1900},
+	3: {"sha256": 35, "keccak256": 130, "sha512_256": 45, "ed25519verify": 1900},
+	4: {
+		"sha256": 35, "keccak256": 130, "sha512_256": 45, "ed25519verify": 1900,
+		"divmodw": 20, "sqrt": 4, "expw": 10,
+		"b+": 10, "b-": 10, "b/": 20, "b*": 20, "b%": 20,
+		"b|": 6, "b&": 6, "b^": 6, "b~": 4,
+	},
+}
+
+// TxnReport is the analysis of the dryrun of one transaction of the group
+type TxnReport struct {
+	// Index of the transaction in the group
+	Index int
+
+	// Txn is the transaction, when it was part of the request
+	Txn types.Transaction
+
+	// Status is the worst outcome of the LogicSig and the application call
+	Status Status
+
+	// LogicSigStatus and AppStatus are the outcomes of each program; they are
+	// Pass when the transaction did not run the program
+	LogicSigStatus Status
+	AppStatus      Status
+
+	// Errors holds the error messages of the programs
+	Errors []string
+
+	// LogicSigCost and AppCost are the cost of the executed opcodes.
+	// LogicSigCost is zero when the transaction also called an application,
+	// see LogicSigTrace.
+	LogicSigCost int
+	AppCost      int
+
+	// LogicSigCostKnown and AppCostKnown are false when the cost of the
+	// program could not be computed, because its disassembly is not available
+	// or because its version is newer than this SDK. The cost is then zero.
+	LogicSigCostKnown bool
+	AppCostKnown      bool
+
+	// GlobalChanges are the changes of the global state of the called
+	// application, relative to the state of the request
+	GlobalChanges []appstate.Change
+
+	// LocalChanges are the changes of local states, indexed by address
+	LocalChanges map[string][]appstate.Change
+
+	result models.DryrunTxnResult
+}
+
+// Report is the analysis of a dryrun response
+type Report struct {
+	ProtocolVersion string
+	Txns            []TxnReport
+}
+
+// NewReport analyzes response, the result of running request. The request
+// provides the transactions and the state the changes are relative to.
+func NewReport(request models.DryrunRequest, response models.DryrunResponse) (*Report, error) {
+	if response.Error != "" {
+		return nil, fmt.Errorf("dryrun failed: %s", response.Error)
+	}
+	report := &Report{ProtocolVersion: response.ProtocolVersion}
+	for i, result := range response.Txns {
+		txnReport := TxnReport{Index: i, result: result}
+		if i < len(request.Txns) {
+			txnReport.Txn = request.Txns[i].Txn
+		}
+
+		txnReport.LogicSigStatus, txnReport.Errors = classify(result.LogicSigMessages, txnReport.Errors)
+		txnReport.AppStatus, txnReport.Errors = classify(result.AppCallMessages, txnReport.Errors)
+		txnReport.Status = txnReport.LogicSigStatus
+		if txnReport.AppStatus > txnReport.Status {
+			txnReport.Status = txnReport.AppStatus
+		}
+		for _, trace := range [][]models.DryrunState{result.LogicSigTrace, result.AppCallTrace} {
+			for _, state := range trace {
+				if state.Error != "" {
+					txnReport.Errors = appendUnique(txnReport.Errors, state.Error)
+				}
+			}
+		}
+
+		txnReport.LogicSigCost, txnReport.LogicSigCostKnown = cost(txnReport.logicSigDisassembly(), result.LogicSigTrace)
+		txnReport.AppCost, txnReport.AppCostKnown = cost(result.Disassembly, result.AppCallTrace)
+
+		if err := txnReport.summarizeDeltas(request); err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		report.Txns = append(report.Txns, txnReport)
+	}
+	return report, nil
+}
+
+// classify returns the outcome of a program from the messages algod produced
+// for it, "PASS", "REJECT" or "ERROR: ...", and adds the errors to errs
+func classify(messages []string, errs []string) (Status, []string) {
+	status := Pass
+	for _, message := range messages {
+		switch {
+		case strings.HasPrefix(message, "ERROR"):
+			status = Error
+			errs = appendUnique(errs, strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(message, "ERROR"), ":")))
+		case message == "REJECT" && status < Reject:
+			status = Reject
+		}
+	}
+	return status, errs
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// cost returns the cost of the opcodes executed in trace and whether it is
+// known. The disassembly starts with a "// version N" comment, which selects
+// the opcode costs. The cost is unknown when the disassembly is not available
+// or the version has no opcode costs.
+func cost(disassembly []string, trace []models.DryrunState) (int, bool) {
+	if len(trace) == 0 {
+		return 0, true
+	}
+	if disassembly == nil {
+		return 0, false
+	}
+	version := 1
+	if len(disassembly) > 0 {
+		fields := strings.Fields(disassembly[0])
+		if len(fields) == 3 && fields[1] == "version" {
+			if v, err := strconv.Atoi(fields[2]); err == nil {
+				version = v
+			}
+		}
+	}
+	if version < 1 || version >= len(opcodeCosts) {
+		return 0, false
+	}
+	costs := opcodeCosts[version]
+
+	total := 0
+	for _, state := range trace {
+		opcode := ""
+		if int(state.Line) < len(disassembly) {
+			if fields := strings.Fields(disassembly[state.Line]); len(fields) > 0 {
+				opcode = fields[0]
+			}
+		}
+		if c, ok := costs[opcode]; ok {
+			total += c
+		} else {
+			total++
+		}
+	}
+	return total, true
+}
+
+// summarizeDeltas computes the state changes of the transaction relative to
+// the state of the applications and accounts of the request
+func (r *TxnReport) summarizeDeltas(request models.DryrunRequest) error {
+	if len(r.result.GlobalDelta) == 0 && len(r.result.LocalDeltas) == 0 {
+		return nil
+	}
+	appIdx := uint64(r.Txn.ApplicationID)
+
+	before := make(appstate.State)
+	for _, app := range request.Apps {
+		if app.Id == appIdx && appIdx != 0 {
+			var err error
+			if before, err = appstate.GlobalState(app); err != nil {
+				return err
+			}
+		}
+	}
+	after := before.Clone()
+	if err := after.Apply(r.result.GlobalDelta); err != nil {
+		return err
+	}
+	r.GlobalChanges = appstate.Diff(before, after)
+
+	for _, delta := range r.result.LocalDeltas {
+		before := make(appstate.State)
+		for _, account := range request.Accounts {
+			if account.Address == delta.Address {
+				if state, err := appstate.LocalState(account, appIdx); err == nil {
+					before = state
+				}
+			}
+		}
+		after := before.Clone()
+		if err := after.Apply(delta.Delta); err != nil {
+			return err
+		}
+		if r.LocalChanges == nil {
+			r.LocalChanges = make(map[string][]appstate.Change)
+		}
+		r.LocalChanges[delta.Address] = appstate.Diff(before, after)
+	}
+	return nil
+}
+
+// logicSigDisassembly returns the disassembly of the LogicSig program, or nil
+// when the transaction also called an application: algod then returns the
+// disassembly of the application program only
+func (r TxnReport) logicSigDisassembly() []string {
+	if len(r.result.AppCallTrace) > 0 {
+		return nil
+	}
+	return r.result.Disassembly
+}
+
+// LogicSigTrace renders the execution of the LogicSig of the transaction.
+// When the transaction also called an application, the source of the LogicSig
+// is not available and only the program counters and stacks are rendered.
+func (r TxnReport) LogicSigTrace() string {
+	disassembly := r.logicSigDisassembly()
+	if disassembly == nil && len(r.result.LogicSigTrace) > 0 {
+		return "no disassembly available for the LogicSig\n" + renderTrace(nil, r.result.LogicSigTrace)
+	}
+	return renderTrace(disassembly, r.result.LogicSigTrace)
+}
+
+// AppTrace renders the execution of the application call of the transaction
+func (r TxnReport) AppTrace() string {
+	return renderTrace(r.result.Disassembly, r.result.AppCallTrace)
+}
+
+// renderTrace renders a table with, at every step, the program counter, the
+// disassembled line being executed, the stack before executing it and the
+// scratch slots that are set to a nonzero value
+func renderTrace(disassembly []string, trace []models.DryrunState) string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "pc\tline\tsource\tstack\tscratch")
+	for _, state := range trace {
+		source := ""
+		if int(state.Line) < len(disassembly) {
+			source = disassembly[state.Line]
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\n", state.Pc, state.Line, source, renderStack(state.Stack), renderScratch(state.Scratch))
+		if state.Error != "" {
+			fmt.Fprintf(tw, "\t\terror: %s\t\t\n", state.Error)
+		}
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+func renderValue(tv models.TealValue) string {
+	value, err := appstate.DecodeValue(tv)
+	if err != nil {
+		return "?"
+	}
+	return value.String()
+}
+
+func renderStack(stack []models.TealValue) string {
+	values := make([]string, len(stack))
+	for i, tv := range stack {
+		values[i] = renderValue(tv)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func renderScratch(scratch []models.TealValue) string {
+	var slots []string
+	for i, tv := range scratch {
+		if tv.Type == 0 || (tv.Type == uint64(appstate.TypeUint) && tv.Uint == 0) {
+			continue
+		}
+		slots = append(slots, fmt.Sprintf("%d=%s", i, renderValue(tv)))
+	}
+	return strings.Join(slots, " ")
+}
+
+// String renders a summary of the report, one line per transaction
+func (r *Report) String() string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "txn\ttype\tstatus\tlsig cost\tapp cost\tmessage")
+	for _, txn := range r.Txns {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", txn.Index, txn.Txn.Type, txn.Status, formatCost(txn.LogicSigCost, txn.LogicSigCostKnown), formatCost(txn.AppCost, txn.AppCostKnown), strings.Join(txn.Errors, "; "))
+		for _, change := range txn.GlobalChanges {
+			fmt.Fprintf(tw, "\t\tglobal %s\t\t\t\n", change)
+		}
+		addrs := make([]string, 0, len(txn.LocalChanges))
+		for addr := range txn.LocalChanges {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+		for _, addr := range addrs {
+			for _, change := range txn.LocalChanges[addr] {
+				fmt.Fprintf(tw, "\t\tlocal %s %s\t\t\t\n", addr, change)
+			}
+		}
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// formatCost formats a cost, "?" when it is unknown
+func formatCost(cost int, known bool) string {
+	if !known {
+		return "?"
+	}
+	return strconv.Itoa(cost)
+}
+
+// txn returns the report of the transaction at index i
+func (r *Report) txn(i int) (TxnReport, error) {
+	if i < 0 || i >= len(r.Txns) {
+		return TxnReport{}, fmt.Errorf("no transaction %d in a dryrun of %d", i, len(r.Txns))
+	}
+	return r.Txns[i], nil
+}
+
+// AssertApproved returns an error, including the traces, unless the
+// transaction at index i passed
+func (r *Report) AssertApproved(i int) error {
+	return r.assertStatus(i, Pass)
+}
+
+// AssertRejected returns an error unless a program rejected the transaction
+// at index i without failing
+func (r *Report) AssertRejected(i int) error {
+	return r.assertStatus(i, Reject)
+}
+
+// AssertError returns an error unless a program failed on the transaction at
+// index i with an error message containing contains
+func (r *Report) AssertError(i int, contains string) error {
+	if err := r.assertStatus(i, Error); err != nil {
+		return err
+	}
+	for _, message := range r.Txns[i].Errors {
+		if strings.Contains(message, contains) {
+			return nil
+		}
+	}
+	return fmt.Errorf("transaction %d failed with %q, expected an error containing %q", i, r.Txns[i].Errors, contains)
+}
+
+func (r *Report) assertStatus(i int, expected Status) error {
+	txn, err := r.txn(i)
+	if err != nil {
+		return err
+	}
+	if txn.Status == expected {
+		return nil
+	}
+	msg := fmt.Sprintf("transaction %d: expected %s, got %s", i, expected, txn.Status)
+	if len(txn.Errors) > 0 {
+		msg += ": " + strings.Join(txn.Errors, "; ")
+	}
+	if txn.LogicSigStatus != Pass {
+		msg += "\nlogicsig trace:\n" + txn.LogicSigTrace()
+	}
+	if txn.AppStatus != Pass {
+		msg += "\napp trace:\n" + txn.AppTrace()
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// AssertGlobalDelta returns an error unless the transaction at index i set
+// key of the global state to value
+func (r *Report) AssertGlobalDelta(i int, key string, value appstate.Value) error {
+	txn, err := r.txn(i)
+	if err != nil {
+		return err
+	}
+	return assertChange(fmt.Sprintf("transaction %d: global", i), txn.GlobalChanges, key, value)
+}
+
+// AssertLocalDelta returns an error unless the transaction at index i set key
+// of the local state of addr to value
+func (r *Report) AssertLocalDelta(i int, addr types.Address, key string, value appstate.Value) error {
+	txn, err := r.txn(i)
+	if err != nil {
+		return err
+	}
+	return assertChange(fmt.Sprintf("transaction %d: local state of %s", i, addr), txn.LocalChanges[addr.String()], key, value)
+}
+
+func assertChange(where string, changes []appstate.Change, key string, value appstate.Value) error {
+	name := appstate.BytesValue([]byte(key)).String()
+	for _, change := range changes {
+		if change.Key != key {
+			continue
+		}
+		if change.Deleted() {
+			return fmt.Errorf("%s key %s was deleted, expected %s", where, name, value)
+		}
+		if !change.New.Equal(value) {
+			return fmt.Errorf("%s key %s is %s, expected %s", where, name, change.New, value)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s key %s did not change, expected %s", where, name, value)
+}
+
+// RunReport builds the request, submits it to the TealDryrun endpoint of the
+// node and analyzes the response
+func (b *RequestBuilder) RunReport(ctx context.Context) (*Report, error) {
+	request, err := b.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response, err := b.client.TealDryrun(request).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewReport(request, response)
+}
@@ -0,0 +1,198 @@
+package dryrun
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func uintValue(v uint64) models.TealValue {
+	return models.TealValue{Type: 2, Uint: v}
+}
+
+var disassembly = []string{
+	"// version 2",
+	"intcblock 1",
+	"byte 0x636f756e746572 // \"counter\"",
+	"sha256",
+	"pop",
+	"intc_0 // 1",
+}
+
+func testResponse() (models.DryrunRequest, models.DryrunResponse) {
+	request := models.DryrunRequest{
+		Txns: []types.SignedTxn{appCall(), appCall(), appCall()},
+		Apps: []models.Application{{
+			Id: 42,
+			Params: models.ApplicationParams{GlobalState: []models.TealKeyValue{
+				{Key: b64("counter"), Value: uintValue(1)},
+				{Key: b64("owner"), Value: models.TealValue{Type: 1, Bytes: b64("alice")}},
+			}},
+		}},
+		Accounts: []models.Account{{
+			Address:        sender.String(),
+			AppsLocalState: []models.ApplicationLocalState{{Id: 42}},
+		}},
+	}
+
+	trace := []models.DryrunState{
+		{Pc: 1, Line: 1},
+		{Pc: 4, Line: 2},
+		{Pc: 13, Line: 3, Stack: []models.TealValue{{Type: 1, Bytes: b64("counter")}}},
+		{Pc: 14, Line: 4, Stack: []models.TealValue{{Type: 1, Bytes: base64.StdEncoding.EncodeToString([]byte{0xff, 0x00})}}},
+		{Pc: 15, Line: 5, Scratch: []models.TealValue{uintValue(0), uintValue(9)}},
+	}
+	response := models.DryrunResponse{
+		ProtocolVersion: "future",
+		Txns: []models.DryrunTxnResult{
+			{
+				AppCallMessages: []string{"ApprovalProgram", "PASS"},
+				AppCallTrace:    trace,
+				Disassembly:     disassembly,
+				GlobalDelta: []models.EvalDeltaKeyValue{
+					{Key: b64("counter"), Value: models.EvalDelta{Action: 2, Uint: 2}},
+					{Key: b64("owner"), Value: models.EvalDelta{Action: 3}},
+				},
+				LocalDeltas: []models.AccountStateDelta{{
+					Address: sender.String(),
+					Delta:   []models.EvalDeltaKeyValue{{Key: b64("votes"), Value: models.EvalDelta{Action: 2, Uint: 5}}},
+				}},
+			},
+			{
+				AppCallMessages: []string{"ApprovalProgram", "REJECT"},
+				AppCallTrace:    trace[:2],
+				Disassembly:     disassembly,
+			},
+			{
+				AppCallMessages: []string{"ApprovalProgram", "ERROR: assert failed"},
+				AppCallTrace:    []models.DryrunState{{Pc: 1, Line: 1}, {Pc: 4, Line: 2, Error: "assert failed"}},
+				Disassembly:     disassembly,
+			},
+		},
+	}
+	return request, response
+}
+
+func TestReport(t *testing.T) {
+	report, err := NewReport(testResponse())
+	require.NoError(t, err)
+	require.Len(t, report.Txns, 3)
+
+	require.Equal(t, Pass, report.Txns[0].Status)
+	require.Equal(t, Reject, report.Txns[1].Status)
+	require.Equal(t, Error, report.Txns[2].Status)
+	require.Equal(t, []string{"assert failed"}, report.Txns[2].Errors)
+
+	// sha256 costs 35 from version 2
+	require.Equal(t, 39, report.Txns[0].AppCost)
+	require.Equal(t, 2, report.Txns[1].AppCost)
+	require.Zero(t, report.Txns[0].LogicSigCost)
+	require.True(t, report.Txns[0].LogicSigCostKnown)
+	require.True(t, report.Txns[0].AppCostKnown)
+
+	changes := report.Txns[0].GlobalChanges
+	require.Len(t, changes, 2)
+	require.Equal(t, `~ "counter": 1 -> 2`, changes[0].String())
+	require.Equal(t, `- "owner" = "alice"`, changes[1].String())
+	require.Equal(t, `+ "votes" = 5`, report.Txns[0].LocalChanges[sender.String()][0].String())
+
+	summary := report.String()
+	require.Contains(t, summary, "REJECT")
+	require.Contains(t, summary, "assert failed")
+	require.Contains(t, summary, `global ~ "counter": 1 -> 2`)
+
+	trace := report.Txns[0].AppTrace()
+	lines := strings.Split(strings.TrimSpace(trace), "\n")
+	require.Len(t, lines, 6)
+	require.Contains(t, lines[3], "sha256")
+	require.Contains(t, lines[3], `["counter"]`)
+	require.Contains(t, lines[4], "[base64:/wA=]")
+	require.Contains(t, lines[5], "1=9")
+	require.NotContains(t, lines[5], "0=0")
+}
+
+func TestAssertions(t *testing.T) {
+	report, err := NewReport(testResponse())
+	require.NoError(t, err)
+
+	require.NoError(t, report.AssertApproved(0))
+	require.NoError(t, report.AssertRejected(1))
+	require.NoError(t, report.AssertError(2, "assert"))
+	require.NoError(t, report.AssertGlobalDelta(0, "counter", appstate.UintValue(2)))
+	require.NoError(t, report.AssertLocalDelta(0, sender, "votes", appstate.UintValue(5)))
+
+	err = report.AssertApproved(2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected PASS, got ERROR: assert failed")
+	require.Contains(t, err.Error(), "app trace:")
+
+	require.Error(t, report.AssertApproved(3))
+	require.Error(t, report.AssertError(1, "assert"))
+	require.Error(t, report.AssertGlobalDelta(0, "counter", appstate.UintValue(3)))
+	require.Error(t, report.AssertGlobalDelta(0, "owner", appstate.BytesValue([]byte("bob"))))
+	require.Error(t, report.AssertGlobalDelta(1, "counter", appstate.UintValue(2)))
+	require.Error(t, report.AssertLocalDelta(0, other, "votes", appstate.UintValue(5)))
+
+	_, err = NewReport(models.DryrunRequest{}, models.DryrunResponse{Error: "cannot compile"})
+	require.Error(t, err)
+}
+
+func TestCostFollowsVersion(t *testing.T) {
+	trace := []models.DryrunState{{Pc: 1, Line: 1}, {Pc: 2, Line: 2}, {Pc: 3, Line: 3}}
+	report := func(version string) TxnReport {
+		request := models.DryrunRequest{Txns: []types.SignedTxn{appCall()}}
+		response := models.DryrunResponse{Txns: []models.DryrunTxnResult{{
+			AppCallMessages: []string{"ApprovalProgram", "PASS"},
+			AppCallTrace:    trace,
+			Disassembly:     []string{"// version " + version, "b+", "divmodw", "sqrt"},
+		}}}
+		report, err := NewReport(request, response)
+		require.NoError(t, err)
+		return report.Txns[0]
+	}
+
+	v4 := report("4")
+	require.True(t, v4.AppCostKnown)
+	require.Equal(t, 10+20+4, v4.AppCost)
+
+	// programs newer than the SDK are still reported, without their cost
+	v5 := report("5")
+	require.Equal(t, Pass, v5.Status)
+	require.False(t, v5.AppCostKnown)
+	require.Zero(t, v5.AppCost)
+}
+
+func TestLogicSigAndAppCall(t *testing.T) {
+	request, response := testResponse()
+	result := &response.Txns[0]
+	result.LogicSigMessages = []string{"PASS"}
+	result.LogicSigTrace = []models.DryrunState{{Pc: 1, Line: 1}, {Pc: 2, Line: 3}}
+
+	// the disassembly is the one of the application, not of the LogicSig
+	report, err := NewReport(request, response)
+	require.NoError(t, err)
+	require.Zero(t, report.Txns[0].LogicSigCost)
+	require.False(t, report.Txns[0].LogicSigCostKnown)
+	require.Equal(t, 39, report.Txns[0].AppCost)
+	require.Contains(t, report.String(), "?")
+	trace := report.Txns[0].LogicSigTrace()
+	require.Contains(t, trace, "no disassembly available")
+	require.NotContains(t, trace, "sha256")
+
+	// without an application call, the disassembly is the LogicSig's
+	result.AppCallMessages, result.AppCallTrace = nil, nil
+	report, err = NewReport(request, response)
+	require.NoError(t, err)
+	require.Equal(t, 36, report.Txns[0].LogicSigCost)
+	require.True(t, report.Txns[0].LogicSigCostKnown)
+	require.Contains(t, report.Txns[0].LogicSigTrace(), "sha256")
+}
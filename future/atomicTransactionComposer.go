@@ -0,0 +1,285 @@
+package future
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// AtomicTransactionComposerStatus is the phase an AtomicTransactionComposer is in
+type AtomicTransactionComposerStatus int
+
+const (
+	// BUILDING means transactions can still be added to the group
+	BUILDING AtomicTransactionComposerStatus = iota
+
+	// BUILT means the group id has been assigned and the group can no longer change
+	BUILT
+
+	// SIGNED means every transaction of the group has been signed
+	SIGNED
+
+	// SUBMITTED means the group has been sent to the network
+	SUBMITTED
+
+	// COMMITTED means the group has been confirmed by the network
+	COMMITTED
+)
+
+func (s AtomicTransactionComposerStatus) String() string {
+	switch s {
+	case BUILDING:
+		return "building"
+	case BUILT:
+		return "built"
+	case SIGNED:
+		return "signed"
+	case SUBMITTED:
+		return "submitted"
+	case COMMITTED:
+		return "committed"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// TransactionWithSigner pairs a transaction with the signer that authorizes it
+type TransactionWithSigner struct {
+	Txn    types.Transaction
+	Signer TransactionSigner
+}
+
+// TransactionResult is the outcome of a committed transaction of the group
+type TransactionResult struct {
+	TxID             string
+	ConfirmedRound   uint64
+	ApplicationIndex uint64
+	AssetIndex       uint64
+}
+
+// ExecuteResult is the outcome of executing a group
+type ExecuteResult struct {
+	// ConfirmedRound is the round the group was committed in
+	ConfirmedRound uint64
+
+	// TxIDs of the group, in order
+	TxIDs []string
+
+	// Results of every transaction of the group, in order
+	Results []TransactionResult
+}
+
+// AtomicTransactionComposer accumulates transactions and their signers, then
+// groups, signs, submits and waits for them as one atomic group
+type AtomicTransactionComposer struct {
+	status  AtomicTransactionComposerStatus
+	txList  []TransactionWithSigner
+	signed  [][]byte
+	txIDs   []string
+	results []TransactionResult
+}
+
+// GetStatus returns the phase the composer is in
+func (atc *AtomicTransactionComposer) GetStatus() AtomicTransactionComposerStatus {
+	return atc.status
+}
+
+// Count returns the number of transactions added so far
+func (atc *AtomicTransactionComposer) Count() int {
+	return len(atc.txList)
+}
+
+// Clone returns a copy of the composer in the BUILDING phase, with the group
+// id of every transaction cleared so that more transactions can be added
+func (atc *AtomicTransactionComposer) Clone() AtomicTransactionComposer {
+	newTxList := make([]TransactionWithSigner, len(atc.txList))
+	copy(newTxList, atc.txList)
+	for i := range newTxList {
+		newTxList[i].Txn.Group = types.Digest{}
+	}
+	return AtomicTransactionComposer{status: BUILDING, txList: newTxList}
+}
+
+// AddTransaction adds a transaction to the group. The composer must be in the
+// BUILDING phase, the group must have room for it and the transaction must not
+// already belong to a group.
+func (atc *AtomicTransactionComposer) AddTransaction(txnAndSigner TransactionWithSigner) error {
+	if atc.status != BUILDING {
+		return fmt.Errorf("status must be %s in order to add transactions, got %s", BUILDING, atc.status)
+	}
+	// transactions do not name their protocol version, so the group size is
+	// bounded by the one of the Latest protocol
+	if maxSize := consensus.LookupOrLatest(consensus.Latest).MaxTxGroupSize; len(atc.txList) == maxSize {
+		return fmt.Errorf("reached max group size: %d", maxSize)
+	}
+	if txnAndSigner.Signer == nil {
+		return fmt.Errorf("transaction has no signer")
+	}
+	if txnAndSigner.Txn.Group != (types.Digest{}) {
+		return fmt.Errorf("cannot add a transaction with nonzero group id")
+	}
+	atc.txList = append(atc.txList, txnAndSigner)
+	return nil
+}
+
+// BuildGroup finalizes the group, assigning the group id when it holds more
+// than one transaction. Later calls return the same group.
+func (atc *AtomicTransactionComposer) BuildGroup() ([]TransactionWithSigner, error) {
+	if atc.status > BUILDING {
+		return atc.txList, nil
+	}
+	if len(atc.txList) == 0 {
+		return nil, fmt.Errorf("attempting to build group with zero transactions")
+	}
+
+	if len(atc.txList) > 1 {
+		txns := make([]types.Transaction, len(atc.txList))
+		for i, tx := range atc.txList {
+			txns[i] = tx.Txn
+		}
+		gid, err := crypto.ComputeGroupID(txns)
+		if err != nil {
+			return nil, err
+		}
+		for i := range atc.txList {
+			atc.txList[i].Txn.Group = gid
+		}
+	}
+
+	atc.txIDs = make([]string, len(atc.txList))
+	for i, tx := range atc.txList {
+		atc.txIDs[i] = crypto.TransactionIDString(tx.Txn)
+	}
+	atc.status = BUILT
+	return atc.txList, nil
+}
+
+// GatherSignatures builds the group if needed and signs every transaction
+// with its signer. Transactions sharing a signer are signed in a single call.
+// The encoded signed transactions are returned in group order.
+func (atc *AtomicTransactionComposer) GatherSignatures() ([][]byte, error) {
+	if atc.status >= SIGNED {
+		return atc.signed, nil
+	}
+	if _, err := atc.BuildGroup(); err != nil {
+		return nil, err
+	}
+
+	txns := make([]types.Transaction, len(atc.txList))
+	for i, tx := range atc.txList {
+		txns[i] = tx.Txn
+	}
+
+	signed := make([][]byte, len(atc.txList))
+	done := make([]bool, len(atc.txList))
+	for i, tx := range atc.txList {
+		if done[i] {
+			continue
+		}
+		var indexes []int
+		for j := i; j < len(atc.txList); j++ {
+			if !done[j] && tx.Signer.Equals(atc.txList[j].Signer) {
+				indexes = append(indexes, j)
+				done[j] = true
+			}
+		}
+		stxs, err := tx.Signer.SignTransactions(txns, indexes)
+		if err != nil {
+			return nil, err
+		}
+		if len(stxs) != len(indexes) {
+			return nil, fmt.Errorf("signer returned %d signed transactions, expected %d", len(stxs), len(indexes))
+		}
+		for k, pos := range indexes {
+			if err := checkSignedTxn(stxs[k], txns[pos]); err != nil {
+				return nil, fmt.Errorf("transaction %d: %v", pos, err)
+			}
+			signed[pos] = stxs[k]
+		}
+	}
+
+	atc.signed = signed
+	atc.status = SIGNED
+	return atc.signed, nil
+}
+
+// checkSignedTxn makes sure a signer did not alter the transaction it signed
+func checkSignedTxn(stxBytes []byte, txn types.Transaction) error {
+	var stx types.SignedTxn
+	if err := msgpack.Decode(stxBytes, &stx); err != nil {
+		return err
+	}
+	if !bytes.Equal(crypto.TransactionID(stx.Txn), crypto.TransactionID(txn)) {
+		return fmt.Errorf("signer returned a different transaction")
+	}
+	return nil
+}
+
+// GetTxIDs returns the ids of the transactions of the group, once built
+func (atc *AtomicTransactionComposer) GetTxIDs() []string {
+	return atc.txIDs
+}
+
+// Submit signs the group if needed and sends it to the network without waiting
+// for confirmation. A group can only be submitted once.
+func (atc *AtomicTransactionComposer) Submit(ctx context.Context, client *algod.Client) ([]string, error) {
+	if atc.status > SIGNED {
+		return nil, fmt.Errorf("status must be %s or lower in order to submit the group, got %s", SIGNED, atc.status)
+	}
+	stxs, err := atc.GatherSignatures()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.SendRawTransaction(bytes.Join(stxs, nil)).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	atc.status = SUBMITTED
+	return atc.txIDs, nil
+}
+
+// Execute submits the group if needed and waits up to waitRounds rounds for it
+// to be committed. The result carries the confirmation of every transaction,
+// including the indexes of created applications and assets.
+func (atc *AtomicTransactionComposer) Execute(ctx context.Context, client *algod.Client, waitRounds uint64) (ExecuteResult, error) {
+	if atc.status == COMMITTED {
+		return atc.executeResult(), nil
+	}
+	if atc.status < SUBMITTED {
+		if _, err := atc.Submit(ctx, client); err != nil {
+			return ExecuteResult{}, err
+		}
+	}
+
+	results := make([]TransactionResult, len(atc.txIDs))
+	for i, txid := range atc.txIDs {
+		info, err := WaitForConfirmation(client, txid, waitRounds, ctx)
+		if err != nil {
+			return ExecuteResult{}, err
+		}
+		results[i] = TransactionResult{
+			TxID:             txid,
+			ConfirmedRound:   info.ConfirmedRound,
+			ApplicationIndex: info.ApplicationIndex,
+			AssetIndex:       info.AssetIndex,
+		}
+	}
+
+	atc.results = results
+	atc.status = COMMITTED
+	return atc.executeResult(), nil
+}
+
+func (atc *AtomicTransactionComposer) executeResult() ExecuteResult {
+	result := ExecuteResult{TxIDs: atc.txIDs, Results: atc.results}
+	if len(atc.results) > 0 {
+		result.ConfirmedRound = atc.results[0].ConfirmedRound
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package future
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var atcParams = types.SuggestedParams{
+	Fee:             0,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     byteFromBase64("SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI="),
+	FirstRoundValid: 1000,
+	LastRoundValid:  2000,
+}
+
+func TestAtomicTransactionComposerSigning(t *testing.T) {
+	alice := crypto.GenerateAccount()
+	// int 1
+	lsig, err := crypto.MakeLogicSig([]byte{0x01, 0x20, 0x01, 0x01, 0x22}, nil, nil, crypto.MultisigAccount{})
+	require.NoError(t, err)
+	contract := crypto.AddressFromProgram(lsig.Logic)
+
+	var atc AtomicTransactionComposer
+	require.Equal(t, BUILDING, atc.GetStatus())
+	for _, from := range []types.Address{alice.Address, contract, alice.Address} {
+		tx, err := MakePaymentTxn(from.String(), alice.Address.String(), 1, nil, "", atcParams)
+		require.NoError(t, err)
+		var signer TransactionSigner = BasicAccountTransactionSigner{Account: alice}
+		if from == contract {
+			signer = LogicSigTransactionSigner{LogicSig: lsig}
+		}
+		require.NoError(t, atc.AddTransaction(TransactionWithSigner{Txn: tx, Signer: signer}))
+	}
+
+	clone := atc.Clone()
+	stxs, err := atc.GatherSignatures()
+	require.NoError(t, err)
+	require.Equal(t, SIGNED, atc.GetStatus())
+	require.Len(t, stxs, 3)
+
+	group, err := atc.BuildGroup()
+	require.NoError(t, err)
+	for i, stxBytes := range stxs {
+		var stx types.SignedTxn
+		require.NoError(t, msgpack.Decode(stxBytes, &stx))
+		require.Equal(t, group[i].Txn, stx.Txn)
+		require.NotEqual(t, types.Digest{}, stx.Txn.Group)
+		require.Equal(t, atc.GetTxIDs()[i], crypto.TransactionIDString(stx.Txn))
+	}
+
+	tx, err := MakePaymentTxn(alice.Address.String(), alice.Address.String(), 1, nil, "", atcParams)
+	require.NoError(t, err)
+	require.Error(t, atc.AddTransaction(TransactionWithSigner{Txn: tx, Signer: BasicAccountTransactionSigner{Account: alice}}))
+
+	// the clone starts over from the ungrouped transactions
+	require.Equal(t, BUILDING, clone.GetStatus())
+	require.Equal(t, 3, clone.Count())
+	require.NoError(t, clone.AddTransaction(TransactionWithSigner{Txn: tx, Signer: BasicAccountTransactionSigner{Account: alice}}))
+}
+
+func TestAtomicTransactionComposerGroupSize(t *testing.T) {
+	alice := crypto.GenerateAccount()
+	tx, err := MakePaymentTxn(alice.Address.String(), alice.Address.String(), 1, nil, "", atcParams)
+	require.NoError(t, err)
+	txAndSigner := TransactionWithSigner{Txn: tx, Signer: BasicAccountTransactionSigner{Account: alice}}
+
+	var atc AtomicTransactionComposer
+	_, err = atc.BuildGroup()
+	require.Error(t, err)
+	for i := 0; i < consensus.LookupOrLatest(consensus.Latest).MaxTxGroupSize; i++ {
+		require.NoError(t, atc.AddTransaction(txAndSigner))
+	}
+	require.Error(t, atc.AddTransaction(txAndSigner))
+}
+
+func TestAtomicTransactionComposerExecute(t *testing.T) {
+	alice := crypto.GenerateAccount()
+	submitted := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/status":
+			json.NewEncoder(w).Encode(models.NodeStatus{LastRound: 1000})
+		case r.URL.Path == "/v2/transactions":
+			body, _ := ioutil.ReadAll(r.Body)
+			require.NotEmpty(t, body)
+			submitted++
+			json.NewEncoder(w).Encode(map[string]string{"txId": "unused"})
+		case strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/"):
+			w.Write(msgpack.Encode(models.PendingTransactionInfoResponse{ConfirmedRound: 1001, AssetIndex: 77}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := algod.MakeClient(server.URL, "")
+	require.NoError(t, err)
+
+	tx, err := MakeAssetCreateTxn(alice.Address.String(), nil, atcParams, 10, 0, false, "", "", "", "", "tst", "test", "", "")
+	require.NoError(t, err)
+	var atc AtomicTransactionComposer
+	require.NoError(t, atc.AddTransaction(TransactionWithSigner{Txn: tx, Signer: BasicAccountTransactionSigner{Account: alice}}))
+
+	result, err := atc.Execute(context.Background(), client, 5)
+	require.NoError(t, err)
+	require.Equal(t, COMMITTED, atc.GetStatus())
+	require.Equal(t, uint64(1001), result.ConfirmedRound)
+	require.Equal(t, uint64(77), result.Results[0].AssetIndex)
+	require.Equal(t, crypto.TransactionIDString(tx), result.Results[0].TxID)
+
+	_, err = atc.Submit(context.Background(), client)
+	require.Error(t, err)
+	require.Equal(t, 1, submitted)
+}
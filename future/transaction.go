@@ -4,13 +4,25 @@ import (
 	"encoding/base64"
 	"fmt"
 
+	"github.com/jffp113/go-algorand-sdk/consensus"
 	"github.com/jffp113/go-algorand-sdk/transaction"
 	"github.com/jffp113/go-algorand-sdk/types"
 )
 
-// MinTxnFee is v5 consensus params, in microAlgos
+// MinTxnFee is v5 consensus params, in microAlgos.
+// Transactions use the minimum fee of params.ConsensusVersion instead, see package consensus.
 const MinTxnFee = transaction.MinTxnFee
 
+// minTxnFee returns the minimum fee of the protocol version in params, which
+// must be known
+func minTxnFee(params types.SuggestedParams) (types.MicroAlgos, error) {
+	protocol, err := consensus.FromSuggestedParams(params)
+	if err != nil {
+		return 0, err
+	}
+	return types.MicroAlgos(protocol.MinTxnFee), nil
+}
+
 // MakePaymentTxn constructs a payment transaction using the passed parameters.
 // `from` and `to` addresses should be checksummed, human-readable addresses
 // fee is fee per byte as received from algod SuggestedFee API call
@@ -71,13 +83,27 @@ func MakePaymentTxn(from, to string, amount uint64, note []byte, closeRemainderT
 		tx.Fee = types.MicroAlgos(eSize * uint64(params.Fee))
 	}
 
-	if tx.Fee < MinTxnFee {
-		tx.Fee = MinTxnFee
+	minFee, err := minTxnFee(params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if tx.Fee < minFee {
+		tx.Fee = minFee
 	}
 
 	return tx, nil
 }
 
+// MakePaymentTxnWithAmount is MakePaymentTxn taking the amount as an exact
+// amount of Algos, see types.Algos and types.ParseAlgos
+func MakePaymentTxnWithAmount(from, to string, amount types.Amount, note []byte, closeRemainderTo string, params types.SuggestedParams) (types.Transaction, error) {
+	microalgos, err := amount.MicroAlgos()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	return MakePaymentTxn(from, to, uint64(microalgos), note, closeRemainderTo, params)
+}
+
 // MakeKeyRegTxn constructs a keyreg transaction using the passed parameters.
 // - account is a checksummed, human-readable address for which we register the given participation key.
 // - note is a byte array
@@ -141,8 +167,12 @@ func MakeKeyRegTxn(account string, note []byte, params types.SuggestedParams, vo
 		tx.Fee = types.MicroAlgos(eSize * uint64(params.Fee))
 	}
 
-	if tx.Fee < MinTxnFee {
-		tx.Fee = MinTxnFee
+	minFee, err := minTxnFee(params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if tx.Fee < minFee {
+		tx.Fee = minFee
 	}
 
 	return tx, nil
@@ -248,13 +278,24 @@ func MakeAssetCreateTxn(account string, note []byte, params types.SuggestedParam
 		tx.Fee = types.MicroAlgos(eSize * uint64(params.Fee))
 	}
 
-	if tx.Fee < MinTxnFee {
-		tx.Fee = MinTxnFee
+	minFee, err := minTxnFee(params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if tx.Fee < minFee {
+		tx.Fee = minFee
 	}
 
 	return tx, nil
 }
 
+// MakeAssetCreateTxnWithAmount is MakeAssetCreateTxn taking the total supply
+// and the number of decimals from total, and the unit name from
+// total.UnitName
+func MakeAssetCreateTxnWithAmount(account string, note []byte, params types.SuggestedParams, total types.Amount, defaultFrozen bool, manager, reserve, freeze, clawback string, assetName, url, metadataHash string) (types.Transaction, error) {
+	return MakeAssetCreateTxn(account, note, params, total.Units, total.Decimals, defaultFrozen, manager, reserve, freeze, clawback, total.UnitName, assetName, url, metadataHash)
+}
+
 // MakeAssetConfigTxn creates a tx template for changing the
 // key configuration of an existing asset.
 // Important notes -
@@ -338,8 +379,12 @@ func MakeAssetConfigTxn(account string, note []byte, params types.SuggestedParam
 		tx.Fee = types.MicroAlgos(eSize * uint64(params.Fee))
 	}
 
-	if tx.Fee < MinTxnFee {
-		tx.Fee = MinTxnFee
+	minFee, err := minTxnFee(params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if tx.Fee < minFee {
+		tx.Fee = minFee
 	}
 
 	return tx, nil
@@ -405,8 +450,12 @@ func transferAssetBuilder(account, recipient string, amount uint64, note []byte,
 	}
 	tx.Fee = types.MicroAlgos(eSize * uint64(params.Fee))
 
-	if tx.Fee < MinTxnFee {
-		tx.Fee = MinTxnFee
+	minFee, err := minTxnFee(params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if tx.Fee < minFee {
+		tx.Fee = minFee
 	}
 
 	return tx, nil
@@ -426,6 +475,18 @@ func MakeAssetTransferTxn(account, recipient string, amount uint64, note []byte,
 	return transferAssetBuilder(account, recipient, amount, note, params, index, closeAssetsTo, revocationTarget)
 }
 
+// MakeAssetTransferTxnWithAmount is MakeAssetTransferTxn taking the amount as
+// an exact amount of the asset with params asset, see types.AssetAmount and
+// types.ParseAssetAmount. It fails if the amount is not one of the asset, see
+// Amount.AssetUnits.
+func MakeAssetTransferTxnWithAmount(account, recipient string, amount types.Amount, asset types.AssetParams, note []byte, params types.SuggestedParams, closeAssetsTo string, index uint64) (types.Transaction, error) {
+	units, err := amount.AssetUnits(asset)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	return MakeAssetTransferTxn(account, recipient, units, note, params, closeAssetsTo, index)
+}
+
 // MakeAssetAcceptanceTxn creates a tx for marking an account as willing to accept the given asset
 // - account is a checksummed, human-readable address that will send the transaction and begin accepting the asset
 // - note is an arbitrary byte array
@@ -447,6 +508,17 @@ func MakeAssetRevocationTxn(account, target string, amount uint64, recipient str
 	return transferAssetBuilder(account, recipient, amount, note, params, index, closeAssetsTo, target)
 }
 
+// MakeAssetRevocationTxnWithAmount is MakeAssetRevocationTxn taking the
+// amount as an exact amount of the asset with params asset. It fails if the
+// amount is not one of the asset, see Amount.AssetUnits.
+func MakeAssetRevocationTxnWithAmount(account, target string, amount types.Amount, asset types.AssetParams, recipient string, note []byte, params types.SuggestedParams, index uint64) (types.Transaction, error) {
+	units, err := amount.AssetUnits(asset)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	return MakeAssetRevocationTxn(account, target, units, recipient, note, params, index)
+}
+
 // MakeAssetDestroyTxn creates a tx template for destroying an asset, removing it from the record.
 // All outstanding asset amount must be held by the creator, and this transaction must be issued by the asset manager.
 // - account is a checksummed, human-readable address that will send the transaction; it also must be the asset manager
@@ -509,8 +581,12 @@ func MakeAssetFreezeTxn(account string, note []byte, params types.SuggestedParam
 		tx.Fee = types.MicroAlgos(eSize * uint64(params.Fee))
 	}
 
-	if tx.Fee < MinTxnFee {
-		tx.Fee = MinTxnFee
+	minFee, err := minTxnFee(params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if tx.Fee < minFee {
+		tx.Fee = minFee
 	}
 
 	return tx, nil
@@ -882,8 +958,12 @@ func setApplicationTransactionFields(
 		applicationTransaction.Fee = types.MicroAlgos(eSize * uint64(sp.Fee))
 	}
 
-	if applicationTransaction.Fee < MinTxnFee {
-		applicationTransaction.Fee = MinTxnFee
+	minFee, err := minTxnFee(sp)
+	if err != nil {
+		return err
+	}
+	if applicationTransaction.Fee < minFee {
+		applicationTransaction.Fee = minFee
 	}
 	return nil
 }
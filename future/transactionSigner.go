@@ -0,0 +1,131 @@
+package future
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// TransactionSigner signs some of the transactions of a group. It returns the
+// encoded SignedTxn of every transaction in indexesToSign, in the same order.
+type TransactionSigner interface {
+	SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error)
+
+	// Equals reports whether other signs with the same credentials
+	Equals(other TransactionSigner) bool
+}
+
+// BasicAccountTransactionSigner signs transactions with the key of a single account
+type BasicAccountTransactionSigner struct {
+	Account crypto.Account
+}
+
+// SignTransactions signs the requested transactions with the account private key.
+// AuthAddr is set by crypto.SignTransaction when the sender was rekeyed to this account.
+func (s BasicAccountTransactionSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	stxs := make([][]byte, len(indexesToSign))
+	for i, pos := range indexesToSign {
+		_, stxBytes, err := crypto.SignTransaction(s.Account.PrivateKey, txGroup[pos])
+		if err != nil {
+			return nil, err
+		}
+		stxs[i] = stxBytes
+	}
+	return stxs, nil
+}
+
+// Equals implements TransactionSigner
+func (s BasicAccountTransactionSigner) Equals(other TransactionSigner) bool {
+	if o, ok := other.(BasicAccountTransactionSigner); ok {
+		return s.Account.Address == o.Account.Address
+	}
+	return false
+}
+
+// LogicSigTransactionSigner attaches a LogicSig to transactions. The LogicSig
+// may be either a contract account or a signed delegation of the sender.
+type LogicSigTransactionSigner struct {
+	LogicSig types.LogicSig
+}
+
+// SignTransactions attaches the LogicSig to the requested transactions
+func (s LogicSigTransactionSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	stxs := make([][]byte, len(indexesToSign))
+	for i, pos := range indexesToSign {
+		_, stxBytes, err := crypto.SignLogicsigTransaction(s.LogicSig, txGroup[pos])
+		if err != nil {
+			return nil, err
+		}
+		stxs[i] = stxBytes
+	}
+	return stxs, nil
+}
+
+// Equals implements TransactionSigner
+func (s LogicSigTransactionSigner) Equals(other TransactionSigner) bool {
+	if o, ok := other.(LogicSigTransactionSigner); ok {
+		return bytes.Equal(msgpack.Encode(s.LogicSig), msgpack.Encode(o.LogicSig))
+	}
+	return false
+}
+
+// MultiSigAccountTransactionSigner signs transactions of a multisig account
+// with every one of the passed private keys
+type MultiSigAccountTransactionSigner struct {
+	Msig crypto.MultisigAccount
+	Sks  [][]byte
+}
+
+// SignTransactions produces, for every requested transaction, a multisig
+// signature merged from the signatures of all the keys in Sks
+func (s MultiSigAccountTransactionSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+	if len(s.Sks) == 0 {
+		return nil, fmt.Errorf("multisig signer has no keys")
+	}
+	stxs := make([][]byte, len(indexesToSign))
+	for i, pos := range indexesToSign {
+		var partials [][]byte
+		for _, sk := range s.Sks {
+			_, stxBytes, err := crypto.SignMultisigTransaction(sk, s.Msig, txGroup[pos])
+			if err != nil {
+				return nil, err
+			}
+			partials = append(partials, stxBytes)
+		}
+		if len(partials) == 1 {
+			stxs[i] = partials[0]
+			continue
+		}
+		_, merged, err := crypto.MergeMultisigTransactions(partials...)
+		if err != nil {
+			return nil, err
+		}
+		stxs[i] = merged
+	}
+	return stxs, nil
+}
+
+// Equals implements TransactionSigner
+func (s MultiSigAccountTransactionSigner) Equals(other TransactionSigner) bool {
+	o, ok := other.(MultiSigAccountTransactionSigner)
+	if !ok || len(s.Sks) != len(o.Sks) {
+		return false
+	}
+	addr, err := s.Msig.Address()
+	if err != nil {
+		return false
+	}
+	otherAddr, err := o.Msig.Address()
+	if err != nil || addr != otherAddr {
+		return false
+	}
+	for i := range s.Sks {
+		if !bytes.Equal(s.Sks[i], o.Sks[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -68,6 +68,22 @@ func TestMakePaymentTxn2(t *testing.T) {
 	require.Error(t, err)
 }
 
+// should fail on a consensus version unknown to the SDK
+func TestMakePaymentTxnUnknownVersion(t *testing.T) {
+	const fromAddress = "47YPQTIGQEO7T4Y4RWDYWEKV6RTR2UNBQXBABEEGM72ESWDQNCQ52OPASU"
+	const toAddress = "PNWOET7LLOWMBMLE4KOCELCX6X3D3Q4H2Q4QJASYIEOF7YIPPQBG3YQ5YI"
+	params := types.SuggestedParams{
+		Fee:              4,
+		FirstRoundValid:  12466,
+		LastRoundValid:   13466,
+		GenesisID:        "devnet-v33.0",
+		GenesisHash:      byteFromBase64("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+		ConsensusVersion: "https://example.com/unknown",
+	}
+	_, err := MakePaymentTxn(fromAddress, toAddress, 1000, nil, "", params)
+	require.Error(t, err)
+}
+
 func TestMakePaymentTxnWithLease(t *testing.T) {
 	const fromAddress = "47YPQTIGQEO7T4Y4RWDYWEKV6RTR2UNBQXBABEEGM72ESWDQNCQ52OPASU"
 	const toAddress = "PNWOET7LLOWMBMLE4KOCELCX6X3D3Q4H2Q4QJASYIEOF7YIPPQBG3YQ5YI"
@@ -727,3 +743,50 @@ func TestLogicSig(t *testing.T) {
 	require.True(t, verified)
 
 }
+
+func TestMakeTxnWithAmount(t *testing.T) {
+	const fromAddress = "47YPQTIGQEO7T4Y4RWDYWEKV6RTR2UNBQXBABEEGM72ESWDQNCQ52OPASU"
+	const toAddress = "PNWOET7LLOWMBMLE4KOCELCX6X3D3Q4H2Q4QJASYIEOF7YIPPQBG3YQ5YI"
+	params := types.SuggestedParams{
+		Fee:             4,
+		FirstRoundValid: 12466,
+		LastRoundValid:  13466,
+		GenesisID:       "devnet-v33.0",
+		GenesisHash:     byteFromBase64("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	}
+
+	algos, err := types.ParseAlgos("12.345678")
+	require.NoError(t, err)
+	txn, err := MakePaymentTxnWithAmount(fromAddress, toAddress, algos, nil, "", params)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(12345678), txn.Amount)
+
+	assetParams := types.AssetParams{Decimals: 2, UnitName: "USDC"}
+	_, err = MakePaymentTxnWithAmount(fromAddress, toAddress, types.AssetAmount(1, assetParams), nil, "", params)
+	require.Error(t, err)
+
+	usdc, err := types.ParseAssetAmount("3.25", assetParams)
+	require.NoError(t, err)
+	txn, err = MakeAssetTransferTxnWithAmount(fromAddress, toAddress, usdc, assetParams, nil, params, "", 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(325), txn.AssetAmount)
+	txn, err = MakeAssetRevocationTxnWithAmount(fromAddress, toAddress, usdc, assetParams, fromAddress, nil, params, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(325), txn.AssetAmount)
+
+	// amounts with other decimals or of another unit are refused
+	cents, err := types.ParseAmount("325", 0)
+	require.NoError(t, err)
+	_, err = MakeAssetTransferTxnWithAmount(fromAddress, toAddress, cents, assetParams, nil, params, "", 10)
+	require.Error(t, err)
+	_, err = MakeAssetRevocationTxnWithAmount(fromAddress, toAddress, algos, types.AssetParams{Decimals: 6, UnitName: "USDT"}, fromAddress, nil, params, 10)
+	require.Error(t, err)
+
+	total, err := types.ParseAssetAmount("1000000 USDC", assetParams)
+	require.NoError(t, err)
+	txn, err = MakeAssetCreateTxnWithAmount(fromAddress, nil, params, total, false, "", "", "", "", "USD Coin", "", "")
+	require.NoError(t, err)
+	require.Equal(t, uint64(100000000), txn.AssetParams.Total)
+	require.Equal(t, uint32(2), txn.AssetParams.Decimals)
+	require.Equal(t, "USDC", txn.AssetParams.UnitName)
+}
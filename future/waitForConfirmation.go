@@ -0,0 +1,46 @@
+package future
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+)
+
+// WaitForConfirmation waits for a pending transaction to be accepted by the network.
+// waitRounds is the number of rounds to wait before giving up; 0 waits until ctx is done.
+func WaitForConfirmation(c *algod.Client, txid string, waitRounds uint64, ctx context.Context) (models.PendingTransactionInfoResponse, error) {
+	status, err := c.Status().Do(ctx)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+
+	startRound := status.LastRound + 1
+	currentRound := startRound
+	for {
+		if err := ctx.Err(); err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+
+		info, _, err := c.PendingTransactionInformation(txid).Do(ctx)
+		if err == nil {
+			if info.ConfirmedRound > 0 {
+				return info, nil
+			}
+			if info.PoolError != "" {
+				return info, fmt.Errorf("transaction %s was rejected: %s", txid, info.PoolError)
+			}
+		}
+
+		if waitRounds > 0 && currentRound >= startRound+waitRounds {
+			return models.PendingTransactionInfoResponse{}, fmt.Errorf("transaction %s not confirmed after %d rounds", txid, waitRounds)
+		}
+
+		_, err = c.StatusAfterBlock(currentRound).Do(ctx)
+		if err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+		currentRound++
+	}
+}
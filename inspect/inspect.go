@@ -0,0 +1,325 @@
+// Package inspect renders the msgpack types of the SDK, such as Transaction,
+// SignedTxn, LogicSig and Block, as human-readable JSON and parses that JSON
+// back without losing information.
+//
+// Objects use the same short field names as the msgpack encoding and, like
+// goal clerk inspect, empty fields are omitted. Addresses are rendered as
+// checksummed strings, byte strings, digests and keys as base64, and every
+// amount in microAlgos is annotated with a sibling "<field>:algos" string
+// holding its value in Algos. Annotations are ignored when parsing.
+package inspect
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// annotationSuffix marks keys that annotate the field they are named after
+const annotationSuffix = ":algos"
+
+var (
+	addressType    = reflect.TypeOf(types.Address{})
+	microAlgosType = reflect.TypeOf(types.MicroAlgos(0))
+)
+
+// Encode renders v as compact canonical JSON: keys are sorted and empty fields omitted
+func Encode(v interface{}) ([]byte, error) {
+	obj, err := toJSON(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// EncodeIndent is like Encode but indents the output for reading
+func EncodeIndent(v interface{}) ([]byte, error) {
+	obj, err := toJSON(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(obj, "", "  ")
+}
+
+// Decode parses JSON produced by Encode into the object pointed to by objptr.
+// Unknown fields are rejected.
+func Decode(data []byte, objptr interface{}) error {
+	ptr := reflect.ValueOf(objptr)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", objptr)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return err
+	}
+	return fromJSON(obj, ptr.Elem(), "")
+}
+
+// field is a struct field as seen by the msgpack encoding
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// fields lists the encoded fields of a struct type, flattening embedded
+// structs the way the codec does
+func fields(t reflect.Type) []field {
+	omitEmpty := false
+	if f, ok := t.FieldByName("_struct"); ok {
+		omitEmpty = strings.Contains(f.Tag.Get("codec"), "omitempty")
+	}
+
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "_struct" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("codec"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for _, inner := range fields(f.Type) {
+				inner.index = append([]int{i}, inner.index...)
+				result = append(result, inner)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		result = append(result, field{name: name, index: []int{i}, omitEmpty: omitEmpty})
+	}
+	return result
+}
+
+func isBytes(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Struct:
+		for _, f := range fields(v.Type()) {
+			if !isEmpty(v.FieldByIndex(f.index)) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.IsZero()
+}
+
+func toJSON(v reflect.Value) (interface{}, error) {
+	t := v.Type()
+	switch {
+	case t == addressType:
+		return v.Interface().(types.Address).String(), nil
+	case isBytes(t):
+		if t.Kind() == reflect.Array {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return base64.StdEncoding.EncodeToString(b), nil
+		}
+		return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return toJSON(v.Elem())
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return json.Number(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			elem, err := toJSON(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return list, nil
+	case reflect.Struct:
+		obj := make(map[string]interface{})
+		for _, f := range fields(t) {
+			fv := v.FieldByIndex(f.index)
+			if f.omitEmpty && isEmpty(fv) {
+				continue
+			}
+			value, err := toJSON(fv)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", f.name, err)
+			}
+			obj[f.name] = value
+			if fv.Type() == microAlgosType {
+				obj[f.name+annotationSuffix] = strconv.FormatFloat(fv.Interface().(types.MicroAlgos).ToAlgos(), 'f', 6, 64)
+			}
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("cannot inspect values of type %s", t)
+}
+
+func fromJSON(obj interface{}, v reflect.Value, path string) error {
+	t := v.Type()
+	fail := func(format string, args ...interface{}) error {
+		if path == "" {
+			return fmt.Errorf(format, args...)
+		}
+		return fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...))
+	}
+
+	if obj == nil {
+		v.Set(reflect.Zero(t))
+		return nil
+	}
+
+	switch {
+	case t == addressType:
+		s, ok := obj.(string)
+		if !ok {
+			return fail("expected an address, got %T", obj)
+		}
+		addr, err := types.DecodeAddress(s)
+		if err != nil {
+			return fail("%v", err)
+		}
+		v.Set(reflect.ValueOf(addr))
+		return nil
+	case isBytes(t):
+		s, ok := obj.(string)
+		if !ok {
+			return fail("expected a base64 string, got %T", obj)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if t.Kind() == reflect.Array {
+			if len(b) != t.Len() {
+				return fail("expected %d bytes, got %d", t.Len(), len(b))
+			}
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+		if len(b) == 0 {
+			// empty and missing byte strings encode the same way
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		v.Set(reflect.ValueOf(b).Convert(t))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(t.Elem())
+		if err := fromJSON(obj, elem.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Bool:
+		b, ok := obj.(bool)
+		if !ok {
+			return fail("expected a boolean, got %T", obj)
+		}
+		v.SetBool(b)
+	case reflect.String:
+		s, ok := obj.(string)
+		if !ok {
+			return fail("expected a string, got %T", obj)
+		}
+		v.SetString(s)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := obj.(json.Number)
+		if !ok {
+			return fail("expected a number, got %T", obj)
+		}
+		u, err := strconv.ParseUint(n.String(), 10, t.Bits())
+		if err != nil {
+			return fail("%v", err)
+		}
+		v.SetUint(u)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := obj.(json.Number)
+		if !ok {
+			return fail("expected a number, got %T", obj)
+		}
+		i, err := strconv.ParseInt(n.String(), 10, t.Bits())
+		if err != nil {
+			return fail("%v", err)
+		}
+		v.SetInt(i)
+	case reflect.Slice, reflect.Array:
+		list, ok := obj.([]interface{})
+		if !ok {
+			return fail("expected an array, got %T", obj)
+		}
+		if t.Kind() == reflect.Array {
+			if len(list) != t.Len() {
+				return fail("expected %d elements, got %d", t.Len(), len(list))
+			}
+		} else if len(list) == 0 {
+			v.Set(reflect.Zero(t))
+		} else {
+			v.Set(reflect.MakeSlice(t, len(list), len(list)))
+		}
+		for i, elem := range list {
+			if err := fromJSON(elem, v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			return fail("expected an object, got %T", obj)
+		}
+		byName := make(map[string]field)
+		for _, f := range fields(t) {
+			byName[f.name] = f
+		}
+		for key, value := range m {
+			if strings.HasSuffix(key, annotationSuffix) {
+				continue
+			}
+			f, ok := byName[key]
+			if !ok {
+				return fail("unknown field %q", key)
+			}
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if err := fromJSON(value, v.FieldByIndex(f.index), fieldPath); err != nil {
+				return err
+			}
+		}
+	default:
+		return fail("cannot parse values of type %s", t)
+	}
+	return nil
+}
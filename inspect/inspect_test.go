@@ -0,0 +1,91 @@
+package inspect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var testParams = types.SuggestedParams{
+	Fee:             1000,
+	FlatFee:         true,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     []byte("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	FirstRoundValid: 100,
+	LastRoundValid:  1100,
+}
+
+func TestSignedTxnRoundTrip(t *testing.T) {
+	accts := []crypto.Account{crypto.GenerateAccount(), crypto.GenerateAccount()}
+	ma := crypto.MultisigAccount{Version: 1, Threshold: 1, Pks: []ed25519.PublicKey{accts[0].PublicKey, accts[1].PublicKey}}
+	from, err := ma.Address()
+	require.NoError(t, err)
+	tx, err := future.MakePaymentTxn(from.String(), accts[1].Address.String(), 2500000, []byte("hello"), "", testParams)
+	require.NoError(t, err)
+	tx.AddLease([32]byte{7}, 0)
+	_, stxBytes, err := crypto.SignMultisigTransaction(accts[0].PrivateKey, ma, tx)
+	require.NoError(t, err)
+	var stx types.SignedTxn
+	require.NoError(t, msgpack.Decode(stxBytes, &stx))
+
+	rendered, err := EncodeIndent(stx)
+	require.NoError(t, err)
+
+	var generic map[string]interface{}
+	require.NoError(t, json.Unmarshal(rendered, &generic))
+	txn := generic["txn"].(map[string]interface{})
+	require.Equal(t, from.String(), txn["snd"])
+	require.Equal(t, accts[1].Address.String(), txn["rcv"])
+	require.Equal(t, "2.500000", txn["amt:algos"])
+	require.Equal(t, "aGVsbG8=", txn["note"])
+	require.NotContains(t, txn, "close")
+
+	var parsed types.SignedTxn
+	require.NoError(t, Decode(rendered, &parsed))
+	require.Equal(t, stxBytes, msgpack.Encode(parsed))
+}
+
+func TestLogicSigAndBlockRoundTrip(t *testing.T) {
+	lsig, err := crypto.MakeLogicSig([]byte{0x01, 0x20, 0x01, 0x01, 0x22}, [][]byte{{1, 2}}, nil, crypto.MultisigAccount{})
+	require.NoError(t, err)
+	rendered, err := Encode(lsig)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"l":"ASABASI=","arg":["AQI="]}`, string(rendered))
+	var parsedLsig types.LogicSig
+	require.NoError(t, Decode(rendered, &parsedLsig))
+	require.Equal(t, lsig, parsedLsig)
+
+	sender := crypto.GenerateAccount()
+	tx, err := future.MakeAssetAcceptanceTxn(sender.Address.String(), nil, testParams, 12)
+	require.NoError(t, err)
+	var block types.Block
+	block.Round = 1000
+	block.TimeStamp = -1
+	block.CurrentProtocol = "future"
+	block.FeeSink = sender.Address
+	block.Payset = types.Payset{{SignedTxnWithAD: types.SignedTxnWithAD{
+		SignedTxn: types.SignedTxn{Txn: tx, Sig: types.Signature{1}},
+		ApplyData: types.ApplyData{SenderRewards: 12},
+	}, HasGenesisID: true}}
+
+	rendered, err = Encode(block)
+	require.NoError(t, err)
+	var parsedBlock types.Block
+	require.NoError(t, Decode(rendered, &parsedBlock))
+	require.Equal(t, msgpack.Encode(block), msgpack.Encode(parsedBlock))
+}
+
+func TestDecodeRejectsBadInput(t *testing.T) {
+	var stx types.SignedTxn
+	require.Error(t, Decode([]byte(`{"txn":{"unknown":1}}`), &stx))
+	require.Error(t, Decode([]byte(`{"txn":{"snd":"NOTANADDRESS"}}`), &stx))
+	require.Error(t, Decode([]byte(`{"sig":"AQI="}`), &stx))
+	require.Error(t, Decode([]byte(`{}`), stx))
+}
@@ -0,0 +1,202 @@
+// Package lease derives transaction leases from application-defined keys and
+// tracks the leases a service holds, so that conflicting transactions are
+// never submitted.
+//
+// A lease makes transactions mutually exclusive: once a transaction with
+// lease L from sender S is confirmed, no other transaction with the same
+// (S, L) pair can be confirmed until its LastValid round has passed. Deriving
+// L from a business key, such as an invoice number, gives exactly-once
+// semantics: resubmitting a payment for the same invoice within the validity
+// window can never pay twice.
+package lease
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/transaction"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// leasePrefix is prepended to the namespace and key when deriving a lease
+var leasePrefix = []byte("LX")
+
+var errNoLease = errors.New("transaction has no lease")
+
+// Derive returns the lease for key within namespace. Namespaces keep the keys
+// of different applications from producing the same lease.
+func Derive(namespace string, key []byte) [32]byte {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(namespace)))
+
+	toHash := append([]byte{}, leasePrefix...)
+	toHash = append(toHash, length[:n]...)
+	toHash = append(toHash, namespace...)
+	toHash = append(toHash, key...)
+	return sha512.Sum512_256(toHash)
+}
+
+// Apply sets the lease derived from namespace and key on txn. Unless params
+// has a flat fee, the fee is raised to pay for the size of the transaction
+// with its lease, see transaction.AssignFee; a fee already higher is kept.
+// Applying the same lease again leaves the transaction unchanged.
+func Apply(txn *types.Transaction, namespace string, key []byte, params types.SuggestedParams) error {
+	txn.Lease = Derive(namespace, key)
+	if params.FlatFee {
+		return nil
+	}
+	priced := *txn
+	if err := transaction.AssignFee(&priced, params, transaction.SignatureShape{}); err != nil {
+		return err
+	}
+	if priced.Fee > txn.Fee {
+		txn.Fee = priced.Fee
+	}
+	return nil
+}
+
+// Holder is the transaction holding a lease
+type Holder struct {
+	TxID      string
+	LastValid types.Round
+}
+
+// ConflictError is returned when a lease is held by another transaction
+type ConflictError struct {
+	Sender types.Address
+	Lease  [32]byte
+	Holder Holder
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("lease of %s is held by transaction %s until round %d", e.Sender, e.Holder.TxID, e.Holder.LastValid)
+}
+
+type entryKey struct {
+	sender types.Address
+	lease  [32]byte
+}
+
+// Registry tracks the (sender, lease) pairs held by transactions a service
+// submitted, until their LastValid round has passed. It is safe for
+// concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	round   types.Round
+	holders map[entryKey][]Holder
+}
+
+// MakeRegistry returns an empty Registry
+func MakeRegistry() *Registry {
+	return &Registry{holders: make(map[entryKey][]Holder)}
+}
+
+// Acquire records that txn holds its lease. It fails with a *ConflictError
+// when another transaction with an overlapping validity window still holds
+// the lease. Acquiring again for the same transaction succeeds, so that it can
+// be resubmitted safely.
+func (r *Registry) Acquire(txn types.Transaction) error {
+	if txn.Lease == ([32]byte{}) {
+		return errNoLease
+	}
+	key := entryKey{sender: txn.Sender, lease: txn.Lease}
+	txid := crypto.TransactionIDString(txn)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, holder := range r.holders[key] {
+		if holder.TxID == txid {
+			return nil
+		}
+	}
+	for _, holder := range r.holders[key] {
+		// a transaction whose validity window starts after the lease
+		// expires cannot conflict with the holder
+		if holder.LastValid >= r.round && txn.FirstValid <= holder.LastValid {
+			return &ConflictError{Sender: txn.Sender, Lease: txn.Lease, Holder: holder}
+		}
+	}
+	r.holders[key] = append(r.holders[key], Holder{TxID: txid, LastValid: txn.LastValid})
+	return nil
+}
+
+// Claim applies the lease derived from namespace and key to txn and acquires
+// it. This is the building block of exactly-once payments: build the payment,
+// Claim it with the business key, sign and submit it, and on retries resubmit
+// the same signed transaction until its LastValid round has passed.
+func (r *Registry) Claim(txn *types.Transaction, namespace string, key []byte, params types.SuggestedParams) error {
+	if err := Apply(txn, namespace, key, params); err != nil {
+		return err
+	}
+	return r.Acquire(*txn)
+}
+
+// Release forgets the lease held by txn, for instance after its submission
+// was rejected. Only the holder can release a lease.
+func (r *Registry) Release(txn types.Transaction) {
+	key := entryKey{sender: txn.Sender, lease: txn.Lease}
+	txid := crypto.TransactionIDString(txn)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.holders[key] = removeHolders(r.holders[key], func(holder Holder) bool { return holder.TxID == txid })
+	if len(r.holders[key]) == 0 {
+		delete(r.holders, key)
+	}
+}
+
+// Holder returns the transaction currently holding the lease of sender, if any
+func (r *Registry) Holder(sender types.Address, lease [32]byte) (Holder, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, holder := range r.holders[entryKey{sender: sender, lease: lease}] {
+		if holder.LastValid >= r.round {
+			return holder, true
+		}
+	}
+	return Holder{}, false
+}
+
+// Advance tells the registry the network reached round, releasing the leases
+// of every transaction whose LastValid is before round
+func (r *Registry) Advance(round types.Round) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if round <= r.round {
+		return
+	}
+	r.round = round
+	for key, holders := range r.holders {
+		holders = removeHolders(holders, func(holder Holder) bool { return holder.LastValid < round })
+		if len(holders) == 0 {
+			delete(r.holders, key)
+		} else {
+			r.holders[key] = holders
+		}
+	}
+}
+
+// Len returns the number of transactions currently holding a lease
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, holders := range r.holders {
+		n += len(holders)
+	}
+	return n
+}
+
+func removeHolders(holders []Holder, remove func(Holder) bool) []Holder {
+	kept := holders[:0]
+	for _, holder := range holders {
+		if !remove(holder) {
+			kept = append(kept, holder)
+		}
+	}
+	return kept
+}
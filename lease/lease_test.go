@@ -0,0 +1,126 @@
+package lease
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/templates"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var testParams = types.SuggestedParams{
+	Fee:             1000,
+	FlatFee:         true,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     []byte("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	FirstRoundValid: 100,
+	LastRoundValid:  1100,
+}
+
+func TestDerive(t *testing.T) {
+	require.Equal(t, Derive("payroll", []byte("invoice-1")), Derive("payroll", []byte("invoice-1")))
+	require.NotEqual(t, Derive("payroll", []byte("invoice-1")), Derive("payroll", []byte("invoice-2")))
+	// the namespace length is part of the hash
+	require.NotEqual(t, Derive("pay", []byte("roll")), Derive("payroll", nil))
+
+	lease := Derive("payroll", []byte("invoice-1"))
+	contract, err := templates.MakePeriodicPaymentWithLease("SKXZDBHECM6AS73GVPGJHMIRDMJKEAN5TUGMUPSKJCQ44E6M6TC2H2UJ3I", lease, 500000, 95, 100, 2445756, 1000)
+	require.NoError(t, err)
+	require.NotEmpty(t, contract.GetAddress())
+}
+
+func TestExactlyOncePayment(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	receiver := crypto.GenerateAccount()
+	registry := MakeRegistry()
+
+	pay, err := future.MakePaymentTxn(sender.Address.String(), receiver.Address.String(), 100, nil, "", testParams)
+	require.NoError(t, err)
+	require.NoError(t, registry.Claim(&pay, "payroll", []byte("invoice-1"), testParams))
+	require.NoError(t, registry.Acquire(pay), "resubmitting the same transaction is allowed")
+
+	// a second payment for the same invoice conflicts, even with another amount
+	again, err := future.MakePaymentTxn(sender.Address.String(), receiver.Address.String(), 200, nil, "", testParams)
+	require.NoError(t, err)
+	err = registry.Claim(&again, "payroll", []byte("invoice-1"), testParams)
+	conflict, ok := err.(*ConflictError)
+	require.True(t, ok)
+	require.Equal(t, crypto.TransactionIDString(pay), conflict.Holder.TxID)
+
+	// another invoice, or another sender, is independent
+	other, err := future.MakePaymentTxn(sender.Address.String(), receiver.Address.String(), 200, nil, "", testParams)
+	require.NoError(t, err)
+	require.NoError(t, registry.Claim(&other, "payroll", []byte("invoice-2"), testParams))
+	require.Equal(t, 2, registry.Len())
+
+	// once the holder expires the lease is free again
+	registry.Advance(1101)
+	_, held := registry.Holder(pay.Sender, pay.Lease)
+	require.False(t, held)
+	require.Equal(t, 0, registry.Len())
+	again.FirstValid, again.LastValid = 1101, 2101
+	require.NoError(t, registry.Acquire(again))
+
+	registry.Release(again)
+	require.Equal(t, 0, registry.Len())
+
+	var unleased types.Transaction
+	require.Error(t, registry.Acquire(unleased))
+}
+
+func TestWindowsAfterExpiryDoNotConflict(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	registry := MakeRegistry()
+	first, err := future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+	require.NoError(t, registry.Claim(&first, "ns", []byte("k"), testParams))
+
+	later := testParams
+	later.FirstRoundValid, later.LastRoundValid = 1101, 2000
+	second, err := future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", later)
+	require.NoError(t, err)
+	require.NoError(t, registry.Claim(&second, "ns", []byte("k"), later))
+	require.Equal(t, 2, registry.Len())
+
+	// the first holder still blocks transactions overlapping its window
+	third, err := future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 2, nil, "", testParams)
+	require.NoError(t, err)
+	require.Error(t, registry.Claim(&third, "ns", []byte("k"), testParams))
+}
+
+func TestApplyIsIdempotent(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	perByte := testParams
+	perByte.FlatFee, perByte.Fee = false, 10
+
+	pay, err := future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", perByte)
+	require.NoError(t, err)
+	require.NoError(t, Apply(&pay, "payroll", []byte("invoice-1"), perByte))
+	_, signed, err := crypto.SignTransaction(sender.PrivateKey, pay)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(10*len(signed)), pay.Fee)
+
+	applied := pay
+	require.NoError(t, Apply(&applied, "payroll", []byte("invoice-1"), perByte))
+	require.Equal(t, pay, applied)
+
+	// a fee at the minimum stays at the minimum
+	perByte.Fee = 1
+	pay, err = future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", perByte)
+	require.NoError(t, err)
+	require.NoError(t, Apply(&pay, "payroll", []byte("invoice-1"), perByte))
+	require.NoError(t, Apply(&pay, "payroll", []byte("invoice-1"), perByte))
+	require.Equal(t, types.MicroAlgos(1000), pay.Fee)
+
+	// a flat fee is left as is
+	flat := testParams
+	flat.Fee = 2500
+	pay, err = future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", flat)
+	require.NoError(t, err)
+	require.NoError(t, Apply(&pay, "payroll", []byte("invoice-1"), flat))
+	require.Equal(t, types.MicroAlgos(2500), pay.Fee)
+	require.Equal(t, Derive("payroll", []byte("invoice-1")), pay.Lease)
+}
@@ -0,0 +1,229 @@
+// Package message provides typed, domain-separated signing of arbitrary
+// application data, suitable for off-chain authentication such as
+// "sign in with Algorand".
+//
+// A Message wraps the canonical msgpack encoding of an application-defined
+// Go value together with a domain tag naming the application, a type tag,
+// an optional expiry round and the address claiming to sign it. Messages can
+// be signed by single keys, multisig accounts and LogicSig-delegated keys,
+// and Verify recovers the signer address.
+package message
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// messagePrefix is prepended to an encoded Message when signing it. It keeps
+// message signatures apart from transactions ("TX"), SignBytes ("MX") and
+// TealSign ("ProgData").
+var messagePrefix = []byte("AMsg")
+
+var errEmptyDomain = errors.New("message domain cannot be empty")
+var errDomainMismatch = errors.New("message domain does not match the expected domain")
+var errExpired = errors.New("message has expired")
+var errSignerMismatch = errors.New("signing key does not match the message signer")
+var errNoSignature = errors.New("message is not signed")
+var errTooManySignatures = errors.New("message must carry exactly one kind of signature")
+var errInvalidSignature = errors.New("invalid message signature")
+var errDelegateNotInProgram = errors.New("delegated logic does not reference the delegate key")
+
+// Message is the structure that is actually signed
+type Message struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// Domain is the application-defined domain tag, e.g. "example.com/login"
+	Domain string `codec:"dom"`
+
+	// Type names the kind of payload, e.g. "LoginRequest"
+	Type string `codec:"type"`
+
+	// Payload is the canonical msgpack encoding of the application value
+	Payload []byte `codec:"pl"`
+
+	// Expiry is the last round at which the message is valid; zero means no expiry
+	Expiry types.Round `codec:"exp"`
+
+	// Signer is the address that claims to sign the message
+	Signer types.Address `codec:"snd"`
+
+	// Delegate is the key signing on behalf of Signer through a delegated LogicSig
+	Delegate ed25519.PublicKey `codec:"dlg"`
+}
+
+// SignedMessage wraps a Message and its signature. Exactly one of Sig, Msig
+// or Lsig identifies how the message was signed.
+type SignedMessage struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Msg  Message           `codec:"msg"`
+	Sig  types.Signature   `codec:"sig"`
+	Msig types.MultisigSig `codec:"msig"`
+
+	// Lsig is the LogicSig delegated by Signer; when present, Sig is the
+	// TealSign signature of Delegate over the message
+	Lsig types.LogicSig `codec:"lsig"`
+}
+
+// NewMessage builds a Message holding the canonical encoding of payload
+func NewMessage(domain, msgType string, payload interface{}, expiry types.Round, signer types.Address) (msg Message, err error) {
+	if domain == "" {
+		err = errEmptyDomain
+		return
+	}
+	msg.Domain = domain
+	msg.Type = msgType
+	msg.Payload = msgpack.Encode(payload)
+	msg.Expiry = expiry
+	msg.Signer = signer
+	return
+}
+
+// DecodePayload decodes the payload of the message into the object pointed to by objptr
+func (msg Message) DecodePayload(objptr interface{}) error {
+	return msgpack.Decode(msg.Payload, objptr)
+}
+
+// BytesToSign returns the prefixed encoding of the message that signers sign
+func (msg Message) BytesToSign() []byte {
+	return bytes.Join([][]byte{messagePrefix, msgpack.Encode(msg)}, nil)
+}
+
+// Sign signs the message with sk, whose address must be the message signer
+func Sign(sk ed25519.PrivateKey, msg Message) (sm SignedMessage, err error) {
+	addr, err := crypto.GenerateAddressFromSK(sk)
+	if err != nil {
+		return
+	}
+	if addr != msg.Signer {
+		err = errSignerMismatch
+		return
+	}
+	sm.Msg = msg
+	copy(sm.Sig[:], ed25519.Sign(sk, msg.BytesToSign()))
+	return
+}
+
+// SignMultisig adds the signature of sk to a message signed by the multisig account ma.
+// Pass a zero SignedMessage holding only Msg to start collecting signatures.
+func SignMultisig(sk ed25519.PrivateKey, ma crypto.MultisigAccount, sm SignedMessage) (SignedMessage, error) {
+	addr, err := ma.Address()
+	if err != nil {
+		return sm, err
+	}
+	if addr != sm.Msg.Signer {
+		return sm, errSignerMismatch
+	}
+
+	myIndex := -1
+	myPublicKey := sk.Public().(ed25519.PublicKey)
+	for i := range ma.Pks {
+		if bytes.Equal(myPublicKey, ma.Pks[i]) {
+			myIndex = i
+		}
+	}
+	if myIndex < 0 {
+		return sm, errSignerMismatch
+	}
+
+	if sm.Msig.Blank() {
+		sm.Msig.Version = ma.Version
+		sm.Msig.Threshold = ma.Threshold
+		sm.Msig.Subsigs = make([]types.MultisigSubsig, len(ma.Pks))
+		for i := range ma.Pks {
+			sm.Msig.Subsigs[i].Key = append(ed25519.PublicKey(nil), ma.Pks[i]...)
+		}
+	}
+	copy(sm.Msig.Subsigs[myIndex].Sig[:], ed25519.Sign(sk, sm.Msg.BytesToSign()))
+	return sm, nil
+}
+
+// SignDelegated signs the message with a delegate key on behalf of the signer
+// that delegated lsig. The delegate signs with crypto.TealSign for the lsig
+// program, so the same signature can be checked on chain with ed25519verify,
+// and the program must embed the delegate public key.
+func SignDelegated(delegate ed25519.PrivateKey, lsig types.LogicSig, msg Message) (sm SignedMessage, err error) {
+	msg.Delegate = delegate.Public().(ed25519.PublicKey)
+	sm.Msg = msg
+	sm.Lsig = lsig
+	if err = checkDelegation(sm); err != nil {
+		return
+	}
+	sm.Sig, err = crypto.TealSignFromProgram(delegate, msg.BytesToSign(), lsig.Logic)
+	return
+}
+
+func checkDelegation(sm SignedMessage) error {
+	if sm.Lsig.Sig == (types.Signature{}) && sm.Lsig.Msig.Blank() {
+		// a contract account cannot delegate
+		return errInvalidSignature
+	}
+	if !crypto.VerifyLogicSig(sm.Lsig, sm.Msg.Signer) {
+		return errInvalidSignature
+	}
+	if len(sm.Msg.Delegate) != ed25519.PublicKeySize || !bytes.Contains(sm.Lsig.Logic, sm.Msg.Delegate) {
+		return errDelegateNotInProgram
+	}
+	return nil
+}
+
+// Verify checks a signed message for the expected domain at the given round,
+// and returns the address of its signer. Pass round 0 to skip the expiry check.
+func Verify(sm SignedMessage, domain string, round types.Round) (signer types.Address, err error) {
+	if sm.Msg.Domain != domain {
+		err = errDomainMismatch
+		return
+	}
+	if sm.Msg.Expiry != 0 && round > sm.Msg.Expiry {
+		err = fmt.Errorf("%v: expired at round %d", errExpired, sm.Msg.Expiry)
+		return
+	}
+
+	hasSig := sm.Sig != (types.Signature{})
+	hasMsig := !sm.Msig.Blank()
+	hasLsig := !sm.Lsig.Blank()
+	switch {
+	case hasMsig && (hasSig || hasLsig):
+		err = errTooManySignatures
+	case hasLsig:
+		if err = checkDelegation(sm); err != nil {
+			return
+		}
+		contract := crypto.AddressFromProgram(sm.Lsig.Logic)
+		if !crypto.VerifyTealSign(sm.Msg.Delegate, sm.Msg.BytesToSign(), contract, sm.Sig) {
+			err = errInvalidSignature
+		}
+	case hasSig:
+		if len(sm.Msg.Delegate) != 0 || !ed25519.Verify(sm.Msg.Signer[:], sm.Msg.BytesToSign(), sm.Sig[:]) {
+			err = errInvalidSignature
+		}
+	case hasMsig:
+		if !crypto.VerifyMultisig(sm.Msg.Signer, sm.Msg.BytesToSign(), sm.Msig) {
+			err = errInvalidSignature
+		}
+	default:
+		err = errNoSignature
+	}
+	if err != nil {
+		return
+	}
+	return sm.Msg.Signer, nil
+}
+
+// Encode returns the msgpack encoding of the signed message
+func (sm SignedMessage) Encode() []byte {
+	return msgpack.Encode(sm)
+}
+
+// Decode decodes a signed message produced by Encode
+func Decode(encoded []byte) (sm SignedMessage, err error) {
+	err = msgpack.Decode(encoded, &sm)
+	return
+}
@@ -0,0 +1,106 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+type loginRequest struct {
+	Nonce   []byte
+	Service string
+}
+
+const testDomain = "example.com/login"
+
+func TestSignVerify(t *testing.T) {
+	acct := crypto.GenerateAccount()
+	payload := loginRequest{Nonce: []byte{1, 2, 3}, Service: "api"}
+	msg, err := NewMessage(testDomain, "LoginRequest", payload, 1000, acct.Address)
+	require.NoError(t, err)
+
+	sm, err := Sign(acct.PrivateKey, msg)
+	require.NoError(t, err)
+
+	decoded, err := Decode(sm.Encode())
+	require.NoError(t, err)
+	signer, err := Verify(decoded, testDomain, 999)
+	require.NoError(t, err)
+	require.Equal(t, acct.Address, signer)
+
+	var got loginRequest
+	require.NoError(t, decoded.Msg.DecodePayload(&got))
+	require.Equal(t, payload, got)
+
+	// domain separation
+	_, err = Verify(decoded, "other.app/login", 999)
+	require.Equal(t, errDomainMismatch, err)
+
+	// expiry
+	_, err = Verify(decoded, testDomain, 1001)
+	require.Error(t, err)
+
+	// a signature is bound to the claimed signer
+	other := crypto.GenerateAccount()
+	_, err = Sign(other.PrivateKey, msg)
+	require.Equal(t, errSignerMismatch, err)
+	decoded.Msg.Signer = other.Address
+	_, err = Verify(decoded, testDomain, 0)
+	require.Equal(t, errInvalidSignature, err)
+}
+
+func TestSignMultisig(t *testing.T) {
+	accts := []crypto.Account{crypto.GenerateAccount(), crypto.GenerateAccount(), crypto.GenerateAccount()}
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{accts[0].Address, accts[1].Address, accts[2].Address})
+	require.NoError(t, err)
+	addr, err := ma.Address()
+	require.NoError(t, err)
+
+	msg, err := NewMessage(testDomain, "LoginRequest", loginRequest{Service: "api"}, 0, addr)
+	require.NoError(t, err)
+	sm, err := SignMultisig(accts[0].PrivateKey, ma, SignedMessage{Msg: msg})
+	require.NoError(t, err)
+	_, err = Verify(sm, testDomain, 0)
+	require.Equal(t, errInvalidSignature, err)
+
+	sm, err = SignMultisig(accts[2].PrivateKey, ma, sm)
+	require.NoError(t, err)
+	signer, err := Verify(sm, testDomain, 0)
+	require.NoError(t, err)
+	require.Equal(t, addr, signer)
+}
+
+// programReferencing returns a program that pushes key, pops it, and approves
+func programReferencing(key []byte) []byte {
+	program := []byte{0x02, 0x20, 0x01, 0x01, 0x26, 0x01, byte(len(key))}
+	program = append(program, key...)
+	return append(program, 0x28, 0x48, 0x22)
+}
+
+func TestSignDelegated(t *testing.T) {
+	acct := crypto.GenerateAccount()
+	delegatePk, delegateSk, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	lsig, err := crypto.MakeLogicSig(programReferencing(delegatePk), nil, acct.PrivateKey, crypto.MultisigAccount{})
+	require.NoError(t, err)
+
+	msg, err := NewMessage(testDomain, "LoginRequest", loginRequest{Service: "api"}, 0, acct.Address)
+	require.NoError(t, err)
+	sm, err := SignDelegated(delegateSk, lsig, msg)
+	require.NoError(t, err)
+
+	signer, err := Verify(sm, testDomain, 0)
+	require.NoError(t, err)
+	require.Equal(t, acct.Address, signer)
+
+	// a key that is not referenced by the delegated program cannot sign
+	_, otherSk, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = SignDelegated(otherSk, lsig, msg)
+	require.Equal(t, errDelegateNotInProgram, err)
+}
@@ -0,0 +1,357 @@
+package multisig
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// BundleVersion is the version of the bundle format written by this package
+const BundleVersion = 1
+
+// bundleChecksumPrefix is prepended to an encoded bundle when computing its checksum
+var bundleChecksumPrefix = []byte("MB")
+
+// txidPrefix is prepended to a transaction to sign it
+var txidPrefix = []byte("TX")
+
+var errBundleEmpty = errors.New("bundle must contain at least one transaction")
+var errBundleUnknownVersion = errors.New("unknown bundle version")
+var errBundleChecksum = errors.New("bundle checksum mismatch, the file is corrupted")
+var errBundleBadKey = fmt.Errorf("bundle holds a public key which is not %d bytes long", ed25519.PublicKeySize)
+var errBundleBadSignature = errors.New("bundle holds a signature which is not valid for its transaction")
+var errBundleMismatch = errors.New("bundles do not hold the same multisig account and transactions")
+var errBundleBadSender = errors.New("transaction sender does not match the multisig account of the bundle")
+var errBundleNotEnoughSigs = errors.New("not enough signatures collected to finalize the bundle")
+
+// Preimage is the portable form of a crypto.MultisigAccount
+type Preimage struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	Version   uint8               `codec:"v"`
+	Threshold uint8               `codec:"thr"`
+	Pks       []ed25519.PublicKey `codec:"pks"`
+}
+
+// Account returns the multisig account described by the preimage
+func (p Preimage) Account() (crypto.MultisigAccount, error) {
+	for _, pk := range p.Pks {
+		if len(pk) != ed25519.PublicKeySize {
+			return crypto.MultisigAccount{}, errBundleBadKey
+		}
+	}
+	ma := crypto.MultisigAccount{Version: p.Version, Threshold: p.Threshold, Pks: p.Pks}
+	return ma, ma.Validate()
+}
+
+// Bundle is a portable set of partially signed multisig transactions.
+// Co-signers exchange bundles, each appending their signature, until the
+// threshold is reached on every transaction and the bundle can be finalized.
+// Every transaction of a bundle is sent by the bundle's multisig account.
+type Bundle struct {
+	_struct struct{} `codec:",omitempty,omitemptyarray"`
+
+	// Version is the bundle format version
+	Version uint8 `codec:"v"`
+
+	// Preimage is the multisig account signing the transactions
+	Preimage Preimage `codec:"pre"`
+
+	// Txns are the partially signed transactions, in group order
+	Txns []types.SignedTxn `codec:"txns"`
+
+	// Checksum commits to all the other fields, see Encode. It detects
+	// corruption only: anyone editing a bundle can recompute it, so Decode
+	// and Finalize verify every signature instead.
+	Checksum types.Digest `codec:"chk"`
+}
+
+// NewBundle creates an unsigned bundle for the passed transactions, which
+// must all be sent by the address of ma
+func NewBundle(ma crypto.MultisigAccount, txns []types.Transaction) (*Bundle, error) {
+	if len(txns) == 0 {
+		return nil, errBundleEmpty
+	}
+	addr, err := ma.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		Version:  BundleVersion,
+		Preimage: Preimage{Version: ma.Version, Threshold: ma.Threshold, Pks: ma.Pks},
+	}
+	for _, tx := range txns {
+		if tx.Sender != addr {
+			return nil, errBundleBadSender
+		}
+		stx := types.SignedTxn{Txn: tx}
+		stx.Msig.Version = ma.Version
+		stx.Msig.Threshold = ma.Threshold
+		stx.Msig.Subsigs = make([]types.MultisigSubsig, len(ma.Pks))
+		for i, pk := range ma.Pks {
+			stx.Msig.Subsigs[i].Key = append(ed25519.PublicKey(nil), pk...)
+		}
+		b.Txns = append(b.Txns, stx)
+	}
+	return b, nil
+}
+
+// Address returns the multisig address of the bundle
+func (b *Bundle) Address() (types.Address, error) {
+	ma, err := b.Preimage.Account()
+	if err != nil {
+		return types.Address{}, err
+	}
+	return ma.Address()
+}
+
+// Append signs every transaction of the bundle with sk, which must belong to the multisig account
+func (b *Bundle) Append(sk ed25519.PrivateKey) error {
+	ma, err := b.Preimage.Account()
+	if err != nil {
+		return err
+	}
+	for i := range b.Txns {
+		_, stxBytes, err := crypto.AppendMultisigTransaction(sk, ma, msgpack.Encode(b.Txns[i]))
+		if err != nil {
+			return err
+		}
+		var stx types.SignedTxn
+		if err = msgpack.Decode(stxBytes, &stx); err != nil {
+			return err
+		}
+		b.Txns[i] = stx
+	}
+	return nil
+}
+
+// Merge adds the signatures collected in other to b. Both bundles must hold
+// the same multisig account and the same transactions.
+func (b *Bundle) Merge(other *Bundle) error {
+	if len(b.Txns) != len(other.Txns) {
+		return errBundleMismatch
+	}
+	if !bytes.Equal(msgpack.Encode(b.Preimage), msgpack.Encode(other.Preimage)) {
+		return errBundleMismatch
+	}
+	merged := make([]types.SignedTxn, len(b.Txns))
+	for i := range b.Txns {
+		if crypto.TransactionIDString(b.Txns[i].Txn) != crypto.TransactionIDString(other.Txns[i].Txn) {
+			return errBundleMismatch
+		}
+		_, stxBytes, err := crypto.MergeMultisigTransactions(msgpack.Encode(b.Txns[i]), msgpack.Encode(other.Txns[i]))
+		if err != nil {
+			return err
+		}
+		if err = msgpack.Decode(stxBytes, &merged[i]); err != nil {
+			return err
+		}
+	}
+	b.Txns = merged
+	return nil
+}
+
+// Status reports how many signatures have been collected
+type Status struct {
+	// Threshold is the number of signatures needed on each transaction
+	Threshold int
+
+	// Total is the number of keys in the multisig account
+	Total int
+
+	// Collected is the number of signatures present on every transaction
+	Collected int
+
+	// Signers are the addresses of the keys that signed every transaction
+	Signers []types.Address
+
+	// Missing are the addresses of the keys that have not signed every transaction yet
+	Missing []types.Address
+}
+
+// Complete returns true iff every transaction has enough signatures
+func (s Status) Complete() bool {
+	return s.Collected >= s.Threshold
+}
+
+// String returns a summary such as "2 of 3 signatures collected (threshold 2)"
+func (s Status) String() string {
+	return fmt.Sprintf("%d of %d signatures collected (threshold %d)", s.Collected, s.Total, s.Threshold)
+}
+
+// Status returns the signature collection status of the bundle
+func (b *Bundle) Status() Status {
+	s := Status{Threshold: int(b.Preimage.Threshold), Total: len(b.Preimage.Pks)}
+	for i, pk := range b.Preimage.Pks {
+		var addr types.Address
+		copy(addr[:], pk)
+		if b.signedBy(i) {
+			s.Signers = append(s.Signers, addr)
+		} else {
+			s.Missing = append(s.Missing, addr)
+		}
+	}
+	s.Collected = len(s.Signers)
+	return s
+}
+
+// signedBy returns true iff the i-th key signed every transaction of the bundle
+func (b *Bundle) signedBy(i int) bool {
+	for _, stx := range b.Txns {
+		if i >= len(stx.Msig.Subsigs) || stx.Msig.Subsigs[i].Sig == (types.Signature{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Summaries returns a human-readable one line description of each transaction
+func (b *Bundle) Summaries() []string {
+	summaries := make([]string, len(b.Txns))
+	for i, stx := range b.Txns {
+		summaries[i] = summarize(stx.Txn)
+	}
+	return summaries
+}
+
+func summarize(tx types.Transaction) string {
+	txid := crypto.TransactionIDString(tx)
+	switch tx.Type {
+	case types.PaymentTx:
+		s := fmt.Sprintf("%s: pay %s from %s to %s", txid, types.Algos(tx.Amount).Format(), tx.Sender, tx.Receiver)
+		if !tx.CloseRemainderTo.IsZero() {
+			s += fmt.Sprintf(", closing to %s", tx.CloseRemainderTo)
+		}
+		return s
+	case types.AssetTransferTx:
+		return fmt.Sprintf("%s: transfer %d units of asset %d from %s to %s", txid, tx.AssetAmount, tx.XferAsset, tx.Sender, tx.AssetReceiver)
+	case types.AssetConfigTx:
+		if tx.ConfigAsset == 0 {
+			return fmt.Sprintf("%s: create asset %q by %s", txid, tx.AssetParams.AssetName, tx.Sender)
+		}
+		return fmt.Sprintf("%s: configure asset %d by %s", txid, tx.ConfigAsset, tx.Sender)
+	case types.AssetFreezeTx:
+		return fmt.Sprintf("%s: set frozen=%v for asset %d of %s by %s", txid, tx.AssetFrozen, tx.FreezeAsset, tx.FreezeAccount, tx.Sender)
+	case types.ApplicationCallTx:
+		return fmt.Sprintf("%s: call application %d (on-completion %d) by %s", txid, tx.ApplicationID, tx.OnCompletion, tx.Sender)
+	case types.KeyRegistrationTx:
+		return fmt.Sprintf("%s: register participation keys for %s", txid, tx.Sender)
+	default:
+		return fmt.Sprintf("%s: %s transaction by %s", txid, tx.Type, tx.Sender)
+	}
+}
+
+// verify checks that every transaction carries the keys of the multisig
+// account and that each of its signatures is valid
+func (b *Bundle) verify() error {
+	if _, err := b.Preimage.Account(); err != nil {
+		return err
+	}
+	for _, stx := range b.Txns {
+		msig := stx.Msig
+		if msig.Version != b.Preimage.Version || msig.Threshold != b.Preimage.Threshold || len(msig.Subsigs) != len(b.Preimage.Pks) {
+			return errBundleMismatch
+		}
+		message := append(append([]byte{}, txidPrefix...), msgpack.Encode(stx.Txn)...)
+		for i, subsig := range msig.Subsigs {
+			if !bytes.Equal(subsig.Key, b.Preimage.Pks[i]) {
+				return errBundleMismatch
+			}
+			if subsig.Sig != (types.Signature{}) && !ed25519.Verify(subsig.Key, message, subsig.Sig[:]) {
+				return errBundleBadSignature
+			}
+		}
+	}
+	return nil
+}
+
+// Finalize checks that every transaction reached the threshold with valid
+// signatures and returns the concatenated signed transactions, ready to be
+// broadcasted to the network
+func (b *Bundle) Finalize() ([]byte, error) {
+	if err := b.verify(); err != nil {
+		return nil, err
+	}
+	var stxBytes []byte
+	for _, stx := range b.Txns {
+		var count int
+		for _, subsig := range stx.Msig.Subsigs {
+			if subsig.Sig != (types.Signature{}) {
+				count++
+			}
+		}
+		if count < int(stx.Msig.Threshold) {
+			return nil, errBundleNotEnoughSigs
+		}
+		stxBytes = append(stxBytes, msgpack.Encode(stx)...)
+	}
+	return stxBytes, nil
+}
+
+// computeChecksum returns the checksum of the bundle content
+func (b *Bundle) computeChecksum() types.Digest {
+	unsummed := *b
+	unsummed.Checksum = types.Digest{}
+	toBeHashed := bytes.Join([][]byte{bundleChecksumPrefix, msgpack.Encode(unsummed)}, nil)
+	return sha512.Sum512_256(toBeHashed)
+}
+
+// Encode sets the bundle checksum and returns its msgpack encoding
+func (b *Bundle) Encode() []byte {
+	b.Checksum = b.computeChecksum()
+	return msgpack.Encode(b)
+}
+
+// Decode decodes an encoded bundle, checks its checksum and verifies the
+// signatures it holds
+func Decode(encoded []byte) (*Bundle, error) {
+	var b Bundle
+	if err := msgpack.Decode(encoded, &b); err != nil {
+		return nil, err
+	}
+	if b.Version != BundleVersion {
+		return nil, errBundleUnknownVersion
+	}
+	if b.Checksum != b.computeChecksum() {
+		return nil, errBundleChecksum
+	}
+	addr, err := b.Address()
+	if err != nil {
+		return nil, err
+	}
+	if len(b.Txns) == 0 {
+		return nil, errBundleEmpty
+	}
+	for _, stx := range b.Txns {
+		if stx.Txn.Sender != addr {
+			return nil, errBundleBadSender
+		}
+	}
+	if err := b.verify(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// WriteFile writes the encoded bundle to path
+func (b *Bundle) WriteFile(path string) error {
+	return ioutil.WriteFile(path, b.Encode(), 0600)
+}
+
+// ReadFile reads and checks a bundle written by WriteFile
+func ReadFile(path string) (*Bundle, error) {
+	encoded, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(encoded)
+}
@@ -0,0 +1,171 @@
+package multisig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+func makeTestBundle(t *testing.T) (*Bundle, crypto.MultisigAccount, []crypto.Account) {
+	signers := []crypto.Account{crypto.GenerateAccount(), crypto.GenerateAccount(), crypto.GenerateAccount()}
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{signers[0].Address, signers[1].Address, signers[2].Address})
+	require.NoError(t, err)
+	from, err := ma.Address()
+	require.NoError(t, err)
+
+	txns := make([]types.Transaction, 2)
+	for i := range txns {
+		txns[i] = types.Transaction{
+			Type: types.PaymentTx,
+			Header: types.Header{
+				Sender:     from,
+				Fee:        1000,
+				FirstValid: 1,
+				LastValid:  1001,
+				GenesisID:  "testnet-v1.0",
+			},
+			PaymentTxnFields: types.PaymentTxnFields{
+				Receiver: signers[i].Address,
+				Amount:   types.MicroAlgos(1000000 * (i + 1)),
+			},
+		}
+	}
+	gid, err := crypto.ComputeGroupID(txns)
+	require.NoError(t, err)
+	for i := range txns {
+		txns[i].Group = gid
+	}
+
+	b, err := NewBundle(ma, txns)
+	require.NoError(t, err)
+	return b, ma, signers
+}
+
+func TestBundleExchange(t *testing.T) {
+	b, ma, signers := makeTestBundle(t)
+	require.Equal(t, "0 of 3 signatures collected (threshold 2)", b.Status().String())
+
+	// first co-signer signs and hands the file over
+	require.NoError(t, b.Append(signers[0].PrivateKey))
+	dir, err := ioutil.TempDir("", "bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "group.msig")
+	require.NoError(t, b.WriteFile(path))
+
+	_, err = b.Finalize()
+	require.Error(t, err)
+
+	// second co-signer completes it
+	received, err := ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, received.Append(signers[2].PrivateKey))
+	status := received.Status()
+	require.True(t, status.Complete())
+	require.Equal(t, []types.Address{signers[0].Address, signers[2].Address}, status.Signers)
+	require.Equal(t, []types.Address{signers[1].Address}, status.Missing)
+	require.Len(t, received.Summaries(), 2)
+	require.Contains(t, received.Summaries()[1], "pay 2.000000 Algo from")
+
+	stxBytes, err := received.Finalize()
+	require.NoError(t, err)
+	addr, err := ma.Address()
+	require.NoError(t, err)
+	dec := msgpack.NewDecoder(bytes.NewReader(stxBytes))
+	for range received.Txns {
+		var stx types.SignedTxn
+		require.NoError(t, dec.Decode(&stx))
+		toBeSigned := append([]byte("TX"), msgpack.Encode(stx.Txn)...)
+		require.True(t, crypto.VerifyMultisig(addr, toBeSigned, stx.Msig))
+	}
+}
+
+func TestBundleMerge(t *testing.T) {
+	b, _, signers := makeTestBundle(t)
+	other, err := Decode(b.Encode())
+	require.NoError(t, err)
+
+	require.NoError(t, b.Append(signers[0].PrivateKey))
+	require.NoError(t, other.Append(signers[1].PrivateKey))
+	require.NoError(t, b.Merge(other))
+	require.Equal(t, 2, b.Status().Collected)
+
+	unrelated, _, _ := makeTestBundle(t)
+	require.Equal(t, errBundleMismatch, b.Merge(unrelated))
+}
+
+func TestBundleChecksum(t *testing.T) {
+	b, _, signers := makeTestBundle(t)
+	encoded := b.Encode()
+
+	var tampered Bundle
+	require.NoError(t, msgpack.Decode(encoded, &tampered))
+	tampered.Txns[0].Txn.Amount++
+	_, err := Decode(msgpack.Encode(tampered))
+	require.Equal(t, errBundleChecksum, err)
+
+	outsider := crypto.GenerateAccount()
+	require.Error(t, b.Append(outsider.PrivateKey))
+	require.NoError(t, b.Append(signers[1].PrivateKey))
+}
+
+func TestBundleSignaturesAreVerified(t *testing.T) {
+	b, _, signers := makeTestBundle(t)
+	require.NoError(t, b.Append(signers[0].PrivateKey))
+	require.NoError(t, b.Append(signers[1].PrivateKey))
+
+	// the checksum is recomputed by Encode, only the signatures catch edits
+	edited, err := Decode(b.Encode())
+	require.NoError(t, err)
+	edited.Txns[0].Txn.Amount++
+	_, err = Decode(edited.Encode())
+	require.Equal(t, errBundleBadSignature, err)
+	_, err = edited.Finalize()
+	require.Equal(t, errBundleBadSignature, err)
+
+	forged, err := Decode(b.Encode())
+	require.NoError(t, err)
+	forged.Txns[1].Msig.Subsigs[2].Sig = forged.Txns[1].Msig.Subsigs[0].Sig
+	_, err = Decode(forged.Encode())
+	require.Equal(t, errBundleBadSignature, err)
+
+	swapped, err := Decode(b.Encode())
+	require.NoError(t, err)
+	swapped.Txns[0].Msig.Subsigs[0].Key = signers[2].PublicKey
+	_, err = Decode(swapped.Encode())
+	require.Equal(t, errBundleMismatch, err)
+}
+
+func TestBundleRejectsShortKeys(t *testing.T) {
+	ma := crypto.MultisigAccount{Version: 1, Threshold: 1, Pks: []ed25519.PublicKey{{1, 2, 3}}}
+	from, err := ma.Address()
+	require.NoError(t, err)
+	stx := types.SignedTxn{Txn: types.Transaction{Type: types.PaymentTx, Header: types.Header{Sender: from}}}
+	stx.Msig.Version, stx.Msig.Threshold = 1, 1
+	stx.Msig.Subsigs = []types.MultisigSubsig{{Key: ma.Pks[0], Sig: types.Signature{1}}}
+	b := &Bundle{
+		Version:  BundleVersion,
+		Preimage: Preimage{Version: 1, Threshold: 1, Pks: ma.Pks},
+		Txns:     []types.SignedTxn{stx},
+	}
+
+	// the checksum and the sender are consistent, only the key is wrong
+	_, err = Decode(b.Encode())
+	require.Equal(t, errBundleBadKey, err)
+	_, err = b.Finalize()
+	require.Equal(t, errBundleBadKey, err)
+}
+
+func TestSummaryAmountsAreExact(t *testing.T) {
+	tx := types.Transaction{Type: types.PaymentTx, PaymentTxnFields: types.PaymentTxnFields{Amount: 9007199254740993}}
+	require.Contains(t, summarize(tx), "pay 9007199254.740993 Algo from")
+}
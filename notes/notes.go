@@ -0,0 +1,237 @@
+// Package notes encodes and decodes structured transaction notes following
+// the "<dapp-name>:<format><data>" convention, where format is a single
+// character naming how data is encoded:
+//   - 'j' JSON
+//   - 'm' msgpack
+//   - 'u' UTF-8 plain text
+//   - 'b' opaque bytes
+//
+// For example, a JSON note of the "exchange" dapp reads `exchange:j{"op":"buy"}`.
+// Tagging notes this way lets indexer search for an application's
+// transactions by note prefix.
+package notes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/client/v2/indexer"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Format identifies how the data of a note is encoded
+type Format byte
+
+const (
+	// JSON notes hold a JSON document
+	JSON Format = 'j'
+	// Msgpack notes hold a msgpack-encoded value
+	Msgpack Format = 'm'
+	// Text notes hold UTF-8 text
+	Text Format = 'u'
+	// Bytes notes hold opaque application bytes
+	Bytes Format = 'b'
+)
+
+// separator separates the dapp name from the format and data
+const separator = ':'
+
+var errNotTagged = errors.New("note does not follow the <dapp-name>:<format> convention")
+var errEmptyDAppName = errors.New("dapp name cannot be empty")
+var errInvalidDAppName = errors.New("dapp name cannot contain ':'")
+
+// Note is a decoded structured note
+type Note struct {
+	// DApp is the name of the application that wrote the note
+	DApp string
+
+	// Format is the encoding of Data
+	Format Format
+
+	// Data is the encoded payload, without the prefix
+	Data []byte
+}
+
+func (f Format) valid() bool {
+	switch f {
+	case JSON, Msgpack, Text, Bytes:
+		return true
+	}
+	return false
+}
+
+func checkDAppName(dapp string) error {
+	if dapp == "" {
+		return errEmptyDAppName
+	}
+	if bytes.IndexByte([]byte(dapp), separator) >= 0 {
+		return errInvalidDAppName
+	}
+	return nil
+}
+
+// Prefix returns the note prefix "<dapp>:<format>" shared by every note of a dapp in that format
+func Prefix(dapp string, format Format) ([]byte, error) {
+	if err := checkDAppName(dapp); err != nil {
+		return nil, err
+	}
+	if !format.valid() {
+		return nil, fmt.Errorf("unknown note format %q", byte(format))
+	}
+	return append([]byte(dapp+string(separator)), byte(format)), nil
+}
+
+// Encode returns the note bytes holding v in the passed format.
+// Text notes take a string, Bytes notes take a []byte. The note may be at most
+// MaxTxnNoteBytes long under the Latest protocol.
+func Encode(dapp string, format Format, v interface{}) ([]byte, error) {
+	prefix, err := Prefix(dapp, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch format {
+	case JSON:
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	case Msgpack:
+		data = msgpack.Encode(v)
+	case Text:
+		text, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("text notes must be strings, got %T", v)
+		}
+		data = []byte(text)
+	case Bytes:
+		raw, ok := v.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("bytes notes must be []byte, got %T", v)
+		}
+		data = raw
+	}
+
+	note := append(prefix, data...)
+	if maxSize := consensus.LookupOrLatest(consensus.Latest).MaxTxnNoteBytes; len(note) > maxSize {
+		return nil, fmt.Errorf("note too long: %d > %d", len(note), maxSize)
+	}
+	return note, nil
+}
+
+// Parse splits a raw note into its dapp name, format and data
+func Parse(note []byte) (n Note, err error) {
+	idx := bytes.IndexByte(note, separator)
+	if idx <= 0 || idx+1 >= len(note) {
+		err = errNotTagged
+		return
+	}
+	n.DApp = string(note[:idx])
+	n.Format = Format(note[idx+1])
+	n.Data = note[idx+2:]
+	if !n.Format.valid() {
+		err = errNotTagged
+		return
+	}
+	if n.Format == Text && !utf8.Valid(n.Data) {
+		err = fmt.Errorf("text note of %s is not valid UTF-8", n.DApp)
+	}
+	return
+}
+
+// Decode decodes the data of the note into the object pointed to by objptr.
+// Text notes decode into a *string, Bytes notes into a *[]byte.
+func (n Note) Decode(objptr interface{}) error {
+	switch n.Format {
+	case JSON:
+		return json.Unmarshal(n.Data, objptr)
+	case Msgpack:
+		return msgpack.Decode(n.Data, objptr)
+	case Text:
+		s, ok := objptr.(*string)
+		if !ok {
+			return fmt.Errorf("text notes decode into *string, got %T", objptr)
+		}
+		*s = string(n.Data)
+		return nil
+	case Bytes:
+		b, ok := objptr.(*[]byte)
+		if !ok {
+			return fmt.Errorf("bytes notes decode into *[]byte, got %T", objptr)
+		}
+		*b = append([]byte(nil), n.Data...)
+		return nil
+	}
+	return errNotTagged
+}
+
+// parseFor parses note and checks it was written by dapp
+func parseFor(note []byte, dapp string) (Note, error) {
+	n, err := Parse(note)
+	if err != nil {
+		return n, err
+	}
+	if n.DApp != dapp {
+		return n, fmt.Errorf("note belongs to dapp %q, not %q", n.DApp, dapp)
+	}
+	return n, nil
+}
+
+// DecodeTransaction decodes the note of a transaction written by dapp into objptr
+func DecodeTransaction(tx types.Transaction, dapp string, objptr interface{}) error {
+	n, err := parseFor(tx.Note, dapp)
+	if err != nil {
+		return err
+	}
+	return n.Decode(objptr)
+}
+
+// DecodeIndexerTransaction decodes the note of an indexer transaction written by dapp into objptr
+func DecodeIndexerTransaction(tx models.Transaction, dapp string, objptr interface{}) error {
+	n, err := parseFor(tx.Note, dapp)
+	if err != nil {
+		return err
+	}
+	return n.Decode(objptr)
+}
+
+// BlockNote is a note found in a block, with the position of its transaction
+type BlockNote struct {
+	Note
+
+	// Index is the position of the transaction in the block Payset
+	Index int
+
+	// Txn is the transaction carrying the note
+	Txn types.Transaction
+}
+
+// FromBlock returns the notes written by dapp in the transactions of block, in Payset order
+func FromBlock(block types.Block, dapp string) []BlockNote {
+	var found []BlockNote
+	for i, stxn := range block.Payset {
+		n, err := parseFor(stxn.Txn.Note, dapp)
+		if err != nil {
+			continue
+		}
+		found = append(found, BlockNote{Note: n, Index: i, Txn: stxn.Txn})
+	}
+	return found
+}
+
+// SearchTransactions returns an indexer transaction search restricted to the
+// notes of dapp in the given format. Further filters can be chained on the result.
+func SearchTransactions(client *indexer.Client, dapp string, format Format) (*indexer.SearchForTransactions, error) {
+	prefix, err := Prefix(dapp, format)
+	if err != nil {
+		return nil, err
+	}
+	return client.SearchForTransactions().NotePrefix(prefix), nil
+}
@@ -0,0 +1,81 @@
+package notes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+type order struct {
+	Op     string `json:"op" codec:"op"`
+	Amount uint64 `json:"amt" codec:"amt"`
+}
+
+func TestEncodeParse(t *testing.T) {
+	for _, format := range []Format{JSON, Msgpack} {
+		note, err := Encode("exchange", format, order{Op: "buy", Amount: 10})
+		require.NoError(t, err)
+
+		n, err := Parse(note)
+		require.NoError(t, err)
+		require.Equal(t, "exchange", n.DApp)
+		require.Equal(t, format, n.Format)
+
+		var got order
+		require.NoError(t, n.Decode(&got))
+		require.Equal(t, order{Op: "buy", Amount: 10}, got)
+	}
+
+	note, err := Encode("exchange", JSON, order{Op: "buy"})
+	require.NoError(t, err)
+	require.Equal(t, `exchange:j{"op":"buy","amt":0}`, string(note))
+
+	note, err = Encode("chat", Text, "hello")
+	require.NoError(t, err)
+	require.Equal(t, "chat:uhello", string(note))
+
+	_, err = Encode("bad:name", Text, "hello")
+	require.Equal(t, errInvalidDAppName, err)
+	_, err = Encode("chat", Text, string(make([]byte, consensus.LookupOrLatest(consensus.Latest).MaxTxnNoteBytes)))
+	require.Error(t, err)
+
+	_, err = Parse([]byte{180, 81, 121, 57})
+	require.Equal(t, errNotTagged, err)
+}
+
+func TestDecodeTransactions(t *testing.T) {
+	note, err := Encode("exchange", Msgpack, order{Op: "sell", Amount: 3})
+	require.NoError(t, err)
+
+	var got order
+	require.NoError(t, DecodeTransaction(types.Transaction{Header: types.Header{Note: note}}, "exchange", &got))
+	require.Equal(t, "sell", got.Op)
+
+	got = order{}
+	require.NoError(t, DecodeIndexerTransaction(models.Transaction{Note: note}, "exchange", &got))
+	require.Equal(t, uint64(3), got.Amount)
+	require.Error(t, DecodeIndexerTransaction(models.Transaction{Note: note}, "other", &got))
+
+	var block types.Block
+	for _, n := range [][]byte{[]byte("raw note"), note, nil, note} {
+		var stxn types.SignedTxnInBlock
+		stxn.Txn.Note = n
+		block.Payset = append(block.Payset, stxn)
+	}
+	found := FromBlock(block, "exchange")
+	require.Len(t, found, 2)
+	require.Equal(t, 1, found[0].Index)
+	require.Equal(t, 3, found[1].Index)
+}
+
+func TestPrefix(t *testing.T) {
+	prefix, err := Prefix("exchange", JSON)
+	require.NoError(t, err)
+	require.Equal(t, "exchange:j", string(prefix))
+	_, err = Prefix("exchange", Format('x'))
+	require.Error(t, err)
+}
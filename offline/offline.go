@@ -0,0 +1,199 @@
+// Package offline supports signing transactions on a machine without network
+// access. Transactions are built online and written to an unsigned transaction
+// file, carried to the signing machine, reviewed and signed there, and the
+// resulting signed transaction file is carried back for submission.
+//
+// Files use the same format as goal's .txn and .stxn files: a concatenation of
+// msgpack-encoded SignedTxn, with no signature in the unsigned case.
+package offline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var errEmptyFile = errors.New("transaction file contains no transactions")
+var errAlreadySigned = errors.New("unsigned transaction file contains a signed transaction")
+var errNotSigned = errors.New("signed transaction file contains an unsigned transaction")
+
+// EncodeUnsigned encodes transactions in the unsigned transaction file format
+func EncodeUnsigned(txns []types.Transaction) []byte {
+	var buf bytes.Buffer
+	for _, txn := range txns {
+		buf.Write(msgpack.Encode(types.SignedTxn{Txn: txn}))
+	}
+	return buf.Bytes()
+}
+
+// DecodeUnsigned decodes the content of an unsigned transaction file
+func DecodeUnsigned(data []byte) ([]types.Transaction, error) {
+	stxns, err := decodeStream(data)
+	if err != nil {
+		return nil, err
+	}
+	txns := make([]types.Transaction, len(stxns))
+	for i, stxn := range stxns {
+		if isSigned(stxn) {
+			return nil, fmt.Errorf("transaction %d: %v", i, errAlreadySigned)
+		}
+		txns[i] = stxn.Txn
+	}
+	return txns, nil
+}
+
+// DecodeSigned decodes the content of a signed transaction file
+func DecodeSigned(data []byte) ([]types.SignedTxn, error) {
+	stxns, err := decodeStream(data)
+	if err != nil {
+		return nil, err
+	}
+	for i, stxn := range stxns {
+		if !isSigned(stxn) {
+			return nil, fmt.Errorf("transaction %d: %v", i, errNotSigned)
+		}
+	}
+	return stxns, nil
+}
+
+func decodeStream(data []byte) (stxns []types.SignedTxn, err error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	for {
+		var stxn types.SignedTxn
+		err = dec.Decode(&stxn)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", len(stxns), err)
+		}
+		stxns = append(stxns, stxn)
+	}
+	if len(stxns) == 0 {
+		return nil, errEmptyFile
+	}
+	return stxns, nil
+}
+
+func isSigned(stxn types.SignedTxn) bool {
+	return stxn.Sig != (types.Signature{}) || !stxn.Msig.Blank() || !stxn.Lsig.Blank()
+}
+
+// WriteUnsignedFile writes transactions to an unsigned transaction (.txn) file
+func WriteUnsignedFile(path string, txns []types.Transaction) error {
+	if len(txns) == 0 {
+		return errEmptyFile
+	}
+	return ioutil.WriteFile(path, EncodeUnsigned(txns), 0600)
+}
+
+// ReadUnsignedFile reads the transactions of an unsigned transaction (.txn) file
+func ReadUnsignedFile(path string) ([]types.Transaction, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeUnsigned(data)
+}
+
+// WriteSignedFile writes encoded signed transactions to a signed transaction (.stxn) file
+func WriteSignedFile(path string, stxns [][]byte) error {
+	if len(stxns) == 0 {
+		return errEmptyFile
+	}
+	return ioutil.WriteFile(path, bytes.Join(stxns, nil), 0600)
+}
+
+// ReadSignedFile reads the transactions of a signed transaction (.stxn) file.
+// The raw file content can be passed as is to algod's SendRawTransaction.
+func ReadSignedFile(path string) ([]types.SignedTxn, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSigned(data)
+}
+
+// Sign signs the transactions at indexesToSign with signer, after writing a
+// review of each of them to review. A nil indexesToSign signs every transaction,
+// and a nil review skips the review. The signed transactions are returned in
+// the order of indexesToSign.
+func Sign(txns []types.Transaction, indexesToSign []int, signer future.TransactionSigner, review io.Writer) ([][]byte, error) {
+	if len(txns) == 0 {
+		return nil, errEmptyFile
+	}
+	if indexesToSign == nil {
+		indexesToSign = make([]int, len(txns))
+		for i := range txns {
+			indexesToSign[i] = i
+		}
+	}
+	for _, i := range indexesToSign {
+		if i < 0 || i >= len(txns) {
+			return nil, fmt.Errorf("index %d out of range for %d transactions", i, len(txns))
+		}
+		if review != nil {
+			if err := Review(review, i, txns[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return signer.SignTransactions(txns, indexesToSign)
+}
+
+// SignFile signs the transactions at indexesToSign of the file at inPath with
+// Sign and writes the result to outPath. Every transaction is written in its
+// original position, the ones left unsigned included, so that the signers of
+// a group can sign it in turn: the input is an unsigned transaction file or
+// the output of a previous SignFile. A nil indexesToSign signs every
+// transaction not signed yet. ReadSignedFile accepts the output once every
+// transaction is signed.
+func SignFile(inPath, outPath string, indexesToSign []int, signer future.TransactionSigner, review io.Writer) error {
+	data, err := ioutil.ReadFile(inPath)
+	if err != nil {
+		return err
+	}
+	stxns, err := decodeStream(data)
+	if err != nil {
+		return err
+	}
+	signAll := indexesToSign == nil
+	txns := make([]types.Transaction, len(stxns))
+	for i, stxn := range stxns {
+		txns[i] = stxn.Txn
+		if signAll && !isSigned(stxn) {
+			indexesToSign = append(indexesToSign, i)
+		}
+	}
+	if len(indexesToSign) == 0 {
+		return fmt.Errorf("no transaction of %s to sign", inPath)
+	}
+	for _, i := range indexesToSign {
+		if i >= 0 && i < len(stxns) && isSigned(stxns[i]) {
+			return fmt.Errorf("transaction %d is already signed", i)
+		}
+	}
+	signed, err := Sign(txns, indexesToSign, signer, review)
+	if err != nil {
+		return err
+	}
+
+	out := make([][]byte, len(stxns))
+	for i, stxn := range stxns {
+		out[i] = msgpack.Encode(stxn)
+	}
+	for k, i := range indexesToSign {
+		out[i] = signed[k]
+	}
+	if _, err := os.Stat(outPath); err == nil {
+		return fmt.Errorf("refusing to overwrite %s", outPath)
+	}
+	return WriteSignedFile(outPath, out)
+}
@@ -0,0 +1,130 @@
+package offline
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var testParams = types.SuggestedParams{
+	Fee:             1000,
+	FlatFee:         true,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     []byte("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	FirstRoundValid: 100,
+	LastRoundValid:  1100,
+}
+
+func TestOfflineSigningRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sender := crypto.GenerateAccount()
+	receiver := crypto.GenerateAccount()
+	pay, err := future.MakePaymentTxn(sender.Address.String(), receiver.Address.String(), 1500000, nil, "", testParams)
+	require.NoError(t, err)
+	optin, err := future.MakeAssetAcceptanceTxn(sender.Address.String(), nil, testParams, 31566704)
+	require.NoError(t, err)
+	txns := []types.Transaction{pay, optin}
+
+	unsignedPath := filepath.Join(dir, "group.txn")
+	require.NoError(t, WriteUnsignedFile(unsignedPath, txns))
+	read, err := ReadUnsignedFile(unsignedPath)
+	require.NoError(t, err)
+	require.Equal(t, txns, read)
+
+	var review bytes.Buffer
+	signedPath := filepath.Join(dir, "group.stxn")
+	signer := future.BasicAccountTransactionSigner{Account: sender}
+	require.NoError(t, SignFile(unsignedPath, signedPath, nil, signer, &review))
+	require.Contains(t, review.String(), "1.500000 Algos")
+	require.Contains(t, review.String(), receiver.Address.String())
+	require.Contains(t, review.String(), "31566704")
+
+	stxns, err := ReadSignedFile(signedPath)
+	require.NoError(t, err)
+	require.Len(t, stxns, 2)
+	for i, stxn := range stxns {
+		require.Equal(t, txns[i], stxn.Txn)
+	}
+
+	// files are never mixed up or overwritten
+	_, err = ReadUnsignedFile(signedPath)
+	require.Error(t, err)
+	_, err = ReadSignedFile(unsignedPath)
+	require.Error(t, err)
+	require.Error(t, SignFile(unsignedPath, signedPath, nil, signer, nil))
+}
+
+func TestSignSelectedIndexes(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+	first, err := future.MakePaymentTxn(sender.Address.String(), other.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+	second, err := future.MakePaymentTxn(other.Address.String(), sender.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+
+	stxns, err := Sign([]types.Transaction{first, second}, []int{1}, future.BasicAccountTransactionSigner{Account: other}, nil)
+	require.NoError(t, err)
+	decoded, err := DecodeSigned(stxns[0])
+	require.NoError(t, err)
+	require.Equal(t, second, decoded[0].Txn)
+
+	_, err = Sign([]types.Transaction{first}, []int{1}, future.BasicAccountTransactionSigner{Account: other}, nil)
+	require.Error(t, err)
+}
+
+func TestSignFileInTurns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	alice, bob := crypto.GenerateAccount(), crypto.GenerateAccount()
+	first, err := future.MakePaymentTxn(alice.Address.String(), bob.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+	second, err := future.MakePaymentTxn(bob.Address.String(), alice.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+	txns := []types.Transaction{first, second}
+	unsignedPath := filepath.Join(dir, "group.txn")
+	require.NoError(t, WriteUnsignedFile(unsignedPath, txns))
+
+	// alice signs her transaction, the one of bob is kept unsigned
+	alicePath := filepath.Join(dir, "alice.stxn")
+	require.NoError(t, SignFile(unsignedPath, alicePath, []int{0}, future.BasicAccountTransactionSigner{Account: alice}, nil))
+	_, err = ReadSignedFile(alicePath)
+	require.Error(t, err)
+	require.Error(t, SignFile(alicePath, filepath.Join(dir, "again.stxn"), []int{0}, future.BasicAccountTransactionSigner{Account: alice}, nil))
+
+	// bob signs the rest from the file of alice
+	bobPath := filepath.Join(dir, "bob.stxn")
+	require.NoError(t, SignFile(alicePath, bobPath, nil, future.BasicAccountTransactionSigner{Account: bob}, nil))
+	stxns, err := ReadSignedFile(bobPath)
+	require.NoError(t, err)
+	require.Len(t, stxns, 2)
+	for i, stxn := range stxns {
+		require.Equal(t, txns[i], stxn.Txn)
+	}
+	require.True(t, signedBy(alice, stxns[0]))
+	require.True(t, signedBy(bob, stxns[1]))
+
+	require.Error(t, SignFile(bobPath, filepath.Join(dir, "done.stxn"), nil, future.BasicAccountTransactionSigner{Account: bob}, nil))
+}
+
+// signedBy returns whether stxn holds the signature of account
+func signedBy(account crypto.Account, stxn types.SignedTxn) bool {
+	_, encoded, err := crypto.SignTransaction(account.PrivateKey, stxn.Txn)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(encoded, msgpack.Encode(stxn))
+}
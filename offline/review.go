@@ -0,0 +1,145 @@
+package offline
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var onCompletionNames = map[types.OnCompletion]string{
+	types.NoOpOC:              "noop",
+	types.OptInOC:             "optin",
+	types.CloseOutOC:          "closeout",
+	types.ClearStateOC:        "clearstate",
+	types.UpdateApplicationOC: "update",
+	types.DeleteApplicationOC: "delete",
+}
+
+// Review writes a human-readable description of the transaction at position
+// index of its group, meant to be checked by a person before signing it.
+// Amounts are shown in Algos and addresses in their checksummed form.
+func Review(w io.Writer, index int, txn types.Transaction) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	line := func(name string, format string, args ...interface{}) {
+		fmt.Fprintf(tw, "  %s:\t%s\n", name, fmt.Sprintf(format, args...))
+	}
+	address := func(name string, addr types.Address) {
+		if !addr.IsZero() {
+			line(name, "%s", addr.String())
+		}
+	}
+
+	fmt.Fprintf(tw, "Transaction %d (%s)\n", index, crypto.TransactionIDString(txn))
+	line("type", "%s", txn.Type)
+	address("sender", txn.Sender)
+	line("fee", "%s", formatAlgos(txn.Fee))
+	line("valid rounds", "%d - %d", txn.FirstValid, txn.LastValid)
+	line("genesis", "%s %s", txn.GenesisID, base64.StdEncoding.EncodeToString(txn.GenesisHash[:]))
+	if len(txn.Note) > 0 {
+		line("note", "%q", txn.Note)
+	}
+	if txn.Group != (types.Digest{}) {
+		line("group", "%s", base64.StdEncoding.EncodeToString(txn.Group[:]))
+	}
+	if txn.Lease != ([32]byte{}) {
+		line("lease", "%s", base64.StdEncoding.EncodeToString(txn.Lease[:]))
+	}
+	address("rekey to", txn.RekeyTo)
+
+	switch txn.Type {
+	case types.PaymentTx:
+		address("receiver", txn.Receiver)
+		line("amount", "%s", formatAlgos(txn.Amount))
+		address("close remainder to", txn.CloseRemainderTo)
+	case types.KeyRegistrationTx:
+		if txn.Nonparticipation {
+			line("nonparticipation", "true")
+		}
+		if txn.VotePK != (types.VotePK{}) {
+			line("vote key", "%s", base64.StdEncoding.EncodeToString(txn.VotePK[:]))
+			line("selection key", "%s", base64.StdEncoding.EncodeToString(txn.SelectionPK[:]))
+			line("vote rounds", "%d - %d", txn.VoteFirst, txn.VoteLast)
+			line("vote key dilution", "%d", txn.VoteKeyDilution)
+		} else {
+			line("participation", "offline")
+		}
+	case types.AssetConfigTx:
+		switch {
+		case txn.ConfigAsset == 0:
+			line("action", "create asset")
+		case txn.AssetParams.IsZero():
+			line("action", "destroy asset %d", txn.ConfigAsset)
+		default:
+			line("action", "reconfigure asset %d", txn.ConfigAsset)
+		}
+		ap := txn.AssetParams
+		if txn.ConfigAsset == 0 {
+			line("asset name", "%q", ap.AssetName)
+			line("unit name", "%q", ap.UnitName)
+			line("total", "%d", ap.Total)
+			line("decimals", "%d", ap.Decimals)
+			line("default frozen", "%t", ap.DefaultFrozen)
+			if ap.URL != "" {
+				line("url", "%q", ap.URL)
+			}
+		}
+		if !ap.IsZero() {
+			// roles left empty on reconfiguration are cleared for good
+			line("manager", "%s", roleAddress(ap.Manager))
+			line("reserve", "%s", roleAddress(ap.Reserve))
+			line("freeze", "%s", roleAddress(ap.Freeze))
+			line("clawback", "%s", roleAddress(ap.Clawback))
+		}
+	case types.AssetTransferTx:
+		line("asset id", "%d", txn.XferAsset)
+		line("asset amount", "%d base units", txn.AssetAmount)
+		address("asset sender", txn.AssetSender)
+		address("asset receiver", txn.AssetReceiver)
+		address("asset close to", txn.AssetCloseTo)
+	case types.AssetFreezeTx:
+		line("asset id", "%d", txn.FreezeAsset)
+		address("freeze account", txn.FreezeAccount)
+		line("frozen", "%t", txn.AssetFrozen)
+	case types.ApplicationCallTx:
+		if txn.ApplicationID == 0 {
+			line("application", "create")
+		} else {
+			line("application id", "%d", txn.ApplicationID)
+		}
+		line("on completion", "%s", onCompletionNames[txn.OnCompletion])
+		for i, arg := range txn.ApplicationArgs {
+			line(fmt.Sprintf("arg %d", i), "%s", base64.StdEncoding.EncodeToString(arg))
+		}
+		for _, acct := range txn.Accounts {
+			address("account", acct)
+		}
+		for _, app := range txn.ForeignApps {
+			line("foreign app", "%d", app)
+		}
+		for _, asset := range txn.ForeignAssets {
+			line("foreign asset", "%d", asset)
+		}
+		if len(txn.ApprovalProgram) > 0 {
+			line("approval program", "%d bytes", len(txn.ApprovalProgram))
+		}
+		if len(txn.ClearStateProgram) > 0 {
+			line("clear program", "%d bytes", len(txn.ClearStateProgram))
+		}
+	}
+	return tw.Flush()
+}
+
+func formatAlgos(amount types.MicroAlgos) string {
+	return fmt.Sprintf("%.6f Algos", amount.ToAlgos())
+}
+
+func roleAddress(addr types.Address) string {
+	if addr.IsZero() {
+		return "(none)"
+	}
+	return addr.String()
+}
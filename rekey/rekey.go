@@ -0,0 +1,223 @@
+// Package rekey helps manage rekeyed accounts: finding the address currently
+// authorized to sign for an account, picking the matching signer, building
+// rekey transactions and tracing an account's chain of custody.
+package rekey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/client/v2/indexer"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/transaction"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var errRekeyToZero = errors.New("cannot rekey to the zero address, the account would become unusable")
+var errRekeyToSelf = errors.New("rekeying an account to itself resets it, use MakeRekeyBackTxn")
+
+// authAddr returns the address authorized to sign for an account
+func authAddr(account models.Account) (types.Address, error) {
+	if account.AuthAddr != "" {
+		return types.DecodeAddress(account.AuthAddr)
+	}
+	return types.DecodeAddress(account.Address)
+}
+
+// AuthAddr returns the address currently authorized to sign for address,
+// which is address itself unless the account was rekeyed
+func AuthAddr(ctx context.Context, client *algod.Client, address string) (types.Address, error) {
+	account, err := client.AccountInformation(address).Do(ctx)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return authAddr(account)
+}
+
+// AuthAddrFromIndexer is like AuthAddr but asks the indexer
+func AuthAddrFromIndexer(ctx context.Context, client *indexer.Client, address string) (types.Address, error) {
+	_, account, err := client.LookupAccountByID(address).Do(ctx)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return authAddr(account)
+}
+
+// Keyring holds signers by the address they sign for, and picks the one
+// authorized for a sender according to the current auth address of the sender
+type Keyring struct {
+	signers map[types.Address]future.TransactionSigner
+}
+
+// MakeKeyring returns an empty Keyring
+func MakeKeyring() *Keyring {
+	return &Keyring{signers: make(map[types.Address]future.TransactionSigner)}
+}
+
+// Add registers signer as the signer of addr
+func (k *Keyring) Add(addr types.Address, signer future.TransactionSigner) {
+	k.signers[addr] = signer
+}
+
+// AddAccount registers the key of a single account
+func (k *Keyring) AddAccount(account crypto.Account) {
+	k.Add(account.Address, future.BasicAccountTransactionSigner{Account: account})
+}
+
+// AddMultisig registers keys of a multisig account
+func (k *Keyring) AddMultisig(ma crypto.MultisigAccount, sks ...[]byte) error {
+	addr, err := ma.Address()
+	if err != nil {
+		return err
+	}
+	k.Add(addr, future.MultiSigAccountTransactionSigner{Msig: ma, Sks: sks})
+	return nil
+}
+
+// Signer returns the signer registered for auth
+func (k *Keyring) Signer(auth types.Address) (future.TransactionSigner, error) {
+	signer, ok := k.signers[auth]
+	if !ok {
+		return nil, fmt.Errorf("no signer for %s", auth)
+	}
+	return signer, nil
+}
+
+// SignerFor looks up the current auth address of sender and returns its signer
+func (k *Keyring) SignerFor(ctx context.Context, client *algod.Client, sender types.Address) (future.TransactionSigner, error) {
+	auth, err := AuthAddr(ctx, client, sender.String())
+	if err != nil {
+		return nil, err
+	}
+	signer, err := k.Signer(auth)
+	if err != nil && auth != sender {
+		return nil, fmt.Errorf("%s is rekeyed to %s: %v", sender, auth, err)
+	}
+	return signer, err
+}
+
+// WithSigner pairs txn with the signer currently authorized for its sender,
+// ready to be added to a future.AtomicTransactionComposer
+func (k *Keyring) WithSigner(ctx context.Context, client *algod.Client, txn types.Transaction) (future.TransactionWithSigner, error) {
+	signer, err := k.SignerFor(ctx, client, txn.Sender)
+	if err != nil {
+		return future.TransactionWithSigner{}, err
+	}
+	return future.TransactionWithSigner{Txn: txn, Signer: signer}, nil
+}
+
+// MakeRekeyTxn builds a zero-amount payment from account to itself that hands
+// signing authority over account to newAuth
+func MakeRekeyTxn(account, newAuth string, params types.SuggestedParams) (types.Transaction, error) {
+	authAddr, err := types.DecodeAddress(newAuth)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if authAddr.IsZero() {
+		return types.Transaction{}, errRekeyToZero
+	}
+	if account == newAuth {
+		return types.Transaction{}, errRekeyToSelf
+	}
+	return makeRekey(account, authAddr, params)
+}
+
+// MakeRekeyBackTxn builds a zero-amount payment from account to itself that
+// gives signing authority back to the account's own key. It must be signed by
+// the current auth address.
+func MakeRekeyBackTxn(account string, params types.SuggestedParams) (types.Transaction, error) {
+	addr, err := types.DecodeAddress(account)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	return makeRekey(account, addr, params)
+}
+
+func makeRekey(account string, to types.Address, params types.SuggestedParams) (types.Transaction, error) {
+	tx, err := future.MakePaymentTxn(account, account, 0, nil, "", params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	tx.RekeyTo = to
+	// RekeyTo adds to the size the fee was computed on
+	if err := transaction.AssignFee(&tx, params, transaction.SignatureShape{}); err != nil {
+		return types.Transaction{}, err
+	}
+	return tx, nil
+}
+
+// Warnings lists the risky rekey combinations found in txn
+func Warnings(txn types.Transaction) []string {
+	if txn.RekeyTo.IsZero() {
+		return nil
+	}
+	var warnings []string
+	if !txn.CloseRemainderTo.IsZero() {
+		warnings = append(warnings, fmt.Sprintf("transaction closes the account to %s and rekeys it to %s: the rekey is pointless, closing deletes the account with its auth address, so if it is ever funded again it is controlled by its own key and not by %s", txn.CloseRemainderTo, txn.RekeyTo, txn.RekeyTo))
+	}
+	if !txn.AssetCloseTo.IsZero() {
+		warnings = append(warnings, fmt.Sprintf("transaction closes an asset holding and rekeys the account to %s", txn.RekeyTo))
+	}
+	return warnings
+}
+
+// Change is a rekey of an account
+type Change struct {
+	TxID      string
+	Round     uint64
+	RoundTime uint64
+
+	offset uint64
+
+	// SignedBy is the auth address that authorized the rekey
+	SignedBy string
+
+	// AuthAddr is the auth address set by the rekey. It equals the account
+	// address when authority was given back to the account's own key.
+	AuthAddr string
+}
+
+// History returns every rekey of address known to the indexer, oldest first
+func History(ctx context.Context, client *indexer.Client, address string) ([]Change, error) {
+	var changes []Change
+	var next string
+	for {
+		response, err := client.LookupAccountTransactions(address).RekeyTo(true).NextToken(next).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range response.Transactions {
+			if tx.Sender != address || tx.RekeyTo == "" {
+				continue
+			}
+			signedBy := tx.AuthAddr
+			if signedBy == "" {
+				signedBy = tx.Sender
+			}
+			changes = append(changes, Change{
+				TxID:      tx.Id,
+				Round:     tx.ConfirmedRound,
+				RoundTime: tx.RoundTime,
+				SignedBy:  signedBy,
+				AuthAddr:  tx.RekeyTo,
+				offset:    tx.IntraRoundOffset,
+			})
+		}
+		if response.NextToken == "" || len(response.Transactions) == 0 {
+			break
+		}
+		next = response.NextToken
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Round != changes[j].Round {
+			return changes[i].Round < changes[j].Round
+		}
+		return changes[i].offset < changes[j].offset
+	})
+	return changes, nil
+}
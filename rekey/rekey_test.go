@@ -0,0 +1,132 @@
+package rekey
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/client/v2/algod"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/client/v2/indexer"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var testParams = types.SuggestedParams{
+	Fee:             1,
+	GenesisID:       "testnet-v1.0",
+	GenesisHash:     []byte("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	FirstRoundValid: 100,
+	LastRoundValid:  1100,
+}
+
+func TestKeyringFollowsAuthAddr(t *testing.T) {
+	owner := crypto.GenerateAccount()
+	cold := crypto.GenerateAccount()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v2/accounts/"+owner.Address.String(), r.URL.Path)
+		json.NewEncoder(w).Encode(models.Account{Address: owner.Address.String(), AuthAddr: cold.Address.String()})
+	}))
+	defer server.Close()
+	client, err := algod.MakeClient(server.URL, "")
+	require.NoError(t, err)
+
+	keyring := MakeKeyring()
+	keyring.AddAccount(owner)
+	_, err = keyring.SignerFor(context.Background(), client, owner.Address)
+	require.Error(t, err)
+
+	keyring.AddAccount(cold)
+	tx, err := MakeRekeyBackTxn(owner.Address.String(), testParams)
+	require.NoError(t, err)
+	txAndSigner, err := keyring.WithSigner(context.Background(), client, tx)
+	require.NoError(t, err)
+	require.True(t, txAndSigner.Signer.Equals(future.BasicAccountTransactionSigner{Account: cold}))
+
+	stxs, err := txAndSigner.Signer.SignTransactions([]types.Transaction{tx}, []int{0})
+	require.NoError(t, err)
+	var stx types.SignedTxn
+	require.NoError(t, msgpack.Decode(stxs[0], &stx))
+	require.Equal(t, cold.Address, stx.AuthAddr)
+	require.Equal(t, owner.Address, stx.Txn.RekeyTo)
+}
+
+func TestMakeRekeyTxn(t *testing.T) {
+	owner := crypto.GenerateAccount()
+	cold := crypto.GenerateAccount()
+
+	tx, err := MakeRekeyTxn(owner.Address.String(), cold.Address.String(), testParams)
+	require.NoError(t, err)
+	require.Equal(t, cold.Address, tx.RekeyTo)
+	require.Equal(t, owner.Address, tx.Receiver)
+	require.Equal(t, types.MicroAlgos(1000), tx.Fee)
+	require.Empty(t, Warnings(tx))
+
+	// the fee per byte covers RekeyTo, a flat fee is kept as is
+	perByte := testParams
+	perByte.Fee = 10
+	paid, err := MakeRekeyTxn(owner.Address.String(), cold.Address.String(), perByte)
+	require.NoError(t, err)
+	_, signed, err := crypto.SignTransaction(owner.PrivateKey, paid)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(10*len(signed)), paid.Fee)
+	flat := testParams
+	flat.FlatFee, flat.Fee = true, 2500
+	paid, err = MakeRekeyTxn(owner.Address.String(), cold.Address.String(), flat)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(2500), paid.Fee)
+
+	_, err = MakeRekeyTxn(owner.Address.String(), types.Address{}.String(), testParams)
+	require.Error(t, err)
+	_, err = MakeRekeyTxn(owner.Address.String(), owner.Address.String(), testParams)
+	require.Error(t, err)
+
+	tx.CloseRemainderTo = cold.Address
+	warnings := Warnings(tx)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "rekey is pointless")
+	require.Contains(t, warnings[0], "controlled by its own key")
+	require.NotContains(t, warnings[0], "keeps the new auth address")
+}
+
+func TestHistory(t *testing.T) {
+	owner := crypto.GenerateAccount().Address.String()
+	first := crypto.GenerateAccount().Address.String()
+	second := crypto.GenerateAccount().Address.String()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "true", r.URL.Query().Get("rekey-to"))
+		var response models.TransactionsResponse
+		// newest first, over two pages
+		switch r.URL.Query().Get("next") {
+		case "":
+			response.Transactions = []models.Transaction{
+				{Id: "C", Sender: owner, RekeyTo: owner, AuthAddr: second, ConfirmedRound: 30},
+				{Id: "B", Sender: owner, RekeyTo: second, AuthAddr: first, ConfirmedRound: 20},
+			}
+			response.NextToken = "page2"
+		case "page2":
+			response.Transactions = []models.Transaction{
+				{Id: "A", Sender: owner, RekeyTo: first, ConfirmedRound: 10},
+			}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+	client, err := indexer.MakeClient(server.URL, "")
+	require.NoError(t, err)
+
+	changes, err := History(context.Background(), client, owner)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	require.Equal(t, "A", changes[0].TxID)
+	require.Equal(t, owner, changes[0].SignedBy)
+	require.Equal(t, first, changes[0].AuthAddr)
+	require.Equal(t, second, changes[2].SignedBy)
+	require.Equal(t, owner, changes[2].AuthAddr)
+}
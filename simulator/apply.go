@@ -0,0 +1,479 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// txidPrefix is prepended to transactions to sign them
+var txidPrefix = []byte("TX")
+
+// Dryrun messages naming the program that ran
+const (
+	approvalProgramMessage = "ApprovalProgram"
+	clearProgramMessage    = "ClearStateProgram"
+)
+
+// messages returns the dryrun messages of a program evaluation
+func messages(pass bool, err error) []string {
+	switch {
+	case err != nil:
+		return []string{"ERROR: " + err.Error()}
+	case pass:
+		return []string{"PASS"}
+	}
+	return []string{"REJECT"}
+}
+
+// effects are the effects of a transaction which later transactions of its
+// group can read
+type effects struct {
+	// scratch is the scratch space left by the program of an application
+	// call
+	scratch *[256]appstate.Value
+
+	// created is the index of the asset or application created, if any
+	created uint64
+}
+
+// applyTxn applies the transaction at index i of group to st, and records
+// its effects in past[i]
+func (l *Ledger) applyTxn(st *state, group []types.SignedTxn, past []effects, i int) (models.DryrunTxnResult, error) {
+	var result models.DryrunTxnResult
+	txn := &group[i].Txn
+	if uint64(txn.FirstValid) > l.Round || l.Round > uint64(txn.LastValid) {
+		return result, fmt.Errorf("valid in rounds %d to %d, not in round %d", txn.FirstValid, txn.LastValid, l.Round)
+	}
+	if txn.GenesisHash != l.GenesisHash {
+		return result, fmt.Errorf("genesis hash does not match the ledger")
+	}
+	if err := l.authorize(st, group, i, &result); err != nil {
+		return result, err
+	}
+
+	sender := st.mutable(txn.Sender)
+	if sender.amount < uint64(txn.Fee) {
+		return result, fmt.Errorf("balance %d of %s cannot pay the fee of %d", sender.amount, txn.Sender, txn.Fee)
+	}
+	sender.amount -= uint64(txn.Fee)
+	if !txn.RekeyTo.IsZero() {
+		sender.authAddr = txn.RekeyTo
+		if txn.RekeyTo == txn.Sender {
+			sender.authAddr = types.Address{}
+		}
+	}
+
+	lastIndex := st.lastIndex
+	var err error
+	switch txn.Type {
+	case types.PaymentTx:
+		err = st.pay(txn)
+	case types.KeyRegistrationTx:
+		// participation is not simulated
+	case types.AssetConfigTx:
+		err = st.configAsset(txn)
+	case types.AssetTransferTx:
+		err = st.transferAsset(txn)
+	case types.AssetFreezeTx:
+		err = st.freezeAsset(txn)
+	case types.ApplicationCallTx:
+		err = l.callApp(st, group, past, i, &result)
+	}
+	if st.lastIndex != lastIndex {
+		past[i].created = st.lastIndex
+	}
+	return result, err
+}
+
+// authorize checks the signature, multisig or LogicSig of the transaction at
+// index i of group against the address authorized to spend from its sender
+func (l *Ledger) authorize(st *state, group []types.SignedTxn, i int, result *models.DryrunTxnResult) error {
+	stxn := &group[i]
+	authorizer := stxn.Txn.Sender
+	if a, ok := st.accounts[authorizer]; ok && !a.authAddr.IsZero() {
+		authorizer = a.authAddr
+	}
+	// like algod, a rekeyed sender needs AuthAddr to name its auth address
+	signer := stxn.Txn.Sender
+	if !stxn.AuthAddr.IsZero() {
+		signer = stxn.AuthAddr
+	}
+	if signer != authorizer {
+		return fmt.Errorf("signed by %s, but %s is authorized to spend from %s", signer, authorizer, stxn.Txn.Sender)
+	}
+
+	hasSig := stxn.Sig != (types.Signature{})
+	hasMsig := !stxn.Msig.Blank()
+	hasLsig := len(stxn.Lsig.Logic) > 0
+	signatures := 0
+	for _, has := range []bool{hasSig, hasMsig, hasLsig} {
+		if has {
+			signatures++
+		}
+	}
+	if signatures != 1 {
+		return fmt.Errorf("needs exactly one of a signature, a multisig and a LogicSig, has %d", signatures)
+	}
+
+	toSign := append(append([]byte{}, txidPrefix...), msgpack.Encode(stxn.Txn)...)
+	switch {
+	case hasSig:
+		if !ed25519.Verify(authorizer[:], toSign, stxn.Sig[:]) {
+			return fmt.Errorf("invalid signature of %s", authorizer)
+		}
+	case hasMsig:
+		if !crypto.VerifyMultisig(authorizer, toSign, stxn.Msig) {
+			return fmt.Errorf("invalid multisig of %s", authorizer)
+		}
+	case hasLsig:
+		if !crypto.VerifyLogicSig(stxn.Lsig, authorizer) {
+			return fmt.Errorf("LogicSig is not valid for %s", authorizer)
+		}
+		cx := &evalContext{
+			mode:    modeSig,
+			params:  l.Params,
+			program: stxn.Lsig.Logic,
+			group:   group,
+			index:   i,
+			args:    stxn.Lsig.Args,
+		}
+		pass, err := cx.run()
+		result.LogicSigTrace = cx.trace
+		result.LogicSigMessages = messages(pass, err)
+		if cx.d != nil {
+			result.Disassembly = cx.d.lines
+		}
+		if err != nil {
+			return fmt.Errorf("LogicSig: %v", err)
+		}
+		if !pass {
+			return errors.New("rejected by LogicSig")
+		}
+	}
+	return nil
+}
+
+// move moves amount microAlgos between two accounts
+func (st *state) move(from, to types.Address, amount uint64) error {
+	source := st.mutable(from)
+	if source.amount < amount {
+		return fmt.Errorf("balance %d of %s is below the amount %d", source.amount, from, amount)
+	}
+	source.amount -= amount
+	dest := st.mutable(to)
+	sum, carry := bits.Add64(dest.amount, amount, 0)
+	if carry != 0 {
+		return fmt.Errorf("balance of %s overflows", to)
+	}
+	dest.amount = sum
+	return nil
+}
+
+func (st *state) pay(txn *types.Transaction) error {
+	if err := st.move(txn.Sender, txn.Receiver, uint64(txn.Amount)); err != nil {
+		return err
+	}
+	if txn.CloseRemainderTo.IsZero() {
+		return nil
+	}
+	sender := st.mutable(txn.Sender)
+	if len(sender.holdings) > 0 || len(sender.locals) > 0 {
+		return fmt.Errorf("cannot close %s, which holds assets or opted in applications", txn.Sender)
+	}
+	for id, app := range st.apps {
+		if app.creator == txn.Sender {
+			return fmt.Errorf("cannot close %s, which created application %d", txn.Sender, id)
+		}
+	}
+	if err := st.move(txn.Sender, txn.CloseRemainderTo, sender.amount); err != nil {
+		return err
+	}
+	delete(st.accounts, txn.Sender)
+	return nil
+}
+
+func (st *state) configAsset(txn *types.Transaction) error {
+	if txn.ConfigAsset == 0 {
+		index := st.newIndex()
+		st.assets[index] = &asset{creator: txn.Sender, params: txn.AssetParams}
+		st.mutable(txn.Sender).holdings[index] = holding{amount: txn.AssetParams.Total}
+		return nil
+	}
+
+	index := uint64(txn.ConfigAsset)
+	a, ok := st.assets[index]
+	if !ok {
+		return fmt.Errorf("asset %d does not exist", index)
+	}
+	if a.params.Manager.IsZero() {
+		return fmt.Errorf("asset %d has no manager and cannot be changed", index)
+	}
+	if txn.Sender != a.params.Manager {
+		return fmt.Errorf("%s is not the manager of asset %d", txn.Sender, index)
+	}
+
+	if txn.AssetParams.IsZero() {
+		creator := st.mutable(a.creator)
+		if creator.holdings[index].amount != a.params.Total {
+			return fmt.Errorf("asset %d can only be destroyed when its creator holds all of it", index)
+		}
+		delete(creator.holdings, index)
+		delete(st.assets, index)
+		return nil
+	}
+
+	roles := []struct {
+		name     string
+		current  *types.Address
+		proposed types.Address
+	}{
+		{"manager", &a.params.Manager, txn.AssetParams.Manager},
+		{"reserve", &a.params.Reserve, txn.AssetParams.Reserve},
+		{"freeze", &a.params.Freeze, txn.AssetParams.Freeze},
+		{"clawback", &a.params.Clawback, txn.AssetParams.Clawback},
+	}
+	for _, role := range roles {
+		if role.current.IsZero() && !role.proposed.IsZero() {
+			return fmt.Errorf("the %s address of asset %d was cleared and cannot be set", role.name, index)
+		}
+	}
+	for _, role := range roles {
+		*role.current = role.proposed
+	}
+	return nil
+}
+
+func (st *state) transferAsset(txn *types.Transaction) error {
+	index := uint64(txn.XferAsset)
+	a, ok := st.assets[index]
+	if !ok {
+		return fmt.Errorf("asset %d does not exist", index)
+	}
+
+	source := txn.Sender
+	clawback := !txn.AssetSender.IsZero()
+	if clawback {
+		if a.params.Clawback.IsZero() || txn.Sender != a.params.Clawback {
+			return fmt.Errorf("%s is not the clawback address of asset %d", txn.Sender, index)
+		}
+		source = txn.AssetSender
+	} else if txn.AssetReceiver == txn.Sender && txn.AssetAmount == 0 {
+		sender := st.mutable(txn.Sender)
+		if _, ok := sender.holdings[index]; !ok {
+			// opting in the asset
+			sender.holdings[index] = holding{frozen: a.params.DefaultFrozen}
+			return nil
+		}
+	}
+
+	if err := st.moveAsset(index, source, txn.AssetReceiver, txn.AssetAmount, clawback); err != nil {
+		return err
+	}
+	if txn.AssetCloseTo.IsZero() {
+		return nil
+	}
+	if source == a.creator {
+		return fmt.Errorf("the creator of asset %d cannot close out its holding", index)
+	}
+	holder := st.mutable(source)
+	if err := st.moveAsset(index, source, txn.AssetCloseTo, holder.holdings[index].amount, false); err != nil {
+		return err
+	}
+	delete(holder.holdings, index)
+	return nil
+}
+
+// moveAsset moves amount units of asset index between two accounts. Frozen
+// holdings cannot send or receive the asset unless ignoreFrozen is set.
+func (st *state) moveAsset(index uint64, from, to types.Address, amount uint64, ignoreFrozen bool) error {
+	source, ok := st.mutable(from).holdings[index]
+	if !ok {
+		return fmt.Errorf("%s has not opted in asset %d", from, index)
+	}
+	dest, ok := st.mutable(to).holdings[index]
+	if !ok {
+		return fmt.Errorf("%s has not opted in asset %d", to, index)
+	}
+	if !ignoreFrozen && (source.frozen || dest.frozen) {
+		return fmt.Errorf("asset %d is frozen for %s or %s", index, from, to)
+	}
+	if source.amount < amount {
+		return fmt.Errorf("%s holds %d of asset %d, not %d", from, source.amount, index, amount)
+	}
+	if from == to {
+		return nil
+	}
+	sum, carry := bits.Add64(dest.amount, amount, 0)
+	if carry != 0 {
+		return fmt.Errorf("holding of %s in asset %d overflows", to, index)
+	}
+	source.amount -= amount
+	dest.amount = sum
+	st.accounts[from].holdings[index] = source
+	st.accounts[to].holdings[index] = dest
+	return nil
+}
+
+func (st *state) freezeAsset(txn *types.Transaction) error {
+	index := uint64(txn.FreezeAsset)
+	a, ok := st.assets[index]
+	if !ok {
+		return fmt.Errorf("asset %d does not exist", index)
+	}
+	if a.params.Freeze.IsZero() || txn.Sender != a.params.Freeze {
+		return fmt.Errorf("%s is not the freeze address of asset %d", txn.Sender, index)
+	}
+	target := st.mutable(txn.FreezeAccount)
+	h, ok := target.holdings[index]
+	if !ok {
+		return fmt.Errorf("%s has not opted in asset %d", txn.FreezeAccount, index)
+	}
+	h.frozen = txn.AssetFrozen
+	target.holdings[index] = h
+	return nil
+}
+
+// callApp applies the application call at index i of group, running the
+// approval or clear state program of the application
+func (l *Ledger) callApp(st *state, group []types.SignedTxn, past []effects, i int, result *models.DryrunTxnResult) error {
+	txn := &group[i].Txn
+	appID := uint64(txn.ApplicationID)
+	if appID == 0 {
+		appID = st.newIndex()
+		st.apps[appID] = &application{
+			creator:      txn.Sender,
+			approval:     txn.ApprovalProgram,
+			clear:        txn.ClearStateProgram,
+			globalSchema: txn.GlobalStateSchema,
+			localSchema:  txn.LocalStateSchema,
+			global:       make(appstate.State),
+		}
+	}
+	app, ok := st.apps[appID]
+	if !ok {
+		return fmt.Errorf("application %d does not exist", appID)
+	}
+	sender := st.mutable(txn.Sender)
+
+	switch txn.OnCompletion {
+	case types.ClearStateOC:
+		if sender.locals[appID] == nil {
+			return fmt.Errorf("%s has not opted in application %d", txn.Sender, appID)
+		}
+		// the account leaves the application whatever the program decides,
+		// its changes are only kept when it approves
+		cx := l.appContext(st, group, past, i, appID, app)
+		pass, err := cx.run()
+		past[i].scratch = &cx.scratch
+		recordApp(result, cx, clearProgramMessage, pass, err)
+		if err == nil && pass {
+			if err := commit(cx, app, result); err != nil {
+				result.AppCallMessages = []string{clearProgramMessage, "ERROR: " + err.Error()}
+			}
+		}
+		delete(sender.locals, appID)
+		return nil
+	case types.OptInOC:
+		if sender.locals[appID] != nil {
+			return fmt.Errorf("%s has already opted in application %d", txn.Sender, appID)
+		}
+		sender.locals[appID] = &localState{schema: app.localSchema, kv: make(appstate.State)}
+	}
+
+	cx := l.appContext(st, group, past, i, appID, app)
+	pass, err := cx.run()
+	past[i].scratch = &cx.scratch
+	recordApp(result, cx, approvalProgramMessage, pass, err)
+	if err != nil {
+		return fmt.Errorf("approval program: %v", err)
+	}
+	if !pass {
+		return errors.New("rejected by the approval program")
+	}
+	if err := commit(cx, app, result); err != nil {
+		return err
+	}
+
+	switch txn.OnCompletion {
+	case types.CloseOutOC:
+		delete(sender.locals, appID)
+	case types.UpdateApplicationOC:
+		app.approval = txn.ApprovalProgram
+		app.clear = txn.ClearStateProgram
+	case types.DeleteApplicationOC:
+		delete(st.apps, appID)
+	}
+	return nil
+}
+
+// appContext returns the context running the approval or clear state
+// program of app for the transaction at index i of group
+func (l *Ledger) appContext(st *state, group []types.SignedTxn, past []effects, i int, appID uint64, app *application) *evalContext {
+	program := app.approval
+	if group[i].Txn.OnCompletion == types.ClearStateOC {
+		program = app.clear
+	}
+	return &evalContext{
+		mode:      modeApp,
+		params:    l.Params,
+		program:   program,
+		group:     group,
+		index:     i,
+		round:     l.Round,
+		timestamp: l.Timestamp,
+		st:        st,
+		appID:     appID,
+		global:    app.global.Clone(),
+		locals:    make(map[types.Address]appstate.State),
+		past:      past,
+	}
+}
+
+// recordApp records the evaluation of an application program in result
+func recordApp(result *models.DryrunTxnResult, cx *evalContext, program string, pass bool, err error) {
+	result.AppCallTrace = cx.trace
+	result.AppCallMessages = append([]string{program}, messages(pass, err)...)
+	if cx.d != nil {
+		result.Disassembly = cx.d.lines
+	}
+}
+
+// commit checks the state written by an approving program against the
+// schemas of app, then records its deltas in result and stores it
+func commit(cx *evalContext, app *application, result *models.DryrunTxnResult) error {
+	if err := checkSchema(cx.global, app.globalSchema); err != nil {
+		return fmt.Errorf("global state: %v", err)
+	}
+	addrs := make([]types.Address, 0, len(cx.locals))
+	for addr, kv := range cx.locals {
+		if err := checkSchema(kv, app.localSchema); err != nil {
+			return fmt.Errorf("local state of %s: %v", addr, err)
+		}
+		addrs = append(addrs, addr)
+	}
+
+	if changes := appstate.Diff(app.global, cx.global); len(changes) > 0 {
+		result.GlobalDelta = appstate.Deltas(changes)
+	}
+	app.global = cx.global
+	for _, addr := range sortAddresses(addrs) {
+		local := cx.st.localState(addr, cx.appID)
+		if changes := appstate.Diff(local.kv, cx.locals[addr]); len(changes) > 0 {
+			result.LocalDeltas = append(result.LocalDeltas, models.AccountStateDelta{
+				Address: addr.String(),
+				Delta:   appstate.Deltas(changes),
+			})
+		}
+		local.kv = cx.locals[addr]
+	}
+	return nil
+}
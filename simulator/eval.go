@@ -0,0 +1,1921 @@
+package simulator
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Evaluation limits of the protocol
+const (
+	maxStackDepth = 1000
+	maxStringSize = 4096
+)
+
+// progDataPrefix is prepended to the data verified by ed25519verify
+var progDataPrefix = []byte("ProgData")
+
+// evalContext is the state of a running program
+type evalContext struct {
+	mode    runMode
+	params  consensus.Params
+	program []byte
+	group   []types.SignedTxn
+	index   int
+
+	// args are the arguments of a LogicSig
+	args [][]byte
+
+	// The fields below are only set in application mode. The program reads
+	// the ledger state st and writes the state of application appID to
+	// global and locals, which are committed when the program approves.
+	round     uint64
+	timestamp int64
+	st        *state
+	appID     uint64
+	global    appstate.State
+	locals    map[types.Address]appstate.State
+
+	// past holds the effects of the transactions of the group before index
+	past []effects
+
+	d         *disassembly
+	pc        int
+	nextPC    int
+	returned  bool
+	cost      int
+	stack     []appstate.Value
+	callstack []int
+	scratch   [256]appstate.Value
+	scratchN  int
+	intc      []uint64
+	bytec     [][]byte
+	trace     []models.DryrunState
+}
+
+// run evaluates the program and reports whether it approved. The trace of
+// the evaluation is left in cx.trace.
+func (cx *evalContext) run() (bool, error) {
+	for i := range cx.scratch {
+		cx.scratch[i] = appstate.UintValue(0)
+	}
+	d, err := disassemble(cx.program)
+	if err != nil {
+		return false, err
+	}
+	cx.d = d
+	if d.version > maxVersion {
+		return false, fmt.Errorf("program version %d is not supported, the maximum is %d", d.version, maxVersion)
+	}
+	maxCost := cx.params.LogicSigMaxCost
+	if cx.mode == modeApp {
+		if d.version < 2 {
+			return false, fmt.Errorf("application programs need version 2 or later")
+		}
+		maxCost = cx.params.MaxAppProgramCost
+	}
+	if d.version < backBranchVersion && uint64(d.cost) > maxCost {
+		return false, fmt.Errorf("program cost %d exceeds the maximum of %d", d.cost, maxCost)
+	}
+
+	_, cx.pc = binary.Uvarint(cx.program)
+	for !cx.returned && cx.pc < len(cx.program) {
+		op := opcodes[cx.program[cx.pc]]
+		instr := d.instrs[cx.pc]
+		cx.trace = append(cx.trace, cx.state(instr.line))
+		cx.nextPC = cx.pc + instr.size
+		cx.cost += op.costIn(d.version)
+		if !op.runsIn(d.version, cx.mode) {
+			err = fmt.Errorf("%s is not available in version %d %s programs", op.name, d.version, cx.mode)
+		} else if uint64(cx.cost) > maxCost {
+			err = fmt.Errorf("cost %d exceeds the maximum of %d", cx.cost, maxCost)
+		} else {
+			err = op.eval(cx)
+		}
+		if err != nil {
+			err = fmt.Errorf("%s at pc %d: %v", op.name, cx.pc, err)
+			cx.trace[len(cx.trace)-1].Error = err.Error()
+			return false, err
+		}
+		cx.pc = cx.nextPC
+	}
+
+	if len(cx.stack) != 1 {
+		return false, fmt.Errorf("program ended with %d values on the stack, expected 1", len(cx.stack))
+	}
+	if cx.stack[0].Type != appstate.TypeUint {
+		return false, fmt.Errorf("program ended with bytes on the stack, expected a uint")
+	}
+	return cx.stack[0].Uint != 0, nil
+}
+
+// state returns the trace entry of the instruction about to run
+func (cx *evalContext) state(line int) models.DryrunState {
+	s := models.DryrunState{Pc: uint64(cx.pc), Line: uint64(line)}
+	for _, v := range cx.stack {
+		s.Stack = append(s.Stack, appstate.EncodeValue(v))
+	}
+	for _, v := range cx.scratch[:cx.scratchN] {
+		s.Scratch = append(s.Scratch, appstate.EncodeValue(v))
+	}
+	return s
+}
+
+func (cx *evalContext) txn() *types.Transaction {
+	return &cx.group[cx.index].Txn
+}
+
+func (cx *evalContext) push(v appstate.Value) error {
+	if len(cx.stack) >= maxStackDepth {
+		return fmt.Errorf("stack overflow")
+	}
+	cx.stack = append(cx.stack, v)
+	return nil
+}
+
+func (cx *evalContext) pushUint(v uint64) error {
+	return cx.push(appstate.UintValue(v))
+}
+
+func (cx *evalContext) pushBytes(v []byte) error {
+	return cx.push(appstate.BytesValue(v))
+}
+
+func (cx *evalContext) pushBool(b bool) error {
+	if b {
+		return cx.pushUint(1)
+	}
+	return cx.pushUint(0)
+}
+
+func (cx *evalContext) pop() (appstate.Value, error) {
+	if len(cx.stack) == 0 {
+		return appstate.Value{}, fmt.Errorf("stack underflow")
+	}
+	v := cx.stack[len(cx.stack)-1]
+	cx.stack = cx.stack[:len(cx.stack)-1]
+	return v, nil
+}
+
+func (cx *evalContext) popUint() (uint64, error) {
+	v, err := cx.pop()
+	if err != nil {
+		return 0, err
+	}
+	if v.Type != appstate.TypeUint {
+		return 0, fmt.Errorf("expected a uint, got bytes")
+	}
+	return v.Uint, nil
+}
+
+func (cx *evalContext) popBytes() ([]byte, error) {
+	v, err := cx.pop()
+	if err != nil {
+		return nil, err
+	}
+	if v.Type != appstate.TypeBytes {
+		return nil, fmt.Errorf("expected bytes, got a uint")
+	}
+	return v.Bytes, nil
+}
+
+// popUints pops two uints, returning them in the order they were pushed
+func (cx *evalContext) popUints() (uint64, uint64, error) {
+	b, err := cx.popUint()
+	if err != nil {
+		return 0, 0, err
+	}
+	a, err := cx.popUint()
+	return a, b, err
+}
+
+// popGroupIndex pops the index of a transaction of the group
+func (cx *evalContext) popGroupIndex() (int, error) {
+	i, err := cx.popUint()
+	if err != nil {
+		return 0, err
+	}
+	if i >= uint64(len(cx.group)) {
+		return 0, fmt.Errorf("transaction %d is not in the group of %d", i, len(cx.group))
+	}
+	return int(i), nil
+}
+
+func opErr(cx *evalContext) error {
+	return errors.New("err opcode executed")
+}
+
+func hashOp(cx *evalContext, hash func([]byte) []byte) error {
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	return cx.pushBytes(hash(b))
+}
+
+func opSHA256(cx *evalContext) error {
+	return hashOp(cx, func(b []byte) []byte {
+		sum := sha256.Sum256(b)
+		return sum[:]
+	})
+}
+
+func opKeccak256(cx *evalContext) error {
+	return hashOp(cx, func(b []byte) []byte {
+		h := sha3.NewLegacyKeccak256()
+		h.Write(b)
+		return h.Sum(nil)
+	})
+}
+
+func opSHA512_256(cx *evalContext) error {
+	return hashOp(cx, func(b []byte) []byte {
+		sum := sha512.Sum512_256(b)
+		return sum[:]
+	})
+}
+
+// opEd25519Verify verifies the signature of "ProgData" || program hash || data
+func opEd25519Verify(cx *evalContext) error {
+	pk, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	sig, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	data, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	if len(pk) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key")
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature")
+	}
+	programHash := crypto.AddressFromProgram(cx.program)
+	message := append(append(append([]byte{}, progDataPrefix...), programHash[:]...), data...)
+	return cx.pushBool(ed25519.Verify(pk, message, sig))
+}
+
+func arithmetic(cx *evalContext, f func(a, b uint64) (uint64, error)) error {
+	a, b, err := cx.popUints()
+	if err != nil {
+		return err
+	}
+	v, err := f(a, b)
+	if err != nil {
+		return err
+	}
+	return cx.pushUint(v)
+}
+
+func opPlus(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		sum, carry := bits.Add64(a, b, 0)
+		if carry != 0 {
+			return 0, fmt.Errorf("overflow")
+		}
+		return sum, nil
+	})
+}
+
+func opMinus(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		if b > a {
+			return 0, fmt.Errorf("result would be negative")
+		}
+		return a - b, nil
+	})
+}
+
+func opDiv(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		if b == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	})
+}
+
+func opMul(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		hi, lo := bits.Mul64(a, b)
+		if hi != 0 {
+			return 0, fmt.Errorf("overflow")
+		}
+		return lo, nil
+	})
+}
+
+func opModulo(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		if b == 0 {
+			return 0, fmt.Errorf("modulo by zero")
+		}
+		return a % b, nil
+	})
+}
+
+func boolOp(cx *evalContext, f func(a, b uint64) bool) error {
+	a, b, err := cx.popUints()
+	if err != nil {
+		return err
+	}
+	return cx.pushBool(f(a, b))
+}
+
+func opLt(cx *evalContext) error { return boolOp(cx, func(a, b uint64) bool { return a < b }) }
+func opGt(cx *evalContext) error { return boolOp(cx, func(a, b uint64) bool { return a > b }) }
+func opLe(cx *evalContext) error { return boolOp(cx, func(a, b uint64) bool { return a <= b }) }
+func opGe(cx *evalContext) error { return boolOp(cx, func(a, b uint64) bool { return a >= b }) }
+
+func opAnd(cx *evalContext) error {
+	return boolOp(cx, func(a, b uint64) bool { return a != 0 && b != 0 })
+}
+
+func opOr(cx *evalContext) error {
+	return boolOp(cx, func(a, b uint64) bool { return a != 0 || b != 0 })
+}
+
+func equal(cx *evalContext) (bool, error) {
+	b, err := cx.pop()
+	if err != nil {
+		return false, err
+	}
+	a, err := cx.pop()
+	if err != nil {
+		return false, err
+	}
+	if a.Type != b.Type {
+		return false, fmt.Errorf("cannot compare %s to %s", a.Type, b.Type)
+	}
+	return a.Equal(b), nil
+}
+
+func opEq(cx *evalContext) error {
+	eq, err := equal(cx)
+	if err != nil {
+		return err
+	}
+	return cx.pushBool(eq)
+}
+
+func opNeq(cx *evalContext) error {
+	eq, err := equal(cx)
+	if err != nil {
+		return err
+	}
+	return cx.pushBool(!eq)
+}
+
+func opNot(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	return cx.pushBool(v == 0)
+}
+
+func opLen(cx *evalContext) error {
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	return cx.pushUint(uint64(len(b)))
+}
+
+func opItob(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return cx.pushBytes(b)
+}
+
+func opBtoi(cx *evalContext) error {
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	if len(b) > 8 {
+		return fmt.Errorf("argument of %d bytes is longer than 8 bytes", len(b))
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return cx.pushUint(v)
+}
+
+func opBitOr(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) { return a | b, nil })
+}
+
+func opBitAnd(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) { return a & b, nil })
+}
+
+func opBitXor(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) { return a ^ b, nil })
+}
+
+func opBitNot(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	return cx.pushUint(^v)
+}
+
+// newUint128 returns the 128 bits integer made of hi and lo
+func newUint128(hi, lo uint64) *big.Int {
+	v := new(big.Int).SetUint64(hi)
+	return v.Lsh(v, 64).Or(v, new(big.Int).SetUint64(lo))
+}
+
+// pushUint128 pushes the high then the low 64 bits of v, which must fit in
+// 128 bits
+func (cx *evalContext) pushUint128(v *big.Int) error {
+	lo := new(big.Int).And(v, new(big.Int).SetUint64(^uint64(0)))
+	hi := new(big.Int).Rsh(v, 64)
+	if err := cx.pushUint(hi.Uint64()); err != nil {
+		return err
+	}
+	return cx.pushUint(lo.Uint64())
+}
+
+func opMulw(cx *evalContext) error {
+	a, b, err := cx.popUints()
+	if err != nil {
+		return err
+	}
+	hi, lo := bits.Mul64(a, b)
+	if err := cx.pushUint(hi); err != nil {
+		return err
+	}
+	return cx.pushUint(lo)
+}
+
+func opPlusw(cx *evalContext) error {
+	a, b, err := cx.popUints()
+	if err != nil {
+		return err
+	}
+	sum, carry := bits.Add64(a, b, 0)
+	if err := cx.pushUint(carry); err != nil {
+		return err
+	}
+	return cx.pushUint(sum)
+}
+
+func opDivModw(cx *evalContext) error {
+	var w [4]uint64
+	for i := len(w) - 1; i >= 0; i-- {
+		v, err := cx.popUint()
+		if err != nil {
+			return err
+		}
+		w[i] = v
+	}
+	divisor := newUint128(w[2], w[3])
+	if divisor.Sign() == 0 {
+		return fmt.Errorf("division by zero")
+	}
+	quotient, remainder := new(big.Int).QuoRem(newUint128(w[0], w[1]), divisor, new(big.Int))
+	if err := cx.pushUint128(quotient); err != nil {
+		return err
+	}
+	return cx.pushUint128(remainder)
+}
+
+func opShiftLeft(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		if b > 63 {
+			return 0, fmt.Errorf("shift of %d is beyond 63", b)
+		}
+		return a << b, nil
+	})
+}
+
+func opShiftRight(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		if b > 63 {
+			return 0, fmt.Errorf("shift of %d is beyond 63", b)
+		}
+		return a >> b, nil
+	})
+}
+
+func opSqrt(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	return cx.pushUint(new(big.Int).Sqrt(new(big.Int).SetUint64(v)).Uint64())
+}
+
+// opBitLen pushes the length in bits of a uint, or of bytes read as a
+// big-endian unsigned integer
+func opBitLen(cx *evalContext) error {
+	v, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	if v.Type == appstate.TypeUint {
+		return cx.pushUint(uint64(bits.Len64(v.Uint)))
+	}
+	return cx.pushUint(uint64(new(big.Int).SetBytes(v.Bytes).BitLen()))
+}
+
+// exp returns a to the power of b, failing when it needs more than
+// maxBits bits
+func exp(a, b uint64, maxBits int) (*big.Int, error) {
+	if a == 0 && b == 0 {
+		return nil, fmt.Errorf("0^0 is undefined")
+	}
+	if a > 1 && b >= uint64(maxBits) {
+		return nil, fmt.Errorf("overflow")
+	}
+	v := new(big.Int).Exp(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b), nil)
+	if v.BitLen() > maxBits {
+		return nil, fmt.Errorf("overflow")
+	}
+	return v, nil
+}
+
+func opExp(cx *evalContext) error {
+	return arithmetic(cx, func(a, b uint64) (uint64, error) {
+		v, err := exp(a, b, 64)
+		if err != nil {
+			return 0, err
+		}
+		return v.Uint64(), nil
+	})
+}
+
+func opExpw(cx *evalContext) error {
+	a, b, err := cx.popUints()
+	if err != nil {
+		return err
+	}
+	v, err := exp(a, b, 128)
+	if err != nil {
+		return err
+	}
+	return cx.pushUint128(v)
+}
+
+func opIntcBlock(cx *evalContext) error {
+	_, ints, err := readIntConstBlock(cx.program, cx.pc)
+	cx.intc = ints
+	return err
+}
+
+func intc(cx *evalContext, i int) error {
+	if i >= len(cx.intc) {
+		return fmt.Errorf("int constant %d is not defined", i)
+	}
+	return cx.pushUint(cx.intc[i])
+}
+
+func opIntc(cx *evalContext) error {
+	return intc(cx, int(cx.program[cx.pc+1]))
+}
+
+func opIntcN(i int) func(*evalContext) error {
+	return func(cx *evalContext) error {
+		return intc(cx, i)
+	}
+}
+
+func opBytecBlock(cx *evalContext) error {
+	_, byteArrays, err := readByteConstBlock(cx.program, cx.pc)
+	cx.bytec = byteArrays
+	return err
+}
+
+func bytec(cx *evalContext, i int) error {
+	if i >= len(cx.bytec) {
+		return fmt.Errorf("byte constant %d is not defined", i)
+	}
+	return cx.pushBytes(cx.bytec[i])
+}
+
+func opBytec(cx *evalContext) error {
+	return bytec(cx, int(cx.program[cx.pc+1]))
+}
+
+func opBytecN(i int) func(*evalContext) error {
+	return func(cx *evalContext) error {
+		return bytec(cx, i)
+	}
+}
+
+func opPushInt(cx *evalContext) error {
+	v, _ := binary.Uvarint(cx.program[cx.pc+1:])
+	return cx.pushUint(v)
+}
+
+func opPushBytes(cx *evalContext) error {
+	length, n := binary.Uvarint(cx.program[cx.pc+1:])
+	start := cx.pc + 1 + n
+	return cx.pushBytes(cx.program[start : start+int(length)])
+}
+
+func arg(cx *evalContext, i int) error {
+	if i >= len(cx.args) {
+		return fmt.Errorf("argument %d is not defined", i)
+	}
+	return cx.pushBytes(cx.args[i])
+}
+
+func opArg(cx *evalContext) error {
+	return arg(cx, int(cx.program[cx.pc+1]))
+}
+
+func opArgN(i int) func(*evalContext) error {
+	return func(cx *evalContext) error {
+		return arg(cx, i)
+	}
+}
+
+func opTxn(cx *evalContext) error {
+	v, err := cx.txnField(cx.index, cx.program[cx.pc+1], 0, false)
+	if err != nil {
+		return err
+	}
+	return cx.push(v)
+}
+
+func opGtxn(cx *evalContext) error {
+	v, err := cx.txnField(int(cx.program[cx.pc+1]), cx.program[cx.pc+2], 0, false)
+	if err != nil {
+		return err
+	}
+	return cx.push(v)
+}
+
+func opTxna(cx *evalContext) error {
+	v, err := cx.txnField(cx.index, cx.program[cx.pc+1], int(cx.program[cx.pc+2]), true)
+	if err != nil {
+		return err
+	}
+	return cx.push(v)
+}
+
+func opGtxna(cx *evalContext) error {
+	v, err := cx.txnField(int(cx.program[cx.pc+1]), cx.program[cx.pc+2], int(cx.program[cx.pc+3]), true)
+	if err != nil {
+		return err
+	}
+	return cx.push(v)
+}
+
+func opGtxns(cx *evalContext) error {
+	i, err := cx.popGroupIndex()
+	if err != nil {
+		return err
+	}
+	v, err := cx.txnField(i, cx.program[cx.pc+1], 0, false)
+	if err != nil {
+		return err
+	}
+	return cx.push(v)
+}
+
+func opGtxnsa(cx *evalContext) error {
+	i, err := cx.popGroupIndex()
+	if err != nil {
+		return err
+	}
+	v, err := cx.txnField(i, cx.program[cx.pc+1], int(cx.program[cx.pc+2]), true)
+	if err != nil {
+		return err
+	}
+	return cx.push(v)
+}
+
+// pastEffects returns the effects of the transaction at groupIndex, which
+// must come before the running one
+func (cx *evalContext) pastEffects(groupIndex int) (effects, error) {
+	if groupIndex >= len(cx.group) {
+		return effects{}, fmt.Errorf("transaction %d is not in the group of %d", groupIndex, len(cx.group))
+	}
+	if groupIndex >= cx.index {
+		return effects{}, fmt.Errorf("transaction %d does not come before transaction %d", groupIndex, cx.index)
+	}
+	return cx.past[groupIndex], nil
+}
+
+// gload pushes the scratch slot of the application call at groupIndex
+func (cx *evalContext) gload(groupIndex int, slot byte) error {
+	past, err := cx.pastEffects(groupIndex)
+	if err != nil {
+		return err
+	}
+	if past.scratch == nil {
+		return fmt.Errorf("transaction %d is not an application call", groupIndex)
+	}
+	return cx.push(past.scratch[slot])
+}
+
+func opGload(cx *evalContext) error {
+	return cx.gload(int(cx.program[cx.pc+1]), cx.program[cx.pc+2])
+}
+
+func opGloads(cx *evalContext) error {
+	i, err := cx.popGroupIndex()
+	if err != nil {
+		return err
+	}
+	return cx.gload(i, cx.program[cx.pc+1])
+}
+
+// gaid pushes the index of the asset or application created by the
+// transaction at groupIndex
+func (cx *evalContext) gaid(groupIndex int) error {
+	past, err := cx.pastEffects(groupIndex)
+	if err != nil {
+		return err
+	}
+	if past.created == 0 {
+		return fmt.Errorf("transaction %d did not create an asset or application", groupIndex)
+	}
+	return cx.pushUint(past.created)
+}
+
+func opGaid(cx *evalContext) error {
+	return cx.gaid(int(cx.program[cx.pc+1]))
+}
+
+func opGaids(cx *evalContext) error {
+	i, err := cx.popGroupIndex()
+	if err != nil {
+		return err
+	}
+	return cx.gaid(i)
+}
+
+func uintValue(v uint64) (appstate.Value, error) {
+	return appstate.UintValue(v), nil
+}
+
+func bytesValue(b []byte) (appstate.Value, error) {
+	return appstate.BytesValue(b), nil
+}
+
+func boolValue(b bool) (appstate.Value, error) {
+	if b {
+		return uintValue(1)
+	}
+	return uintValue(0)
+}
+
+// txnField returns field of the transaction at groupIndex. Array fields are
+// indexed by arrayIndex and can only be read when array is set.
+func (cx *evalContext) txnField(groupIndex int, field byte, arrayIndex int, array bool) (appstate.Value, error) {
+	if groupIndex >= len(cx.group) {
+		return appstate.Value{}, fmt.Errorf("transaction %d is not in the group of %d", groupIndex, len(cx.group))
+	}
+	name, err := fieldName(txnFields, field)
+	if err != nil {
+		return appstate.Value{}, err
+	}
+	if fieldVersion(firstTxnField, field) > cx.d.version {
+		return appstate.Value{}, fmt.Errorf("field %s is not available in version %d", name, cx.d.version)
+	}
+	isArray := name == "ApplicationArgs" || name == "Accounts" || name == "Assets" || name == "Applications"
+	if isArray != array {
+		if array {
+			return appstate.Value{}, fmt.Errorf("field %s is not an array", name)
+		}
+		return appstate.Value{}, fmt.Errorf("field %s is an array", name)
+	}
+
+	txn := &cx.group[groupIndex].Txn
+	switch name {
+	case "Sender":
+		return bytesValue(txn.Sender[:])
+	case "Fee":
+		return uintValue(uint64(txn.Fee))
+	case "FirstValid":
+		return uintValue(uint64(txn.FirstValid))
+	case "FirstValidTime":
+		return appstate.Value{}, fmt.Errorf("field FirstValidTime is not supported")
+	case "LastValid":
+		return uintValue(uint64(txn.LastValid))
+	case "Note":
+		return bytesValue(txn.Note)
+	case "Lease":
+		return bytesValue(txn.Lease[:])
+	case "Receiver":
+		return bytesValue(txn.Receiver[:])
+	case "Amount":
+		return uintValue(uint64(txn.Amount))
+	case "CloseRemainderTo":
+		return bytesValue(txn.CloseRemainderTo[:])
+	case "VotePK":
+		return bytesValue(txn.VotePK[:])
+	case "SelectionPK":
+		return bytesValue(txn.SelectionPK[:])
+	case "VoteFirst":
+		return uintValue(uint64(txn.VoteFirst))
+	case "VoteLast":
+		return uintValue(uint64(txn.VoteLast))
+	case "VoteKeyDilution":
+		return uintValue(txn.VoteKeyDilution)
+	case "Type":
+		return bytesValue([]byte(txn.Type))
+	case "TypeEnum":
+		return uintValue(typeEnum(txn.Type))
+	case "XferAsset":
+		return uintValue(uint64(txn.XferAsset))
+	case "AssetAmount":
+		return uintValue(txn.AssetAmount)
+	case "AssetSender":
+		return bytesValue(txn.AssetSender[:])
+	case "AssetReceiver":
+		return bytesValue(txn.AssetReceiver[:])
+	case "AssetCloseTo":
+		return bytesValue(txn.AssetCloseTo[:])
+	case "GroupIndex":
+		return uintValue(uint64(groupIndex))
+	case "TxID":
+		return bytesValue(crypto.TransactionID(*txn))
+	case "ApplicationID":
+		return uintValue(uint64(txn.ApplicationID))
+	case "OnCompletion":
+		return uintValue(uint64(txn.OnCompletion))
+	case "ApplicationArgs":
+		if arrayIndex >= len(txn.ApplicationArgs) {
+			return appstate.Value{}, fmt.Errorf("invalid ApplicationArgs index %d", arrayIndex)
+		}
+		return bytesValue(txn.ApplicationArgs[arrayIndex])
+	case "NumAppArgs":
+		return uintValue(uint64(len(txn.ApplicationArgs)))
+	case "Accounts":
+		if arrayIndex == 0 {
+			return bytesValue(txn.Sender[:])
+		}
+		if arrayIndex > len(txn.Accounts) {
+			return appstate.Value{}, fmt.Errorf("invalid Accounts index %d", arrayIndex)
+		}
+		return bytesValue(txn.Accounts[arrayIndex-1][:])
+	case "NumAccounts":
+		return uintValue(uint64(len(txn.Accounts)))
+	case "ApprovalProgram":
+		return bytesValue(txn.ApprovalProgram)
+	case "ClearStateProgram":
+		return bytesValue(txn.ClearStateProgram)
+	case "RekeyTo":
+		return bytesValue(txn.RekeyTo[:])
+	case "ConfigAsset":
+		return uintValue(uint64(txn.ConfigAsset))
+	case "ConfigAssetTotal":
+		return uintValue(txn.AssetParams.Total)
+	case "ConfigAssetDecimals":
+		return uintValue(uint64(txn.AssetParams.Decimals))
+	case "ConfigAssetDefaultFrozen":
+		return boolValue(txn.AssetParams.DefaultFrozen)
+	case "ConfigAssetUnitName":
+		return bytesValue([]byte(txn.AssetParams.UnitName))
+	case "ConfigAssetName":
+		return bytesValue([]byte(txn.AssetParams.AssetName))
+	case "ConfigAssetURL":
+		return bytesValue([]byte(txn.AssetParams.URL))
+	case "ConfigAssetMetadataHash":
+		return bytesValue(txn.AssetParams.MetadataHash[:])
+	case "ConfigAssetManager":
+		return bytesValue(txn.AssetParams.Manager[:])
+	case "ConfigAssetReserve":
+		return bytesValue(txn.AssetParams.Reserve[:])
+	case "ConfigAssetFreeze":
+		return bytesValue(txn.AssetParams.Freeze[:])
+	case "ConfigAssetClawback":
+		return bytesValue(txn.AssetParams.Clawback[:])
+	case "FreezeAsset":
+		return uintValue(uint64(txn.FreezeAsset))
+	case "FreezeAssetAccount":
+		return bytesValue(txn.FreezeAccount[:])
+	case "FreezeAssetFrozen":
+		return boolValue(txn.AssetFrozen)
+	case "Assets":
+		if arrayIndex >= len(txn.ForeignAssets) {
+			return appstate.Value{}, fmt.Errorf("invalid Assets index %d", arrayIndex)
+		}
+		return uintValue(uint64(txn.ForeignAssets[arrayIndex]))
+	case "NumAssets":
+		return uintValue(uint64(len(txn.ForeignAssets)))
+	case "Applications":
+		if arrayIndex == 0 {
+			return uintValue(uint64(txn.ApplicationID))
+		}
+		if arrayIndex > len(txn.ForeignApps) {
+			return appstate.Value{}, fmt.Errorf("invalid Applications index %d", arrayIndex)
+		}
+		return uintValue(uint64(txn.ForeignApps[arrayIndex-1]))
+	case "NumApplications":
+		return uintValue(uint64(len(txn.ForeignApps)))
+	case "GlobalNumUint":
+		return uintValue(txn.GlobalStateSchema.NumUint)
+	case "GlobalNumByteSlice":
+		return uintValue(txn.GlobalStateSchema.NumByteSlice)
+	case "LocalNumUint":
+		return uintValue(txn.LocalStateSchema.NumUint)
+	case "LocalNumByteSlice":
+		return uintValue(txn.LocalStateSchema.NumByteSlice)
+	case "ExtraProgramPages":
+		// transactions of this SDK cannot ask for extra program pages
+		return uintValue(0)
+	}
+	return appstate.Value{}, fmt.Errorf("field %s is not supported", name)
+}
+
+// typeEnum returns the TypeEnum of a transaction type
+func typeEnum(t types.TxType) uint64 {
+	switch t {
+	case types.PaymentTx:
+		return 1
+	case types.KeyRegistrationTx:
+		return 2
+	case types.AssetConfigTx:
+		return 3
+	case types.AssetTransferTx:
+		return 4
+	case types.AssetFreezeTx:
+		return 5
+	case types.ApplicationCallTx:
+		return 6
+	}
+	return 0
+}
+
+func opGlobal(cx *evalContext) error {
+	field := cx.program[cx.pc+1]
+	name, err := fieldName(globalFields, field)
+	if err != nil {
+		return err
+	}
+	if fieldVersion(firstGlobalField, field) > cx.d.version {
+		return fmt.Errorf("field %s is not available in version %d", name, cx.d.version)
+	}
+	switch name {
+	case "MinTxnFee":
+		return cx.pushUint(cx.params.MinTxnFee)
+	case "MinBalance":
+		return cx.pushUint(cx.params.MinBalance)
+	case "MaxTxnLife":
+		return cx.pushUint(cx.params.MaxTxnLife)
+	case "ZeroAddress":
+		return cx.pushBytes(make([]byte, len(types.Address{})))
+	case "GroupSize":
+		return cx.pushUint(uint64(len(cx.group)))
+	case "LogicSigVersion":
+		return cx.pushUint(cx.params.LogicSigVersion)
+	}
+	if cx.mode != modeApp {
+		return fmt.Errorf("field %s is only available in application programs", name)
+	}
+	switch name {
+	case "Round":
+		return cx.pushUint(cx.round)
+	case "LatestTimestamp":
+		if cx.timestamp < 0 {
+			return fmt.Errorf("latest timestamp %d is negative", cx.timestamp)
+		}
+		return cx.pushUint(uint64(cx.timestamp))
+	case "CurrentApplicationID":
+		return cx.pushUint(cx.appID)
+	case "CreatorAddress":
+		app, ok := cx.st.apps[cx.appID]
+		if !ok {
+			return fmt.Errorf("application %d does not exist", cx.appID)
+		}
+		return cx.pushBytes(app.creator[:])
+	}
+	return fmt.Errorf("field %s is not supported", name)
+}
+
+func opLoad(cx *evalContext) error {
+	return cx.push(cx.scratch[cx.program[cx.pc+1]])
+}
+
+func opStore(cx *evalContext) error {
+	v, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	i := int(cx.program[cx.pc+1])
+	cx.scratch[i] = v
+	if i >= cx.scratchN {
+		cx.scratchN = i + 1
+	}
+	return nil
+}
+
+// branch jumps to the target of the branch instruction at pc
+func (cx *evalContext) branch() error {
+	offset := int16(binary.BigEndian.Uint16(cx.program[cx.pc+1:]))
+	if offset < 0 && cx.d.version < backBranchVersion {
+		return fmt.Errorf("backward branches need version %d or later", backBranchVersion)
+	}
+	target := cx.pc + 3 + int(offset)
+	if target > len(cx.program) {
+		return fmt.Errorf("branch to pc %d past the end of the program", target)
+	}
+	if _, ok := cx.d.instrs[target]; !ok && target < len(cx.program) {
+		return fmt.Errorf("branch to pc %d which is not an instruction", target)
+	}
+	cx.nextPC = target
+	return nil
+}
+
+func opBnz(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil || v == 0 {
+		return err
+	}
+	return cx.branch()
+}
+
+func opBz(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil || v != 0 {
+		return err
+	}
+	return cx.branch()
+}
+
+func opB(cx *evalContext) error {
+	return cx.branch()
+}
+
+func opCallSub(cx *evalContext) error {
+	cx.callstack = append(cx.callstack, cx.nextPC)
+	return cx.branch()
+}
+
+func opRetSub(cx *evalContext) error {
+	if len(cx.callstack) == 0 {
+		return fmt.Errorf("retsub outside of a subroutine")
+	}
+	cx.nextPC = cx.callstack[len(cx.callstack)-1]
+	cx.callstack = cx.callstack[:len(cx.callstack)-1]
+	return nil
+}
+
+func opReturn(cx *evalContext) error {
+	v, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	cx.stack = []appstate.Value{v}
+	cx.returned = true
+	return nil
+}
+
+func opPop(cx *evalContext) error {
+	_, err := cx.pop()
+	return err
+}
+
+func opDup(cx *evalContext) error {
+	if len(cx.stack) < 1 {
+		return fmt.Errorf("stack underflow")
+	}
+	return cx.push(cx.stack[len(cx.stack)-1])
+}
+
+func opDup2(cx *evalContext) error {
+	if len(cx.stack) < 2 {
+		return fmt.Errorf("stack underflow")
+	}
+	a, b := cx.stack[len(cx.stack)-2], cx.stack[len(cx.stack)-1]
+	if err := cx.push(a); err != nil {
+		return err
+	}
+	return cx.push(b)
+}
+
+func opAssert(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	if v == 0 {
+		return fmt.Errorf("assertion failed")
+	}
+	return nil
+}
+
+func opDig(cx *evalContext) error {
+	n := int(cx.program[cx.pc+1])
+	if n >= len(cx.stack) {
+		return fmt.Errorf("stack underflow")
+	}
+	return cx.push(cx.stack[len(cx.stack)-1-n])
+}
+
+func opSwap(cx *evalContext) error {
+	if len(cx.stack) < 2 {
+		return fmt.Errorf("stack underflow")
+	}
+	last := len(cx.stack) - 1
+	cx.stack[last], cx.stack[last-1] = cx.stack[last-1], cx.stack[last]
+	return nil
+}
+
+// opSelect pops A, B and C and pushes B when C is not zero, A otherwise
+func opSelect(cx *evalContext) error {
+	c, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	b, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	a, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	if c != 0 {
+		return cx.push(b)
+	}
+	return cx.push(a)
+}
+
+func opConcat(cx *evalContext) error {
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	a, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	if len(a)+len(b) > maxStringSize {
+		return fmt.Errorf("result is longer than %d bytes", maxStringSize)
+	}
+	return cx.pushBytes(append(append([]byte{}, a...), b...))
+}
+
+func substring(cx *evalContext, b []byte, start, end uint64) error {
+	if end < start {
+		return fmt.Errorf("end %d is before start %d", end, start)
+	}
+	if end > uint64(len(b)) {
+		return fmt.Errorf("end %d is past the %d bytes", end, len(b))
+	}
+	return cx.pushBytes(b[start:end])
+}
+
+func opSubstring(cx *evalContext) error {
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	return substring(cx, b, uint64(cx.program[cx.pc+1]), uint64(cx.program[cx.pc+2]))
+}
+
+func opSubstring3(cx *evalContext) error {
+	start, end, err := cx.popUints()
+	if err != nil {
+		return err
+	}
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	return substring(cx, b, start, end)
+}
+
+// bitAt locates bit i of v: bit 0 is the lowest bit of a uint and the
+// highest bit of the first byte of bytes
+func bitAt(v appstate.Value, i uint64) (byteIndex int, mask uint64, err error) {
+	if v.Type == appstate.TypeUint {
+		if i > 63 {
+			return 0, 0, fmt.Errorf("bit %d is beyond 63", i)
+		}
+		return 0, 1 << i, nil
+	}
+	if i/8 >= uint64(len(v.Bytes)) {
+		return 0, 0, fmt.Errorf("bit %d is past the %d bytes", i, len(v.Bytes))
+	}
+	return int(i / 8), 0x80 >> (i % 8), nil
+}
+
+func opGetBit(cx *evalContext) error {
+	i, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	v, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	byteIndex, mask, err := bitAt(v, i)
+	if err != nil {
+		return err
+	}
+	if v.Type == appstate.TypeUint {
+		return cx.pushBool(v.Uint&mask != 0)
+	}
+	return cx.pushBool(uint64(v.Bytes[byteIndex])&mask != 0)
+}
+
+func opSetBit(cx *evalContext) error {
+	bit, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	if bit > 1 {
+		return fmt.Errorf("bit value %d is not 0 or 1", bit)
+	}
+	i, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	v, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	byteIndex, mask, err := bitAt(v, i)
+	if err != nil {
+		return err
+	}
+	if v.Type == appstate.TypeUint {
+		if bit == 1 {
+			return cx.pushUint(v.Uint | mask)
+		}
+		return cx.pushUint(v.Uint &^ mask)
+	}
+	b := append([]byte{}, v.Bytes...)
+	if bit == 1 {
+		b[byteIndex] |= byte(mask)
+	} else {
+		b[byteIndex] &^= byte(mask)
+	}
+	return cx.pushBytes(b)
+}
+
+func opGetByte(cx *evalContext) error {
+	i, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	if i >= uint64(len(b)) {
+		return fmt.Errorf("byte %d is past the %d bytes", i, len(b))
+	}
+	return cx.pushUint(uint64(b[i]))
+}
+
+func opSetByte(cx *evalContext) error {
+	v, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	if v > 255 {
+		return fmt.Errorf("value %d does not fit in a byte", v)
+	}
+	i, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	if i >= uint64(len(b)) {
+		return fmt.Errorf("byte %d is past the %d bytes", i, len(b))
+	}
+	b = append([]byte{}, b...)
+	b[i] = byte(v)
+	return cx.pushBytes(b)
+}
+
+// account returns the account referred to by ref, the index of an account
+// in the Accounts of the transaction, 0 being its sender. From
+// directRefVersion, ref can also be the address of one of these accounts.
+func (cx *evalContext) account(ref appstate.Value) (types.Address, error) {
+	txn := cx.txn()
+	if ref.Type == appstate.TypeUint {
+		if ref.Uint == 0 {
+			return txn.Sender, nil
+		}
+		if ref.Uint > uint64(len(txn.Accounts)) {
+			return types.Address{}, fmt.Errorf("invalid Accounts index %d", ref.Uint)
+		}
+		return txn.Accounts[ref.Uint-1], nil
+	}
+	if cx.d.version < directRefVersion {
+		return types.Address{}, fmt.Errorf("expected a uint, got bytes")
+	}
+	var addr types.Address
+	if len(ref.Bytes) != len(addr) {
+		return addr, fmt.Errorf("expected an address, got %d bytes", len(ref.Bytes))
+	}
+	copy(addr[:], ref.Bytes)
+	if addr == txn.Sender {
+		return addr, nil
+	}
+	for _, a := range txn.Accounts {
+		if a == addr {
+			return addr, nil
+		}
+	}
+	return addr, fmt.Errorf("invalid Account reference %s", addr)
+}
+
+// app returns the application referred to by ref, 0 being the application
+// called. Before directRefVersion, ref is an application index, or the
+// index of an application in the ForeignApps of the transaction when
+// foreign is set. From then on, ref can be either.
+func (cx *evalContext) app(ref uint64, foreign bool) (uint64, error) {
+	txn := cx.txn()
+	if ref == 0 {
+		return cx.appID, nil
+	}
+	if cx.d.version < directRefVersion && !foreign {
+		return ref, nil
+	}
+	if ref <= uint64(len(txn.ForeignApps)) {
+		return uint64(txn.ForeignApps[ref-1]), nil
+	}
+	if cx.d.version >= directRefVersion {
+		for _, id := range txn.ForeignApps {
+			if uint64(id) == ref {
+				return ref, nil
+			}
+		}
+		if ref == cx.appID {
+			return ref, nil
+		}
+		return 0, fmt.Errorf("invalid App reference %d", ref)
+	}
+	return 0, fmt.Errorf("invalid ForeignApps index %d", ref)
+}
+
+// asset returns the asset referred to by ref. Before directRefVersion, ref
+// is an asset index, or the index of an asset in the ForeignAssets of the
+// transaction when foreign is set. From then on, ref can be either.
+func (cx *evalContext) asset(ref uint64, foreign bool) (uint64, error) {
+	txn := cx.txn()
+	if cx.d.version < directRefVersion && !foreign {
+		return ref, nil
+	}
+	if ref < uint64(len(txn.ForeignAssets)) {
+		return uint64(txn.ForeignAssets[ref]), nil
+	}
+	if cx.d.version >= directRefVersion {
+		for _, id := range txn.ForeignAssets {
+			if uint64(id) == ref {
+				return ref, nil
+			}
+		}
+		return 0, fmt.Errorf("invalid Asset reference %d", ref)
+	}
+	return 0, fmt.Errorf("invalid ForeignAssets index %d", ref)
+}
+
+// localState returns the local state of addr in the application called,
+// which programs can modify
+func (cx *evalContext) localState(addr types.Address) (appstate.State, error) {
+	if state, ok := cx.locals[addr]; ok {
+		return state, nil
+	}
+	local := cx.st.localState(addr, cx.appID)
+	if local == nil {
+		return nil, fmt.Errorf("account %s has not opted in application %d", addr, cx.appID)
+	}
+	state := local.kv.Clone()
+	cx.locals[addr] = state
+	return state, nil
+}
+
+// readLocal returns the local state of addr in appID, if addr opted in
+func (cx *evalContext) readLocal(addr types.Address, appID uint64) (appstate.State, bool) {
+	if appID == cx.appID {
+		state, err := cx.localState(addr)
+		return state, err == nil
+	}
+	local := cx.st.localState(addr, appID)
+	if local == nil {
+		return nil, false
+	}
+	return local.kv, true
+}
+
+// readGlobal returns the global state of appID, if it exists
+func (cx *evalContext) readGlobal(appID uint64) (appstate.State, bool) {
+	if appID == cx.appID {
+		return cx.global, true
+	}
+	app, ok := cx.st.apps[appID]
+	if !ok {
+		return nil, false
+	}
+	return app.global, true
+}
+
+// pushLookup pushes the value of key in state, or a zero uint, and whether
+// the key exists when ex is set
+func (cx *evalContext) pushLookup(state appstate.State, key []byte, ex bool) error {
+	v, ok := state[string(key)]
+	if !ok {
+		v = appstate.UintValue(0)
+	}
+	if err := cx.push(v); err != nil || !ex {
+		return err
+	}
+	return cx.pushBool(ok)
+}
+
+// checkPut checks that key and v can be stored in application state
+func (cx *evalContext) checkPut(key []byte, v appstate.Value) error {
+	if len(key) > cx.params.MaxAppKeyLen {
+		return fmt.Errorf("key of %d bytes is longer than %d bytes", len(key), cx.params.MaxAppKeyLen)
+	}
+	if v.Type == appstate.TypeBytes && len(v.Bytes) > cx.params.MaxAppBytesValueLen {
+		return fmt.Errorf("value of %d bytes is longer than %d bytes", len(v.Bytes), cx.params.MaxAppBytesValueLen)
+	}
+	return nil
+}
+
+func opBalance(cx *evalContext) error {
+	ref, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	addr, err := cx.account(ref)
+	if err != nil {
+		return err
+	}
+	var balance uint64
+	if account, ok := cx.st.accounts[addr]; ok {
+		balance = account.amount
+	}
+	return cx.pushUint(balance)
+}
+
+func opMinBalance(cx *evalContext) error {
+	ref, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	addr, err := cx.account(ref)
+	if err != nil {
+		return err
+	}
+	return cx.pushUint(cx.st.minBalance(cx.params, addr))
+}
+
+func opAppOptedIn(cx *evalContext) error {
+	appRef, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	accountRef, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	addr, err := cx.account(accountRef)
+	if err != nil {
+		return err
+	}
+	appID, err := cx.app(appRef, false)
+	if err != nil {
+		return err
+	}
+	return cx.pushBool(cx.st.localState(addr, appID) != nil)
+}
+
+func opAppLocalGet(cx *evalContext) error {
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	ref, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	addr, err := cx.account(ref)
+	if err != nil {
+		return err
+	}
+	state, err := cx.localState(addr)
+	if err != nil {
+		return err
+	}
+	return cx.pushLookup(state, key, false)
+}
+
+func opAppLocalGetEx(cx *evalContext) error {
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	appRef, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	accountRef, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	addr, err := cx.account(accountRef)
+	if err != nil {
+		return err
+	}
+	appID, err := cx.app(appRef, false)
+	if err != nil {
+		return err
+	}
+	state, _ := cx.readLocal(addr, appID)
+	return cx.pushLookup(state, key, true)
+}
+
+func opAppGlobalGet(cx *evalContext) error {
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	return cx.pushLookup(cx.global, key, false)
+}
+
+func opAppGlobalGetEx(cx *evalContext) error {
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	ref, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	appID, err := cx.app(ref, true)
+	if err != nil {
+		return err
+	}
+	state, _ := cx.readGlobal(appID)
+	return cx.pushLookup(state, key, true)
+}
+
+func opAppLocalPut(cx *evalContext) error {
+	v, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	ref, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	if err := cx.checkPut(key, v); err != nil {
+		return err
+	}
+	addr, err := cx.account(ref)
+	if err != nil {
+		return err
+	}
+	state, err := cx.localState(addr)
+	if err != nil {
+		return err
+	}
+	state[string(key)] = v
+	return nil
+}
+
+func opAppGlobalPut(cx *evalContext) error {
+	v, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	if err := cx.checkPut(key, v); err != nil {
+		return err
+	}
+	cx.global[string(key)] = v
+	return nil
+}
+
+func opAppLocalDel(cx *evalContext) error {
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	ref, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	addr, err := cx.account(ref)
+	if err != nil {
+		return err
+	}
+	state, err := cx.localState(addr)
+	if err != nil {
+		return err
+	}
+	delete(state, string(key))
+	return nil
+}
+
+func opAppGlobalDel(cx *evalContext) error {
+	key, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	delete(cx.global, string(key))
+	return nil
+}
+
+// pushField pushes v and whether it exists, zero values standing for
+// fields that do not exist
+func (cx *evalContext) pushField(v appstate.Value, exists bool) error {
+	if !exists {
+		v = appstate.UintValue(0)
+	}
+	if err := cx.push(v); err != nil {
+		return err
+	}
+	return cx.pushBool(exists)
+}
+
+func opAssetHoldingGet(cx *evalContext) error {
+	field, err := fieldName(holdingFields, cx.program[cx.pc+1])
+	if err != nil {
+		return err
+	}
+	assetRef, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	accountRef, err := cx.pop()
+	if err != nil {
+		return err
+	}
+	addr, err := cx.account(accountRef)
+	if err != nil {
+		return err
+	}
+	assetID, err := cx.asset(assetRef, false)
+	if err != nil {
+		return err
+	}
+	account, ok := cx.st.accounts[addr]
+	if !ok {
+		return cx.pushField(appstate.Value{}, false)
+	}
+	h, ok := account.holdings[assetID]
+	if !ok {
+		return cx.pushField(appstate.Value{}, false)
+	}
+	var v appstate.Value
+	switch field {
+	case "AssetBalance":
+		v, _ = uintValue(h.amount)
+	case "AssetFrozen":
+		v, _ = boolValue(h.frozen)
+	}
+	return cx.pushField(v, true)
+}
+
+func opAssetParamsGet(cx *evalContext) error {
+	field, err := fieldName(paramsFields, cx.program[cx.pc+1])
+	if err != nil {
+		return err
+	}
+	ref, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	assetID, err := cx.asset(ref, true)
+	if err != nil {
+		return err
+	}
+	a, ok := cx.st.assets[assetID]
+	if !ok {
+		return cx.pushField(appstate.Value{}, false)
+	}
+	params := a.params
+	var v appstate.Value
+	switch field {
+	case "AssetTotal":
+		v, _ = uintValue(params.Total)
+	case "AssetDecimals":
+		v, _ = uintValue(uint64(params.Decimals))
+	case "AssetDefaultFrozen":
+		v, _ = boolValue(params.DefaultFrozen)
+	case "AssetUnitName":
+		v, _ = bytesValue([]byte(params.UnitName))
+	case "AssetName":
+		v, _ = bytesValue([]byte(params.AssetName))
+	case "AssetURL":
+		v, _ = bytesValue([]byte(params.URL))
+	case "AssetMetadataHash":
+		v, _ = bytesValue(params.MetadataHash[:])
+	case "AssetManager":
+		v, _ = bytesValue(params.Manager[:])
+	case "AssetReserve":
+		v, _ = bytesValue(params.Reserve[:])
+	case "AssetFreeze":
+		v, _ = bytesValue(params.Freeze[:])
+	case "AssetClawback":
+		v, _ = bytesValue(params.Clawback[:])
+	}
+	return cx.pushField(v, true)
+}
+
+// maxByteMathSize is the longest input of byte arithmetic and comparisons
+const maxByteMathSize = 64
+
+// popByteInts pops two big-endian unsigned integers, returning them in the
+// order they were pushed
+func (cx *evalContext) popByteInts() (*big.Int, *big.Int, error) {
+	var ints [2]*big.Int
+	for i := len(ints) - 1; i >= 0; i-- {
+		b, err := cx.popBytes()
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(b) > maxByteMathSize {
+			return nil, nil, fmt.Errorf("argument of %d bytes is longer than %d bytes", len(b), maxByteMathSize)
+		}
+		ints[i] = new(big.Int).SetBytes(b)
+	}
+	return ints[0], ints[1], nil
+}
+
+func byteArithmetic(cx *evalContext, f func(a, b *big.Int) (*big.Int, error)) error {
+	a, b, err := cx.popByteInts()
+	if err != nil {
+		return err
+	}
+	v, err := f(a, b)
+	if err != nil {
+		return err
+	}
+	return cx.pushBytes(v.Bytes())
+}
+
+func opBytesPlus(cx *evalContext) error {
+	return byteArithmetic(cx, func(a, b *big.Int) (*big.Int, error) {
+		return a.Add(a, b), nil
+	})
+}
+
+func opBytesMinus(cx *evalContext) error {
+	return byteArithmetic(cx, func(a, b *big.Int) (*big.Int, error) {
+		if a.Cmp(b) < 0 {
+			return nil, fmt.Errorf("result would be negative")
+		}
+		return a.Sub(a, b), nil
+	})
+}
+
+func opBytesDiv(cx *evalContext) error {
+	return byteArithmetic(cx, func(a, b *big.Int) (*big.Int, error) {
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a.Quo(a, b), nil
+	})
+}
+
+func opBytesMul(cx *evalContext) error {
+	return byteArithmetic(cx, func(a, b *big.Int) (*big.Int, error) {
+		return a.Mul(a, b), nil
+	})
+}
+
+func opBytesModulo(cx *evalContext) error {
+	return byteArithmetic(cx, func(a, b *big.Int) (*big.Int, error) {
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("modulo by zero")
+		}
+		return a.Rem(a, b), nil
+	})
+}
+
+func byteCompare(cx *evalContext, f func(cmp int) bool) error {
+	a, b, err := cx.popByteInts()
+	if err != nil {
+		return err
+	}
+	return cx.pushBool(f(a.Cmp(b)))
+}
+
+func opBytesLt(cx *evalContext) error {
+	return byteCompare(cx, func(cmp int) bool { return cmp < 0 })
+}
+
+func opBytesGt(cx *evalContext) error {
+	return byteCompare(cx, func(cmp int) bool { return cmp > 0 })
+}
+
+func opBytesLe(cx *evalContext) error {
+	return byteCompare(cx, func(cmp int) bool { return cmp <= 0 })
+}
+
+func opBytesGe(cx *evalContext) error {
+	return byteCompare(cx, func(cmp int) bool { return cmp >= 0 })
+}
+
+func opBytesEq(cx *evalContext) error {
+	return byteCompare(cx, func(cmp int) bool { return cmp == 0 })
+}
+
+func opBytesNeq(cx *evalContext) error {
+	return byteCompare(cx, func(cmp int) bool { return cmp != 0 })
+}
+
+// bytesBitwise pops two byte arrays, left padded with zeros to the same
+// length, and pushes f applied to each pair of their bytes
+func bytesBitwise(cx *evalContext, f func(a, b byte) byte) error {
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	a, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	v := make([]byte, n)
+	for i := range v {
+		var x, y byte
+		if j := i - (n - len(a)); j >= 0 {
+			x = a[j]
+		}
+		if j := i - (n - len(b)); j >= 0 {
+			y = b[j]
+		}
+		v[i] = f(x, y)
+	}
+	return cx.pushBytes(v)
+}
+
+func opBytesBitOr(cx *evalContext) error {
+	return bytesBitwise(cx, func(a, b byte) byte { return a | b })
+}
+
+func opBytesBitAnd(cx *evalContext) error {
+	return bytesBitwise(cx, func(a, b byte) byte { return a & b })
+}
+
+func opBytesBitXor(cx *evalContext) error {
+	return bytesBitwise(cx, func(a, b byte) byte { return a ^ b })
+}
+
+func opBytesBitNot(cx *evalContext) error {
+	b, err := cx.popBytes()
+	if err != nil {
+		return err
+	}
+	v := make([]byte, len(b))
+	for i, c := range b {
+		v[i] = ^c
+	}
+	return cx.pushBytes(v)
+}
+
+func opBzero(cx *evalContext) error {
+	n, err := cx.popUint()
+	if err != nil {
+		return err
+	}
+	if n > maxStringSize {
+		return fmt.Errorf("length %d is longer than %d bytes", n, maxStringSize)
+	}
+	return cx.pushBytes(make([]byte, n))
+}
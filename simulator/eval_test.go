@@ -0,0 +1,506 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+// assemble assembles the subset of TEAL used by the tests: int and byte
+// pseudo-opcodes, labels and the opcodes of the evaluator with their
+// immediates
+func assemble(t *testing.T, source string) []byte {
+	byName := make(map[string]byte)
+	for opcode, op := range opcodes {
+		if op != nil {
+			byName[op.name] = byte(opcode)
+		}
+	}
+	indexOf := func(names []string, name string) byte {
+		for i, n := range names {
+			if n == name {
+				return byte(i)
+			}
+		}
+		t.Fatalf("unknown field %s", name)
+		return 0
+	}
+	number := func(s string) byte {
+		n, err := strconv.Atoi(s)
+		require.NoError(t, err)
+		return byte(n)
+	}
+
+	version := uint64(1)
+	var ints []uint64
+	var byteConsts [][]byte
+	var code []byte
+	labels := make(map[string]int)
+	fixups := make(map[int]string)
+	for _, line := range strings.Split(source, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "#pragma":
+			v, err := strconv.ParseUint(fields[2], 10, 64)
+			require.NoError(t, err)
+			version = v
+		case strings.HasSuffix(fields[0], ":"):
+			labels[strings.TrimSuffix(fields[0], ":")] = len(code)
+		case fields[0] == "int":
+			v, err := strconv.ParseUint(fields[1], 10, 64)
+			require.NoError(t, err)
+			code = append(code, byName["intc"], byte(len(ints)))
+			ints = append(ints, v)
+		case fields[0] == "byte":
+			var b []byte
+			if strings.HasPrefix(fields[1], "0x") {
+				var err error
+				b, err = hex.DecodeString(fields[1][2:])
+				require.NoError(t, err)
+			} else {
+				s, err := strconv.Unquote(strings.Join(fields[1:], " "))
+				require.NoError(t, err)
+				b = []byte(s)
+			}
+			code = append(code, byName["bytec"], byte(len(byteConsts)))
+			byteConsts = append(byteConsts, b)
+		default:
+			opcode, ok := byName[fields[0]]
+			require.True(t, ok, "unknown opcode %s", fields[0])
+			code = append(code, opcode)
+			switch opcodes[opcode].imm {
+			case immUint8:
+				code = append(code, number(fields[1]))
+			case immUint8Pair:
+				code = append(code, number(fields[1]), number(fields[2]))
+			case immTxnField:
+				code = append(code, indexOf(txnFields, fields[1]))
+			case immGlobalField:
+				code = append(code, indexOf(globalFields, fields[1]))
+			case immHoldingField:
+				code = append(code, indexOf(holdingFields, fields[1]))
+			case immParamsField:
+				code = append(code, indexOf(paramsFields, fields[1]))
+			case immGtxnField:
+				code = append(code, number(fields[1]), indexOf(txnFields, fields[2]))
+			case immTxnaField:
+				code = append(code, indexOf(txnFields, fields[1]), number(fields[2]))
+			case immGtxnaField:
+				code = append(code, number(fields[1]), indexOf(txnFields, fields[2]), number(fields[3]))
+			case immBranch:
+				fixups[len(code)] = fields[1]
+				code = append(code, 0, 0)
+			case immPushInt:
+				v, err := strconv.ParseUint(fields[1], 10, 64)
+				require.NoError(t, err)
+				code = appendUvarint(code, v)
+			case immPushBytes:
+				b, err := hex.DecodeString(strings.TrimPrefix(fields[1], "0x"))
+				require.NoError(t, err)
+				code = append(appendUvarint(code, uint64(len(b))), b...)
+			}
+		}
+	}
+
+	program := appendUvarint(nil, version)
+	if len(ints) > 0 {
+		program = append(program, byName["intcblock"])
+		program = appendUvarint(program, uint64(len(ints)))
+		for _, v := range ints {
+			program = appendUvarint(program, v)
+		}
+	}
+	if len(byteConsts) > 0 {
+		program = append(program, byName["bytecblock"])
+		program = appendUvarint(program, uint64(len(byteConsts)))
+		for _, b := range byteConsts {
+			program = appendUvarint(program, uint64(len(b)))
+			program = append(program, b...)
+		}
+	}
+	for at, label := range fixups {
+		target, ok := labels[label]
+		require.True(t, ok, "unknown label %s", label)
+		binary.BigEndian.PutUint16(code[at:], uint16(target-at-2))
+	}
+	return append(program, code...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return append(b, buf[:binary.PutUvarint(buf, v)]...)
+}
+
+// evalApp runs program as the approval program of application 1 called by
+// txn, on an empty state
+func evalApp(t *testing.T, source string, txn types.Transaction) (*evalContext, bool, error) {
+	st := newState()
+	st.apps[1] = &application{global: make(appstate.State)}
+	cx := &evalContext{
+		mode:    modeApp,
+		params:  consensus.LookupOrLatest(consensus.Latest),
+		program: assemble(t, source),
+		group:   []types.SignedTxn{{Txn: txn}},
+		round:   7,
+		st:      st,
+		appID:   1,
+		global:  make(appstate.State),
+		locals:  make(map[types.Address]appstate.State),
+	}
+	pass, err := cx.run()
+	return cx, pass, err
+}
+
+func TestDisassemble(t *testing.T) {
+	program := assemble(t, `#pragma version 2
+int 1
+byte "hi"
+len
+txna ApplicationArgs 0
+pop
+bnz done
+err
+done:
+int 1`)
+	d, err := disassemble(program)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"// version 2",
+		"intcblock 1 1",
+		"bytecblock 0x6869",
+		"intc 0",
+		"bytec 0",
+		"len",
+		"txna ApplicationArgs 0",
+		"pop",
+		"bnz @23",
+		"err",
+		"intc 1",
+	}, d.lines)
+	require.Equal(t, uint64(2), d.version)
+	require.Equal(t, 10, d.cost)
+
+	_, err = disassemble([]byte{2, 0xff})
+	require.Error(t, err)
+}
+
+func TestEval(t *testing.T) {
+	txn := types.Transaction{Type: types.ApplicationCallTx}
+	txn.ApplicationArgs = [][]byte{[]byte("abc")}
+
+	cx, pass, err := evalApp(t, `#pragma version 2
+txna ApplicationArgs 0
+substring 1 3
+byte "bc"
+==
+global Round
+int 7
+==
+&&
+store 2
+load 2
+int 2
+int 3
+*
+int 6
+==
+&&`, txn)
+	require.NoError(t, err)
+	require.True(t, pass)
+
+	// the trace holds the state before each instruction
+	require.Len(t, cx.trace, 18)
+	require.Equal(t, uint64(1), cx.trace[0].Pc)
+	require.Equal(t, uint64(1), cx.trace[0].Line)
+	last := cx.trace[len(cx.trace)-1]
+	require.Len(t, last.Stack, 2)
+	require.Len(t, last.Scratch, 3)
+	require.Equal(t, uint64(1), last.Scratch[2].Uint)
+
+	// failures are reported on the instruction at fault
+	cx, pass, err = evalApp(t, `#pragma version 2
+int 1
+int 0
+/`, txn)
+	require.False(t, pass)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "division by zero")
+	require.Contains(t, cx.trace[len(cx.trace)-1].Error, "division by zero")
+
+	_, pass, err = evalApp(t, `#pragma version 2
+int 0`, txn)
+	require.NoError(t, err)
+	require.False(t, pass)
+
+	_, _, err = evalApp(t, `#pragma version 2
+int 1
+int 2`, txn)
+	require.Error(t, err)
+
+	_, _, err = evalApp(t, `#pragma version 2
+arg 0`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not available")
+
+	_, _, err = evalApp(t, `#pragma version 1
+int 1`, txn)
+	require.Error(t, err)
+}
+
+func TestEvalState(t *testing.T) {
+	txn := types.Transaction{Type: types.ApplicationCallTx}
+	cx, pass, err := evalApp(t, `#pragma version 2
+byte "counter"
+byte "counter"
+app_global_get
+int 1
++
+app_global_put
+int 0
+byte "missing"
+app_global_get_ex
+!
+bnz end
+err
+end:
+int 1
+return`, txn)
+	require.NoError(t, err)
+	require.True(t, pass)
+	counter, ok := cx.global.Uint("counter")
+	require.True(t, ok)
+	require.Equal(t, uint64(1), counter)
+
+	// writing the local state of an account which did not opt in fails
+	_, _, err = evalApp(t, `#pragma version 2
+int 0
+byte "k"
+int 1
+app_local_put
+int 1`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "has not opted in")
+}
+
+func TestEvalVersion3(t *testing.T) {
+	txn := types.Transaction{Type: types.ApplicationCallTx}
+	txn.ForeignAssets = []types.AssetIndex{10}
+	txn.ForeignApps = []types.AppIndex{20}
+
+	_, pass, err := evalApp(t, `#pragma version 3
+pushint 1
+pushint 2
+pushint 3
+dig 2
+int 1
+==
+assert
+swap
+int 2
+==
+assert
+pop
+pop
+pushint 5
+pushint 6
+int 1
+select
+int 6
+==
+assert
+pushbytes 0x0f
+int 4
+getbit
+int 1
+==
+assert
+pushbytes 0x00ff
+int 0
+int 1
+setbit
+int 1
+getbyte
+int 255
+==
+assert
+int 0
+int 1
+int 1
+setbit
+int 2
+==
+assert
+pushbytes 0x0000
+int 1
+int 7
+setbyte
+pushbytes 0x0007
+==
+assert
+txna Assets 0
+int 10
+==
+assert
+txna Applications 1
+int 20
+==
+assert
+txn NumApplications
+int 1
+==`, txn)
+	require.NoError(t, err)
+	require.True(t, pass)
+
+	_, _, err = evalApp(t, `#pragma version 3
+int 0
+assert
+int 1`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "assertion failed")
+
+	// version 2 programs cannot use the opcodes and fields of version 3
+	_, _, err = evalApp(t, `#pragma version 2
+int 1
+int 2
+swap`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not available")
+	_, _, err = evalApp(t, `#pragma version 2
+txn NumAssets`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not available")
+}
+
+func TestEvalVersion4(t *testing.T) {
+	txn := types.Transaction{Type: types.ApplicationCallTx}
+	txn.Sender = types.Address{1}
+
+	// subroutines, backward branches and the new arithmetic
+	_, pass, err := evalApp(t, `#pragma version 4
+int 0
+store 0
+int 5
+loop:
+dup
+callsub add
+int 1
+-
+dup
+bnz loop
+pop
+load 0
+int 15
+==
+assert
+int 1
+int 63
+shl
+int 63
+shr
+int 1
+==
+assert
+int 17
+sqrt
+int 4
+==
+assert
+int 2
+int 64
+expw
+int 0
+==
+assert
+int 1
+==
+assert
+int 1
+int 0
+int 0
+int 3
+divmodw
+int 1
+==
+assert
+pop
+int 6148914691236517205
+==
+assert
+pop
+pushbytes 0xffffffffffffffff
+pushbytes 0x01
+b+
+pushbytes 0x010000000000000000
+b==
+assert
+pushbytes 0xff00
+pushbytes 0x0f
+b|
+b~
+pushbytes 0x00f0
+==
+assert
+int 3
+bzero
+len
+int 3
+==
+assert
+int 1
+b end
+add:
+load 0
++
+store 0
+retsub
+end:`, txn)
+	require.NoError(t, err)
+	require.True(t, pass)
+
+	// the cost of the instructions run is limited
+	_, _, err = evalApp(t, `#pragma version 4
+loop:
+b loop`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cost 701 exceeds the maximum of 700")
+
+	// before version 4, branches only go forward
+	_, _, err = evalApp(t, `#pragma version 3
+int 1
+loop:
+b loop`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "backward")
+
+	// accounts can be referred to by address
+	_, pass, err = evalApp(t, `#pragma version 4
+txn Sender
+balance
+int 0
+==`, txn)
+	require.NoError(t, err)
+	require.True(t, pass)
+	_, _, err = evalApp(t, `#pragma version 3
+txn Sender
+balance`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected a uint")
+	_, _, err = evalApp(t, `#pragma version 4
+global ZeroAddress
+balance`, txn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid Account reference")
+}
@@ -0,0 +1,496 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// maxVersion is the highest program version the evaluator supports, the
+// LogicSigVersion of consensus.Latest
+const maxVersion = 4
+
+// Program versions changing the rules of the evaluator
+const (
+	// backBranchVersion allows backward branches and subroutines, and
+	// checks the cost of the instructions run instead of the cost of the
+	// whole program
+	backBranchVersion = 4
+
+	// directRefVersion allows accounts to be referred to by address, and
+	// assets and applications by index wherever an offset was expected
+	directRefVersion = 4
+)
+
+// runMode is the mode a program runs in
+type runMode int
+
+const (
+	// modeSig is the mode of LogicSig programs
+	modeSig runMode = 1 << iota
+
+	// modeApp is the mode of approval and clear state programs
+	modeApp
+
+	modeAny = modeSig | modeApp
+)
+
+func (m runMode) String() string {
+	if m == modeSig {
+		return "LogicSig"
+	}
+	return "application"
+}
+
+// immediate is the kind of the immediate arguments following an opcode
+type immediate int
+
+const (
+	immNone immediate = iota
+	immUint8
+	immUint8Pair
+	immTxnField
+	immGlobalField
+	immGtxnField
+	immTxnaField
+	immGtxnaField
+	immBranch
+	immIntcBlock
+	immBytecBlock
+	immHoldingField
+	immParamsField
+	immPushInt
+	immPushBytes
+)
+
+// opSpec describes an opcode. A zero version means the opcode exists since
+// version 1 and zero modes means it runs in any mode.
+type opSpec struct {
+	name    string
+	version uint64
+	modes   runMode
+	imm     immediate
+
+	// cost is the cost of the opcode from version 2, costV1 the cost in
+	// version 1 when it differs
+	cost   int
+	costV1 int
+
+	eval func(cx *evalContext) error
+}
+
+// costIn returns the cost of the opcode in programs of the given version
+func (op *opSpec) costIn(version uint64) int {
+	if version < 2 && op.costV1 != 0 {
+		return op.costV1
+	}
+	if op.cost == 0 {
+		return 1
+	}
+	return op.cost
+}
+
+// runsIn reports whether the opcode can be used in a program of the given
+// version running in mode
+func (op *opSpec) runsIn(version uint64, mode runMode) bool {
+	if op.version > version {
+		return false
+	}
+	return op.modes == 0 || op.modes&mode != 0
+}
+
+var opcodes [256]*opSpec
+
+func init() {
+	specs := map[byte]opSpec{
+		0x00: {name: "err", eval: opErr},
+		0x01: {name: "sha256", cost: 35, costV1: 7, eval: opSHA256},
+		0x02: {name: "keccak256", cost: 130, costV1: 26, eval: opKeccak256},
+		0x03: {name: "sha512_256", cost: 45, costV1: 9, eval: opSHA512_256},
+		0x04: {name: "ed25519verify", cost: 1900, eval: opEd25519Verify},
+		0x08: {name: "+", eval: opPlus},
+		0x09: {name: "-", eval: opMinus},
+		0x0a: {name: "/", eval: opDiv},
+		0x0b: {name: "*", eval: opMul},
+		0x0c: {name: "<", eval: opLt},
+		0x0d: {name: ">", eval: opGt},
+		0x0e: {name: "<=", eval: opLe},
+		0x0f: {name: ">=", eval: opGe},
+		0x10: {name: "&&", eval: opAnd},
+		0x11: {name: "||", eval: opOr},
+		0x12: {name: "==", eval: opEq},
+		0x13: {name: "!=", eval: opNeq},
+		0x14: {name: "!", eval: opNot},
+		0x15: {name: "len", eval: opLen},
+		0x16: {name: "itob", eval: opItob},
+		0x17: {name: "btoi", eval: opBtoi},
+		0x18: {name: "%", eval: opModulo},
+		0x19: {name: "|", eval: opBitOr},
+		0x1a: {name: "&", eval: opBitAnd},
+		0x1b: {name: "^", eval: opBitXor},
+		0x1c: {name: "~", eval: opBitNot},
+		0x1d: {name: "mulw", eval: opMulw},
+		0x1e: {name: "plusw", version: 2, eval: opPlusw},
+		0x1f: {name: "divmodw", version: 4, cost: 20, eval: opDivModw},
+		0x20: {name: "intcblock", imm: immIntcBlock, eval: opIntcBlock},
+		0x21: {name: "intc", imm: immUint8, eval: opIntc},
+		0x22: {name: "intc_0", eval: opIntcN(0)},
+		0x23: {name: "intc_1", eval: opIntcN(1)},
+		0x24: {name: "intc_2", eval: opIntcN(2)},
+		0x25: {name: "intc_3", eval: opIntcN(3)},
+		0x26: {name: "bytecblock", imm: immBytecBlock, eval: opBytecBlock},
+		0x27: {name: "bytec", imm: immUint8, eval: opBytec},
+		0x28: {name: "bytec_0", eval: opBytecN(0)},
+		0x29: {name: "bytec_1", eval: opBytecN(1)},
+		0x2a: {name: "bytec_2", eval: opBytecN(2)},
+		0x2b: {name: "bytec_3", eval: opBytecN(3)},
+		0x2c: {name: "arg", modes: modeSig, imm: immUint8, eval: opArg},
+		0x2d: {name: "arg_0", modes: modeSig, eval: opArgN(0)},
+		0x2e: {name: "arg_1", modes: modeSig, eval: opArgN(1)},
+		0x2f: {name: "arg_2", modes: modeSig, eval: opArgN(2)},
+		0x30: {name: "arg_3", modes: modeSig, eval: opArgN(3)},
+		0x31: {name: "txn", imm: immTxnField, eval: opTxn},
+		0x32: {name: "global", imm: immGlobalField, eval: opGlobal},
+		0x33: {name: "gtxn", imm: immGtxnField, eval: opGtxn},
+		0x34: {name: "load", imm: immUint8, eval: opLoad},
+		0x35: {name: "store", imm: immUint8, eval: opStore},
+		0x36: {name: "txna", version: 2, imm: immTxnaField, eval: opTxna},
+		0x37: {name: "gtxna", version: 2, imm: immGtxnaField, eval: opGtxna},
+		0x38: {name: "gtxns", version: 3, imm: immTxnField, eval: opGtxns},
+		0x39: {name: "gtxnsa", version: 3, imm: immTxnaField, eval: opGtxnsa},
+		0x3a: {name: "gload", version: 4, modes: modeApp, imm: immUint8Pair, eval: opGload},
+		0x3b: {name: "gloads", version: 4, modes: modeApp, imm: immUint8, eval: opGloads},
+		0x3c: {name: "gaid", version: 4, modes: modeApp, imm: immUint8, eval: opGaid},
+		0x3d: {name: "gaids", version: 4, modes: modeApp, eval: opGaids},
+		0x40: {name: "bnz", imm: immBranch, eval: opBnz},
+		0x41: {name: "bz", version: 2, imm: immBranch, eval: opBz},
+		0x42: {name: "b", version: 2, imm: immBranch, eval: opB},
+		0x43: {name: "return", version: 2, eval: opReturn},
+		0x44: {name: "assert", version: 3, eval: opAssert},
+		0x48: {name: "pop", eval: opPop},
+		0x49: {name: "dup", eval: opDup},
+		0x4a: {name: "dup2", version: 2, eval: opDup2},
+		0x4b: {name: "dig", version: 3, imm: immUint8, eval: opDig},
+		0x4c: {name: "swap", version: 3, eval: opSwap},
+		0x4d: {name: "select", version: 3, eval: opSelect},
+		0x50: {name: "concat", version: 2, eval: opConcat},
+		0x51: {name: "substring", version: 2, imm: immUint8Pair, eval: opSubstring},
+		0x52: {name: "substring3", version: 2, eval: opSubstring3},
+		0x53: {name: "getbit", version: 3, eval: opGetBit},
+		0x54: {name: "setbit", version: 3, eval: opSetBit},
+		0x55: {name: "getbyte", version: 3, eval: opGetByte},
+		0x56: {name: "setbyte", version: 3, eval: opSetByte},
+		0x60: {name: "balance", version: 2, modes: modeApp, eval: opBalance},
+		0x61: {name: "app_opted_in", version: 2, modes: modeApp, eval: opAppOptedIn},
+		0x62: {name: "app_local_get", version: 2, modes: modeApp, eval: opAppLocalGet},
+		0x63: {name: "app_local_get_ex", version: 2, modes: modeApp, eval: opAppLocalGetEx},
+		0x64: {name: "app_global_get", version: 2, modes: modeApp, eval: opAppGlobalGet},
+		0x65: {name: "app_global_get_ex", version: 2, modes: modeApp, eval: opAppGlobalGetEx},
+		0x66: {name: "app_local_put", version: 2, modes: modeApp, eval: opAppLocalPut},
+		0x67: {name: "app_global_put", version: 2, modes: modeApp, eval: opAppGlobalPut},
+		0x68: {name: "app_local_del", version: 2, modes: modeApp, eval: opAppLocalDel},
+		0x69: {name: "app_global_del", version: 2, modes: modeApp, eval: opAppGlobalDel},
+		0x70: {name: "asset_holding_get", version: 2, modes: modeApp, imm: immHoldingField, eval: opAssetHoldingGet},
+		0x71: {name: "asset_params_get", version: 2, modes: modeApp, imm: immParamsField, eval: opAssetParamsGet},
+		0x78: {name: "min_balance", version: 3, modes: modeApp, eval: opMinBalance},
+		0x80: {name: "pushbytes", version: 3, imm: immPushBytes, eval: opPushBytes},
+		0x81: {name: "pushint", version: 3, imm: immPushInt, eval: opPushInt},
+		0x88: {name: "callsub", version: 4, imm: immBranch, eval: opCallSub},
+		0x89: {name: "retsub", version: 4, eval: opRetSub},
+		0x90: {name: "shl", version: 4, eval: opShiftLeft},
+		0x91: {name: "shr", version: 4, eval: opShiftRight},
+		0x92: {name: "sqrt", version: 4, cost: 4, eval: opSqrt},
+		0x93: {name: "bitlen", version: 4, eval: opBitLen},
+		0x94: {name: "exp", version: 4, eval: opExp},
+		0x95: {name: "expw", version: 4, cost: 10, eval: opExpw},
+		0xa0: {name: "b+", version: 4, cost: 10, eval: opBytesPlus},
+		0xa1: {name: "b-", version: 4, cost: 10, eval: opBytesMinus},
+		0xa2: {name: "b/", version: 4, cost: 20, eval: opBytesDiv},
+		0xa3: {name: "b*", version: 4, cost: 20, eval: opBytesMul},
+		0xa4: {name: "b<", version: 4, eval: opBytesLt},
+		0xa5: {name: "b>", version: 4, eval: opBytesGt},
+		0xa6: {name: "b<=", version: 4, eval: opBytesLe},
+		0xa7: {name: "b>=", version: 4, eval: opBytesGe},
+		0xa8: {name: "b==", version: 4, eval: opBytesEq},
+		0xa9: {name: "b!=", version: 4, eval: opBytesNeq},
+		0xaa: {name: "b%", version: 4, cost: 20, eval: opBytesModulo},
+		0xab: {name: "b|", version: 4, cost: 6, eval: opBytesBitOr},
+		0xac: {name: "b&", version: 4, cost: 6, eval: opBytesBitAnd},
+		0xad: {name: "b^", version: 4, cost: 6, eval: opBytesBitXor},
+		0xae: {name: "b~", version: 4, cost: 4, eval: opBytesBitNot},
+		0xaf: {name: "bzero", version: 4, eval: opBzero},
+	}
+	for opcode, spec := range specs {
+		spec := spec
+		if spec.version == 0 {
+			spec.version = 1
+		}
+		opcodes[opcode] = &spec
+	}
+}
+
+// Field names of the txn, gtxn, gtxns opcodes and their array variants, by
+// field index
+var txnFields = []string{
+	"Sender", "Fee", "FirstValid", "FirstValidTime", "LastValid", "Note", "Lease",
+	"Receiver", "Amount", "CloseRemainderTo", "VotePK", "SelectionPK", "VoteFirst",
+	"VoteLast", "VoteKeyDilution", "Type", "TypeEnum", "XferAsset", "AssetAmount",
+	"AssetSender", "AssetReceiver", "AssetCloseTo", "GroupIndex", "TxID",
+	"ApplicationID", "OnCompletion", "ApplicationArgs", "NumAppArgs", "Accounts",
+	"NumAccounts", "ApprovalProgram", "ClearStateProgram", "RekeyTo", "ConfigAsset",
+	"ConfigAssetTotal", "ConfigAssetDecimals", "ConfigAssetDefaultFrozen",
+	"ConfigAssetUnitName", "ConfigAssetName", "ConfigAssetURL",
+	"ConfigAssetMetadataHash", "ConfigAssetManager", "ConfigAssetReserve",
+	"ConfigAssetFreeze", "ConfigAssetClawback", "FreezeAsset", "FreezeAssetAccount",
+	"FreezeAssetFrozen", "Assets", "NumAssets", "Applications", "NumApplications",
+	"GlobalNumUint", "GlobalNumByteSlice", "LocalNumUint", "LocalNumByteSlice",
+	"ExtraProgramPages",
+}
+
+// firstTxnField holds the index of the first txn field added by versions 2
+// and later
+var firstTxnField = []byte{2: 24, 3: 48, 4: 56}
+
+// Field names of the global opcode, by field index
+var globalFields = []string{
+	"MinTxnFee", "MinBalance", "MaxTxnLife", "ZeroAddress", "GroupSize",
+	"LogicSigVersion", "Round", "LatestTimestamp", "CurrentApplicationID",
+	"CreatorAddress",
+}
+
+// firstGlobalField holds the index of the first global field added by
+// versions 2 and later
+var firstGlobalField = []byte{2: 5, 3: 9}
+
+// Field names of the asset_holding_get and asset_params_get opcodes
+var (
+	holdingFields = []string{"AssetBalance", "AssetFrozen"}
+	paramsFields  = []string{
+		"AssetTotal", "AssetDecimals", "AssetDefaultFrozen", "AssetUnitName",
+		"AssetName", "AssetURL", "AssetMetadataHash", "AssetManager",
+		"AssetReserve", "AssetFreeze", "AssetClawback",
+	}
+)
+
+// fieldName returns the name of field index i of names
+func fieldName(names []string, i byte) (string, error) {
+	if int(i) >= len(names) {
+		return "", fmt.Errorf("invalid field %d", i)
+	}
+	return names[i], nil
+}
+
+// fieldVersion returns the version which added field index i, given the
+// index of the first field added by each version
+func fieldVersion(first []byte, i byte) uint64 {
+	version := uint64(1)
+	for v := 2; v < len(first); v++ {
+		if i >= first[v] {
+			version = uint64(v)
+		}
+	}
+	return version
+}
+
+// immediates returns the disassembly of the immediate arguments of the
+// opcode at pc and the size of the instruction
+func immediates(program []byte, pc int, imm immediate) (string, int, error) {
+	need := func(n int) error {
+		if pc+n > len(program) {
+			return fmt.Errorf("program ends in the middle of the instruction at pc %d", pc)
+		}
+		return nil
+	}
+	switch imm {
+	case immNone:
+		return "", 1, nil
+	case immUint8:
+		if err := need(2); err != nil {
+			return "", 0, err
+		}
+		return fmt.Sprintf("%d", program[pc+1]), 2, nil
+	case immUint8Pair:
+		if err := need(3); err != nil {
+			return "", 0, err
+		}
+		return fmt.Sprintf("%d %d", program[pc+1], program[pc+2]), 3, nil
+	case immTxnField, immGlobalField, immHoldingField, immParamsField:
+		if err := need(2); err != nil {
+			return "", 0, err
+		}
+		names := map[immediate][]string{
+			immTxnField:     txnFields,
+			immGlobalField:  globalFields,
+			immHoldingField: holdingFields,
+			immParamsField:  paramsFields,
+		}[imm]
+		name, err := fieldName(names, program[pc+1])
+		return name, 2, err
+	case immGtxnField:
+		if err := need(3); err != nil {
+			return "", 0, err
+		}
+		name, err := fieldName(txnFields, program[pc+2])
+		return fmt.Sprintf("%d %s", program[pc+1], name), 3, err
+	case immTxnaField:
+		if err := need(3); err != nil {
+			return "", 0, err
+		}
+		name, err := fieldName(txnFields, program[pc+1])
+		return fmt.Sprintf("%s %d", name, program[pc+2]), 3, err
+	case immGtxnaField:
+		if err := need(4); err != nil {
+			return "", 0, err
+		}
+		name, err := fieldName(txnFields, program[pc+2])
+		return fmt.Sprintf("%d %s %d", program[pc+1], name, program[pc+3]), 4, err
+	case immBranch:
+		if err := need(3); err != nil {
+			return "", 0, err
+		}
+		target := pc + 3 + int(int16(binary.BigEndian.Uint16(program[pc+1:])))
+		return fmt.Sprintf("@%d", target), 3, nil
+	case immPushInt:
+		v, n := binary.Uvarint(program[pc+1:])
+		if n <= 0 {
+			return "", 0, fmt.Errorf("could not decode pushint constant")
+		}
+		return fmt.Sprintf("%d", v), 1 + n, nil
+	case immPushBytes:
+		length, n := binary.Uvarint(program[pc+1:])
+		if n <= 0 {
+			return "", 0, fmt.Errorf("could not decode pushbytes length")
+		}
+		if uint64(len(program)-pc-1-n) < length {
+			return "", 0, fmt.Errorf("pushbytes ran past end of program")
+		}
+		b := program[pc+1+n : pc+1+n+int(length)]
+		return "0x" + hex.EncodeToString(b), 1 + n + int(length), nil
+	case immIntcBlock:
+		size, ints, err := readIntConstBlock(program, pc)
+		if err != nil {
+			return "", 0, err
+		}
+		parts := make([]string, len(ints))
+		for i, v := range ints {
+			parts[i] = fmt.Sprintf("%d", v)
+		}
+		return strings.Join(parts, " "), size, nil
+	case immBytecBlock:
+		size, byteArrays, err := readByteConstBlock(program, pc)
+		if err != nil {
+			return "", 0, err
+		}
+		parts := make([]string, len(byteArrays))
+		for i, b := range byteArrays {
+			parts[i] = "0x" + hex.EncodeToString(b)
+		}
+		return strings.Join(parts, " "), size, nil
+	}
+	return "", 0, fmt.Errorf("unknown immediate kind %d", imm)
+}
+
+// instruction locates an instruction in a disassembly
+type instruction struct {
+	line int
+	size int
+}
+
+// disassembly is a program decoded into one line per instruction
+type disassembly struct {
+	version uint64
+
+	// lines holds a "// version N" comment followed by the instructions,
+	// in the format of the dryrun response
+	lines []string
+
+	// instrs maps the pc of each instruction to its line and size
+	instrs map[int]instruction
+
+	// cost is the cost of all the instructions, which bounds the cost of
+	// running programs before backBranchVersion as their branches only go
+	// forward
+	cost int
+}
+
+// disassemble decodes program. Branch targets are written as "@pc".
+func disassemble(program []byte) (*disassembly, error) {
+	if len(program) == 0 {
+		return nil, fmt.Errorf("empty program")
+	}
+	version, vlen := binary.Uvarint(program)
+	if vlen <= 0 {
+		return nil, fmt.Errorf("invalid version")
+	}
+	d := &disassembly{
+		version: version,
+		lines:   []string{fmt.Sprintf("// version %d", version)},
+		instrs:  make(map[int]instruction),
+	}
+	for pc := vlen; pc < len(program); {
+		op := opcodes[program[pc]]
+		if op == nil {
+			return nil, fmt.Errorf("invalid opcode 0x%02x at pc %d", program[pc], pc)
+		}
+		args, size, err := immediates(program, pc, op.imm)
+		if err != nil {
+			return nil, fmt.Errorf("%s at pc %d: %v", op.name, pc, err)
+		}
+		text := op.name
+		if args != "" {
+			text += " " + args
+		}
+		d.instrs[pc] = instruction{line: len(d.lines), size: size}
+		d.lines = append(d.lines, text)
+		d.cost += op.costIn(version)
+		pc += size
+	}
+	return d, nil
+}
+
+func readIntConstBlock(program []byte, pc int) (size int, ints []uint64, err error) {
+	size = 1
+	count, n := binary.Uvarint(program[pc+size:])
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("could not decode int const block size")
+	}
+	size += n
+	for i := uint64(0); i < count; i++ {
+		if pc+size >= len(program) {
+			return 0, nil, fmt.Errorf("intcblock ran past end of program")
+		}
+		v, n := binary.Uvarint(program[pc+size:])
+		if n <= 0 {
+			return 0, nil, fmt.Errorf("could not decode int const %d", i)
+		}
+		ints = append(ints, v)
+		size += n
+	}
+	return size, ints, nil
+}
+
+func readByteConstBlock(program []byte, pc int) (size int, byteArrays [][]byte, err error) {
+	size = 1
+	count, n := binary.Uvarint(program[pc+size:])
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("could not decode []byte const block size")
+	}
+	size += n
+	for i := uint64(0); i < count; i++ {
+		if pc+size >= len(program) {
+			return 0, nil, fmt.Errorf("bytecblock ran past end of program")
+		}
+		length, n := binary.Uvarint(program[pc+size:])
+		if n <= 0 {
+			return 0, nil, fmt.Errorf("could not decode []byte const %d", i)
+		}
+		size += n
+		if uint64(len(program)-pc-size) < length {
+			return 0, nil, fmt.Errorf("bytecblock ran past end of program")
+		}
+		byteArrays = append(byteArrays, program[pc+size:pc+size+int(length)])
+		size += int(length)
+	}
+	return size, byteArrays, nil
+}
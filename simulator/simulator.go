@@ -0,0 +1,373 @@
+// Package simulator runs transactions against an in-memory ledger, to unit
+// test applications without a network.
+//
+// A Ledger holds accounts, their balances and asset holdings, applications
+// with their global state and the local state of the accounts opted in them.
+// Applying a group of signed transactions checks them like algod would,
+// including their signatures, runs LogicSigs and application programs with
+// an in-process TEAL evaluator and updates the ledger when the whole group
+// succeeds. A failing group leaves the ledger untouched.
+//
+// The outcome of each transaction has the shape of a dryrun response, so it
+// can be analyzed with the dryrun package:
+//
+//	response, err := ledger.Apply(stxns...)
+//	report, _ := dryrun.NewReport(request, response)
+//
+// where request is ledger.DryrunRequest(stxns...) taken before applying the
+// group. Accounts and applications are read and loaded in the shape of the
+// REST API, so the state of a live network can be copied into a Ledger.
+//
+// The evaluator runs TEAL programs up to version 4, the LogicSigVersion of
+// consensus.Latest, including the dynamic cost and the direct references
+// to accounts, applications and assets of version 4. Programs cannot ask
+// for extra program pages, so ExtraProgramPages is always 0.
+// Applications and assets are given increasing indexes starting from 1.
+package simulator
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+	"io"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/jffp113/go-algorand-sdk/validate"
+)
+
+// GenesisID is the genesis id of simulated ledgers
+const GenesisID = "simulator-v1"
+
+// Ledger is an in-memory ledger. It is not safe for concurrent use.
+type Ledger struct {
+	// Round is the round transactions are applied in, which must be in
+	// their validity window
+	Round uint64
+
+	// Timestamp is the LatestTimestamp seen by application programs, in
+	// seconds since the epoch
+	Timestamp int64
+
+	// ProtocolVersion is the consensus version of the ledger and Params its
+	// parameters
+	ProtocolVersion string
+	Params          consensus.Params
+
+	// GenesisHash identifies the ledger; transactions must carry it
+	GenesisHash types.Digest
+
+	st *state
+}
+
+// NewLedger returns an empty ledger at round 1 following the consensus
+// version, or the Latest version when it is empty
+func NewLedger(version string) (*Ledger, error) {
+	if version == "" {
+		version = consensus.Latest
+	}
+	params, err := consensus.Lookup(version)
+	if err != nil {
+		return nil, err
+	}
+	return &Ledger{
+		Round:           1,
+		ProtocolVersion: version,
+		Params:          params,
+		GenesisHash:     sha512.Sum512_256([]byte(GenesisID)),
+		st:              newState(),
+	}, nil
+}
+
+// SuggestedParams returns parameters for transactions valid from the current
+// round for the longest window allowed
+func (l *Ledger) SuggestedParams() types.SuggestedParams {
+	return types.SuggestedParams{
+		Fee:              types.MicroAlgos(l.Params.MinTxnFee),
+		FlatFee:          true,
+		GenesisID:        GenesisID,
+		GenesisHash:      l.GenesisHash[:],
+		FirstRoundValid:  types.Round(l.Round),
+		LastRoundValid:   types.Round(l.Round + l.Params.MaxTxnLife),
+		ConsensusVersion: l.ProtocolVersion,
+	}
+}
+
+// Fund adds amount microAlgos to the balance of addr
+func (l *Ledger) Fund(addr types.Address, amount uint64) {
+	l.st.mutable(addr).amount += amount
+	delete(l.st.touched, addr)
+}
+
+// SetAccount replaces an account with the one given in the shape of the REST
+// API, along with the applications and assets it created. Rewards and
+// participation are ignored.
+func (l *Ledger) SetAccount(model models.Account) error {
+	addr, err := types.DecodeAddress(model.Address)
+	if err != nil {
+		return err
+	}
+	authAddr, err := decodeOptionalAddress(model.AuthAddr)
+	if err != nil {
+		return fmt.Errorf("auth address of %s: %v", addr, err)
+	}
+	a := &account{
+		amount:   model.Amount,
+		authAddr: authAddr,
+		holdings: make(map[uint64]holding, len(model.Assets)),
+		locals:   make(map[uint64]*localState, len(model.AppsLocalState)),
+	}
+	for _, h := range model.Assets {
+		a.holdings[h.AssetId] = holding{amount: h.Amount, frozen: h.IsFrozen}
+	}
+	for _, local := range model.AppsLocalState {
+		kv, err := appstate.Decode(local.KeyValue)
+		if err != nil {
+			return fmt.Errorf("local state of %s in application %d: %v", addr, local.Id, err)
+		}
+		a.locals[local.Id] = &localState{schema: stateSchema(local.Schema), kv: kv}
+	}
+	for _, app := range model.CreatedApps {
+		if app.Params.Creator == "" {
+			app.Params.Creator = model.Address
+		}
+		if err := l.SetApplication(app); err != nil {
+			return err
+		}
+	}
+	for _, created := range model.CreatedAssets {
+		ca, err := assetFromModel(created)
+		if err != nil {
+			return fmt.Errorf("asset %d: %v", created.Index, err)
+		}
+		ca.creator = addr
+		l.st.assets[created.Index] = ca
+		l.st.reserveIndex(created.Index)
+	}
+	l.st.accounts[addr] = a
+	return nil
+}
+
+// SetApplication replaces an application with the one given in the shape of
+// the REST API
+func (l *Ledger) SetApplication(app models.Application) error {
+	if app.Id == 0 {
+		return fmt.Errorf("application has no id")
+	}
+	a, err := applicationFromModel(app)
+	if err != nil {
+		return fmt.Errorf("application %d: %v", app.Id, err)
+	}
+	l.st.apps[app.Id] = a
+	l.st.reserveIndex(app.Id)
+	return nil
+}
+
+// Account returns the account at addr in the shape of the REST API. Accounts
+// the ledger never saw are empty.
+func (l *Ledger) Account(addr types.Address) models.Account {
+	result := models.Account{Address: addr.String(), Round: l.Round, Status: "Offline"}
+	a, ok := l.st.accounts[addr]
+	if !ok {
+		return result
+	}
+	result.Amount = a.amount
+	result.AmountWithoutPendingRewards = a.amount
+	result.AuthAddr = addressString(a.authAddr)
+
+	var holdingIDs, localIDs, appIDs, assetIDs []uint64
+	for id := range a.holdings {
+		holdingIDs = append(holdingIDs, id)
+	}
+	for id := range a.locals {
+		localIDs = append(localIDs, id)
+	}
+	for id, app := range l.st.apps {
+		if app.creator == addr {
+			appIDs = append(appIDs, id)
+		}
+	}
+	for id, created := range l.st.assets {
+		if created.creator == addr {
+			assetIDs = append(assetIDs, id)
+		}
+	}
+
+	var total types.StateSchema
+	for _, id := range sortIDs(holdingIDs) {
+		h := a.holdings[id]
+		model := models.AssetHolding{AssetId: id, Amount: h.amount, IsFrozen: h.frozen}
+		if created, ok := l.st.assets[id]; ok {
+			model.Creator = created.creator.String()
+		}
+		result.Assets = append(result.Assets, model)
+	}
+	for _, id := range sortIDs(localIDs) {
+		local := a.locals[id]
+		result.AppsLocalState = append(result.AppsLocalState, models.ApplicationLocalState{
+			Id:       id,
+			KeyValue: local.kv.Encode(),
+			Schema:   modelSchema(local.schema),
+		})
+		total.NumUint += local.schema.NumUint
+		total.NumByteSlice += local.schema.NumByteSlice
+	}
+	for _, id := range sortIDs(appIDs) {
+		app := l.st.apps[id]
+		result.CreatedApps = append(result.CreatedApps, app.model(id))
+		total.NumUint += app.globalSchema.NumUint
+		total.NumByteSlice += app.globalSchema.NumByteSlice
+	}
+	for _, id := range sortIDs(assetIDs) {
+		result.CreatedAssets = append(result.CreatedAssets, l.st.assets[id].model(id))
+	}
+	result.AppsTotalSchema = modelSchema(total)
+	return result
+}
+
+// Application returns the application appID in the shape of the REST API
+func (l *Ledger) Application(appID uint64) (models.Application, bool) {
+	app, ok := l.st.apps[appID]
+	if !ok {
+		return models.Application{}, false
+	}
+	return app.model(appID), true
+}
+
+// Asset returns the asset index in the shape of the REST API
+func (l *Ledger) Asset(index uint64) (models.Asset, bool) {
+	a, ok := l.st.assets[index]
+	if !ok {
+		return models.Asset{}, false
+	}
+	return a.model(index), true
+}
+
+// GlobalState returns a copy of the global state of application appID
+func (l *Ledger) GlobalState(appID uint64) (appstate.State, bool) {
+	app, ok := l.st.apps[appID]
+	if !ok {
+		return nil, false
+	}
+	return app.global.Clone(), true
+}
+
+// LocalState returns a copy of the local state of addr in application appID,
+// if addr opted in the application
+func (l *Ledger) LocalState(addr types.Address, appID uint64) (appstate.State, bool) {
+	local := l.st.localState(addr, appID)
+	if local == nil {
+		return nil, false
+	}
+	return local.kv.Clone(), true
+}
+
+// DryrunRequest returns a dryrun request for txns holding every account and
+// application of the ledger, to analyze the response of applying txns with
+// dryrun.NewReport
+func (l *Ledger) DryrunRequest(txns ...types.SignedTxn) models.DryrunRequest {
+	request := models.DryrunRequest{
+		Txns:            txns,
+		Round:           l.Round,
+		ProtocolVersion: l.ProtocolVersion,
+	}
+	if l.Timestamp > 0 {
+		request.LatestTimestamp = uint64(l.Timestamp)
+	}
+	addrs := make([]types.Address, 0, len(l.st.accounts))
+	for addr := range l.st.accounts {
+		addrs = append(addrs, addr)
+	}
+	for _, addr := range sortAddresses(addrs) {
+		request.Accounts = append(request.Accounts, l.Account(addr))
+	}
+	ids := make([]uint64, 0, len(l.st.apps))
+	for id := range l.st.apps {
+		ids = append(ids, id)
+	}
+	for _, id := range sortIDs(ids) {
+		request.Apps = append(request.Apps, l.st.apps[id].model(id))
+	}
+	return request
+}
+
+// Dryrun evaluates the group txns like Apply, without changing the ledger
+func (l *Ledger) Dryrun(txns ...types.SignedTxn) (models.DryrunResponse, error) {
+	_, response, err := l.run(txns)
+	return response, err
+}
+
+// Apply applies the group txns to the ledger. It returns the outcome of each
+// transaction evaluated, and an error when the group fails, in which case
+// the ledger is left unchanged. The response is complete up to the failing
+// transaction.
+func (l *Ledger) Apply(txns ...types.SignedTxn) (models.DryrunResponse, error) {
+	st, response, err := l.run(txns)
+	if err != nil {
+		return response, err
+	}
+	st.touched = make(map[types.Address]bool)
+	l.st = st
+	return response, nil
+}
+
+// ApplyRaw applies the group of msgpack-encoded signed transactions
+// concatenated in rawTxns, the format accepted by SendRawTransaction
+func (l *Ledger) ApplyRaw(rawTxns []byte) (models.DryrunResponse, error) {
+	var txns []types.SignedTxn
+	dec := msgpack.NewDecoder(bytes.NewReader(rawTxns))
+	for {
+		var stxn types.SignedTxn
+		err := dec.Decode(&stxn)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return models.DryrunResponse{}, fmt.Errorf("decoding transaction %d: %v", len(txns), err)
+		}
+		txns = append(txns, stxn)
+	}
+	return l.Apply(txns...)
+}
+
+// run evaluates the group on a copy of the ledger state, and returns the
+// modified copy
+func (l *Ledger) run(stxns []types.SignedTxn) (*state, models.DryrunResponse, error) {
+	response := models.DryrunResponse{ProtocolVersion: l.ProtocolVersion}
+	txns := make([]types.Transaction, len(stxns))
+	for i, stxn := range stxns {
+		txns[i] = stxn.Txn
+	}
+	if err := validate.Group(txns, l.Params); err != nil {
+		response.Error = err.Error()
+		return nil, response, err
+	}
+
+	st := l.st.clone()
+	past := make([]effects, len(stxns))
+	for i := range stxns {
+		result, err := l.applyTxn(st, stxns, past, i)
+		response.Txns = append(response.Txns, result)
+		if err != nil {
+			return nil, response, fmt.Errorf("transaction %d: %v", i, err)
+		}
+	}
+
+	addrs := make([]types.Address, 0, len(st.touched))
+	for addr := range st.touched {
+		addrs = append(addrs, addr)
+	}
+	for _, addr := range sortAddresses(addrs) {
+		a, ok := st.accounts[addr]
+		if !ok || a.empty() {
+			continue
+		}
+		if min := st.minBalance(l.Params, addr); a.amount < min {
+			return nil, response, fmt.Errorf("balance %d of %s is below its minimum balance of %d", a.amount, addr, min)
+		}
+	}
+	return st, response, nil
+}
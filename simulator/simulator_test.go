@@ -0,0 +1,359 @@
+package simulator
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/dryrun"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+const counterApproval = `#pragma version 2
+txn ApplicationID
+bz create
+txn OnCompletion
+int 1 // OptIn
+==
+bnz approve
+byte "counter"
+byte "counter"
+app_global_get
+int 1
++
+app_global_put
+int 0
+byte "calls"
+int 0
+byte "calls"
+app_local_get
+int 1
++
+app_local_put
+b approve
+create:
+byte "counter"
+int 0
+app_global_put
+approve:
+int 1`
+
+func sign(t *testing.T, sk ed25519.PrivateKey, txn types.Transaction) types.SignedTxn {
+	_, encoded, err := crypto.SignTransaction(sk, txn)
+	require.NoError(t, err)
+	var stxn types.SignedTxn
+	require.NoError(t, msgpack.Decode(encoded, &stxn))
+	return stxn
+}
+
+func newLedger(t *testing.T, accounts ...crypto.Account) *Ledger {
+	ledger, err := NewLedger("")
+	require.NoError(t, err)
+	for _, account := range accounts {
+		ledger.Fund(account.Address, 10000000)
+	}
+	return ledger
+}
+
+func TestPayment(t *testing.T) {
+	alice, bob := crypto.GenerateAccount(), crypto.GenerateAccount()
+	ledger := newLedger(t, alice)
+	sp := ledger.SuggestedParams()
+
+	txn, err := future.MakePaymentTxn(alice.Address.String(), bob.Address.String(), 1000000, nil, "", sp)
+	require.NoError(t, err)
+	_, err = ledger.ApplyRaw(msgpack.Encode(sign(t, alice.PrivateKey, txn)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000000), ledger.Account(bob.Address).Amount)
+	require.Equal(t, uint64(10000000-1000000-1000), ledger.Account(alice.Address).Amount)
+
+	// payments leaving bob below the minimum balance fail
+	txn, err = future.MakePaymentTxn(bob.Address.String(), alice.Address.String(), 998000, nil, "", sp)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, bob.PrivateKey, txn))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "minimum balance")
+
+	// closing the account is allowed
+	txn, err = future.MakePaymentTxn(bob.Address.String(), alice.Address.String(), 0, nil, alice.Address.String(), sp)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, bob.PrivateKey, txn))
+	require.NoError(t, err)
+	require.Zero(t, ledger.Account(bob.Address).Amount)
+
+	// transactions must be signed by the sender
+	txn, err = future.MakePaymentTxn(alice.Address.String(), bob.Address.String(), 1000000, nil, "", sp)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, bob.PrivateKey, txn))
+	require.Error(t, err)
+}
+
+func TestGroupIsAtomic(t *testing.T) {
+	alice, bob := crypto.GenerateAccount(), crypto.GenerateAccount()
+	ledger := newLedger(t, alice, bob)
+	sp := ledger.SuggestedParams()
+
+	pay, err := future.MakePaymentTxn(alice.Address.String(), bob.Address.String(), 1000, nil, "", sp)
+	require.NoError(t, err)
+	overdraw, err := future.MakePaymentTxn(bob.Address.String(), alice.Address.String(), 100000000, nil, "", sp)
+	require.NoError(t, err)
+	gid, err := crypto.ComputeGroupID([]types.Transaction{pay, overdraw})
+	require.NoError(t, err)
+	pay.Group, overdraw.Group = gid, gid
+
+	response, err := ledger.Apply(sign(t, alice.PrivateKey, pay), sign(t, bob.PrivateKey, overdraw))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "transaction 1")
+	require.Len(t, response.Txns, 2)
+	require.Equal(t, uint64(10000000), ledger.Account(alice.Address).Amount)
+	require.Equal(t, uint64(10000000), ledger.Account(bob.Address).Amount)
+
+	// groups are validated before being evaluated
+	pay.Group = types.Digest{}
+	response, err = ledger.Apply(sign(t, alice.PrivateKey, pay), sign(t, bob.PrivateKey, overdraw))
+	require.Error(t, err)
+	require.NotEmpty(t, response.Error)
+}
+
+func TestAssets(t *testing.T) {
+	alice, bob := crypto.GenerateAccount(), crypto.GenerateAccount()
+	ledger := newLedger(t, alice, bob)
+	sp := ledger.SuggestedParams()
+	creator := alice.Address.String()
+
+	txn, err := future.MakeAssetCreateTxn(creator, nil, sp, 100, 0, false, creator, creator, creator, creator, "T", "test", "", "")
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, txn))
+	require.NoError(t, err)
+	created := ledger.Account(alice.Address).CreatedAssets
+	require.Len(t, created, 1)
+	index := created[0].Index
+
+	// transfers need the receiver to opt in
+	transfer, err := future.MakeAssetTransferTxn(creator, bob.Address.String(), 40, nil, sp, "", index)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, transfer))
+	require.Error(t, err)
+
+	optIn, err := future.MakeAssetAcceptanceTxn(bob.Address.String(), nil, sp, index)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, bob.PrivateKey, optIn), sign(t, alice.PrivateKey, transfer))
+	require.Error(t, err, "a group of two transactions needs a group ID")
+
+	_, err = ledger.Apply(sign(t, bob.PrivateKey, optIn))
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, transfer))
+	require.NoError(t, err)
+
+	holdings := ledger.Account(bob.Address).Assets
+	require.Len(t, holdings, 1)
+	require.Equal(t, uint64(40), holdings[0].Amount)
+
+	// frozen holdings cannot be sent
+	freeze, err := future.MakeAssetFreezeTxn(creator, nil, sp, index, bob.Address.String(), true)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, freeze))
+	require.NoError(t, err)
+	back, err := future.MakeAssetTransferTxn(bob.Address.String(), creator, 10, nil, sp, "", index)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, bob.PrivateKey, back))
+	require.Error(t, err)
+
+	// but can be clawed back
+	clawback, err := future.MakeAssetRevocationTxn(creator, bob.Address.String(), 10, creator, nil, sp, index)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, clawback))
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), ledger.Account(bob.Address).Assets[0].Amount)
+}
+
+func TestApplication(t *testing.T) {
+	alice := crypto.GenerateAccount()
+	ledger := newLedger(t, alice)
+	sp := ledger.SuggestedParams()
+	approval := assemble(t, counterApproval)
+	clear := assemble(t, "#pragma version 2\nint 1")
+	schema := types.StateSchema{NumUint: 1}
+
+	create, err := future.MakeApplicationCreateTx(true, approval, clear, schema, schema, nil, nil, nil, nil, sp, alice.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+	require.NoError(t, err)
+	response, err := ledger.Apply(sign(t, alice.PrivateKey, create))
+	require.NoError(t, err)
+	require.Equal(t, []string{"ApprovalProgram", "PASS"}, response.Txns[0].AppCallMessages)
+	created := ledger.Account(alice.Address).CreatedApps
+	require.Len(t, created, 1)
+	appID := created[0].Id
+
+	call, err := future.MakeApplicationNoOpTx(appID, nil, nil, nil, nil, sp, alice.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+	require.NoError(t, err)
+	stxn := sign(t, alice.PrivateKey, call)
+	request := ledger.DryrunRequest(stxn)
+	response, err = ledger.Apply(stxn)
+	require.NoError(t, err)
+
+	global, ok := ledger.GlobalState(appID)
+	require.True(t, ok)
+	require.True(t, global["counter"].Equal(appstate.UintValue(1)))
+	local, ok := ledger.LocalState(alice.Address, appID)
+	require.True(t, ok)
+	require.True(t, local["calls"].Equal(appstate.UintValue(1)))
+
+	// the response reads like one of algod
+	report, err := dryrun.NewReport(request, response)
+	require.NoError(t, err)
+	require.NoError(t, report.AssertApproved(0))
+	require.NoError(t, report.AssertGlobalDelta(0, "counter", appstate.UintValue(1)))
+	require.NoError(t, report.AssertLocalDelta(0, alice.Address, "calls", appstate.UintValue(1)))
+
+	// exceeding the schema fails the call, leaving the state unchanged
+	ledger.st.apps[appID].globalSchema = types.StateSchema{}
+	_, err = ledger.Apply(stxn)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "schema")
+	global, _ = ledger.GlobalState(appID)
+	require.True(t, global["counter"].Equal(appstate.UintValue(1)))
+
+	// dryruns do not change the ledger
+	ledger.st.apps[appID].globalSchema = schema
+	_, err = ledger.Dryrun(stxn)
+	require.NoError(t, err)
+	global, _ = ledger.GlobalState(appID)
+	require.True(t, global["counter"].Equal(appstate.UintValue(1)))
+}
+
+func TestLogicSig(t *testing.T) {
+	alice := crypto.GenerateAccount()
+	ledger := newLedger(t, alice)
+	sp := ledger.SuggestedParams()
+
+	// the escrow only pays to alice
+	program := assemble(t, `#pragma version 2
+txn Receiver
+byte 0x`+hex.EncodeToString(alice.Address[:])+`
+==`)
+	lsig, err := crypto.MakeLogicSig(program, nil, nil, crypto.MultisigAccount{})
+	require.NoError(t, err)
+	escrow := crypto.AddressFromProgram(program)
+	ledger.Fund(escrow, 1000000)
+
+	sendTo := func(receiver types.Address) error {
+		txn, err := future.MakePaymentTxn(escrow.String(), receiver.String(), 1000, nil, "", sp)
+		require.NoError(t, err)
+		_, encoded, err := crypto.SignLogicsigTransaction(lsig, txn)
+		require.NoError(t, err)
+		response, err := ledger.ApplyRaw(encoded)
+		require.NotEmpty(t, response.Txns[0].LogicSigTrace)
+		return err
+	}
+	require.NoError(t, sendTo(alice.Address))
+	require.Error(t, sendTo(crypto.GenerateAccount().Address))
+}
+
+func TestRekeyedSender(t *testing.T) {
+	alice, bob := crypto.GenerateAccount(), crypto.GenerateAccount()
+	ledger := newLedger(t, alice)
+	sp := ledger.SuggestedParams()
+
+	rekey, err := future.MakePaymentTxn(alice.Address.String(), alice.Address.String(), 0, nil, "", sp)
+	require.NoError(t, err)
+	rekey.RekeyTo = bob.Address
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, rekey))
+	require.NoError(t, err)
+
+	pay, err := future.MakePaymentTxn(alice.Address.String(), bob.Address.String(), 1000000, nil, "", sp)
+	require.NoError(t, err)
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, pay))
+	require.Error(t, err, "alice no longer controls her account")
+
+	// the signature of bob needs AuthAddr to name him, like algod requires
+	stxn := sign(t, bob.PrivateKey, pay)
+	require.Equal(t, bob.Address, stxn.AuthAddr)
+	stxn.AuthAddr = types.Address{}
+	_, err = ledger.Apply(stxn)
+	require.Error(t, err)
+
+	_, err = ledger.Apply(sign(t, bob.PrivateKey, pay))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000000), ledger.Account(bob.Address).Amount)
+}
+
+func TestPooledFees(t *testing.T) {
+	alice, bob := crypto.GenerateAccount(), crypto.GenerateAccount()
+	ledger := newLedger(t, alice, bob)
+	sp := ledger.SuggestedParams()
+
+	// alice pays the fee of bob
+	pay, err := future.MakePaymentTxn(alice.Address.String(), bob.Address.String(), 1000, nil, "", sp)
+	require.NoError(t, err)
+	back, err := future.MakePaymentTxn(bob.Address.String(), alice.Address.String(), 1000, nil, "", sp)
+	require.NoError(t, err)
+	pay.Fee, back.Fee = 2000, 0
+	gid, err := crypto.ComputeGroupID([]types.Transaction{pay, back})
+	require.NoError(t, err)
+	pay.Group, back.Group = gid, gid
+
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, pay), sign(t, bob.PrivateKey, back))
+	require.NoError(t, err)
+	require.Equal(t, uint64(10000000-2000), ledger.Account(alice.Address).Amount)
+	require.Equal(t, uint64(10000000), ledger.Account(bob.Address).Amount)
+
+	// before v28, every transaction pays its own fee
+	ledger, err = NewLedger(consensus.V27)
+	require.NoError(t, err)
+	ledger.Fund(alice.Address, 10000000)
+	ledger.Fund(bob.Address, 10000000)
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, pay), sign(t, bob.PrivateKey, back))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "minimum fee")
+}
+
+func TestGroupEffects(t *testing.T) {
+	alice := crypto.GenerateAccount()
+	ledger := newLedger(t, alice)
+	sp := ledger.SuggestedParams()
+	clear := assemble(t, "#pragma version 4\nint 1")
+
+	// the second application reads the scratch space left by the first one
+	// and the index it was given
+	first := assemble(t, `#pragma version 4
+int 42
+store 3
+int 1`)
+	second := assemble(t, `#pragma version 4
+gload 0 3
+int 42
+==
+assert
+int 0
+gaids
+int 1
+==`)
+	var txns []types.Transaction
+	for _, approval := range [][]byte{first, second} {
+		txn, err := future.MakeApplicationCreateTx(false, approval, clear, types.StateSchema{}, types.StateSchema{}, nil, nil, nil, nil, sp, alice.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+		require.NoError(t, err)
+		txns = append(txns, txn)
+	}
+	gid, err := crypto.ComputeGroupID(txns)
+	require.NoError(t, err)
+	txns[0].Group, txns[1].Group = gid, gid
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, txns[0]), sign(t, alice.PrivateKey, txns[1]))
+	require.NoError(t, err)
+	require.Len(t, ledger.Account(alice.Address).CreatedApps, 2)
+
+	// transactions cannot read the effects of the ones after them
+	txns[0], txns[1] = txns[1], txns[0]
+	txns[0].Group, txns[1].Group = types.Digest{}, types.Digest{}
+	gid, err = crypto.ComputeGroupID(txns)
+	require.NoError(t, err)
+	txns[0].Group, txns[1].Group = gid, gid
+	_, err = ledger.Apply(sign(t, alice.PrivateKey, txns[0]), sign(t, alice.PrivateKey, txns[1]))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not come before")
+}
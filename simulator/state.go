@@ -0,0 +1,306 @@
+package simulator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/jffp113/go-algorand-sdk/appstate"
+	"github.com/jffp113/go-algorand-sdk/client/v2/common/models"
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// holding is the balance of an account in an asset
+type holding struct {
+	amount uint64
+	frozen bool
+}
+
+// localState is the local state of an account opted in an application
+type localState struct {
+	schema types.StateSchema
+	kv     appstate.State
+}
+
+type account struct {
+	amount   uint64
+	authAddr types.Address
+	holdings map[uint64]holding
+	locals   map[uint64]*localState
+}
+
+type application struct {
+	creator      types.Address
+	approval     []byte
+	clear        []byte
+	globalSchema types.StateSchema
+	localSchema  types.StateSchema
+	global       appstate.State
+}
+
+type asset struct {
+	creator types.Address
+	params  types.AssetParams
+}
+
+// state holds the accounts, applications and assets of a ledger
+type state struct {
+	accounts map[types.Address]*account
+	apps     map[uint64]*application
+	assets   map[uint64]*asset
+
+	// lastIndex is the last index given to an application or asset
+	lastIndex uint64
+
+	// touched holds the accounts modified by the group being applied, whose
+	// minimum balance is checked at the end of the group
+	touched map[types.Address]bool
+}
+
+func newState() *state {
+	return &state{
+		accounts: make(map[types.Address]*account),
+		apps:     make(map[uint64]*application),
+		assets:   make(map[uint64]*asset),
+		touched:  make(map[types.Address]bool),
+	}
+}
+
+// clone returns a deep copy of the state, with no account touched
+func (st *state) clone() *state {
+	c := newState()
+	c.lastIndex = st.lastIndex
+	for addr, a := range st.accounts {
+		ca := &account{
+			amount:   a.amount,
+			authAddr: a.authAddr,
+			holdings: make(map[uint64]holding, len(a.holdings)),
+			locals:   make(map[uint64]*localState, len(a.locals)),
+		}
+		for id, h := range a.holdings {
+			ca.holdings[id] = h
+		}
+		for id, local := range a.locals {
+			ca.locals[id] = &localState{schema: local.schema, kv: local.kv.Clone()}
+		}
+		c.accounts[addr] = ca
+	}
+	for id, app := range st.apps {
+		capp := *app
+		capp.global = app.global.Clone()
+		c.apps[id] = &capp
+	}
+	for id, a := range st.assets {
+		ca := *a
+		c.assets[id] = &ca
+	}
+	return c
+}
+
+// mutable returns the account at addr, creating it if needed, and marks it
+// as touched
+func (st *state) mutable(addr types.Address) *account {
+	st.touched[addr] = true
+	a, ok := st.accounts[addr]
+	if !ok {
+		a = &account{holdings: make(map[uint64]holding), locals: make(map[uint64]*localState)}
+		st.accounts[addr] = a
+	}
+	return a
+}
+
+// localState returns the local state of addr in appID, or nil when addr has
+// not opted in the application
+func (st *state) localState(addr types.Address, appID uint64) *localState {
+	a, ok := st.accounts[addr]
+	if !ok {
+		return nil
+	}
+	return a.locals[appID]
+}
+
+// newIndex returns the index of a new application or asset
+func (st *state) newIndex() uint64 {
+	st.lastIndex++
+	return st.lastIndex
+}
+
+// reserveIndex makes sure new applications and assets do not reuse index
+func (st *state) reserveIndex(index uint64) {
+	if index > st.lastIndex {
+		st.lastIndex = index
+	}
+}
+
+// schemaCost returns the minimum balance needed by a state schema
+func schemaCost(params consensus.Params, schema types.StateSchema) uint64 {
+	return schema.NumUint*(params.SchemaMinBalancePerEntry+params.SchemaUintMinBalance) +
+		schema.NumByteSlice*(params.SchemaMinBalancePerEntry+params.SchemaBytesMinBalance)
+}
+
+// minBalance returns the minimum balance of the account at addr
+func (st *state) minBalance(params consensus.Params, addr types.Address) uint64 {
+	a, ok := st.accounts[addr]
+	if !ok {
+		return 0
+	}
+	min := params.MinBalance + uint64(len(a.holdings))*params.MinBalance
+	for _, local := range a.locals {
+		min += params.AppFlatOptInMinBalance + schemaCost(params, local.schema)
+	}
+	for _, app := range st.apps {
+		if app.creator == addr {
+			min += params.AppFlatParamsMinBalance + schemaCost(params, app.globalSchema)
+		}
+	}
+	return min
+}
+
+// empty reports whether the account holds nothing, in which case it needs
+// no minimum balance
+func (a *account) empty() bool {
+	return a.amount == 0 && len(a.holdings) == 0 && len(a.locals) == 0
+}
+
+// checkSchema checks that a state fits in schema
+func checkSchema(kv appstate.State, schema types.StateSchema) error {
+	var uints, byteSlices uint64
+	for _, v := range kv {
+		if v.Type == appstate.TypeUint {
+			uints++
+		} else {
+			byteSlices++
+		}
+	}
+	if uints > schema.NumUint {
+		return fmt.Errorf("%d uints exceed the schema of %d", uints, schema.NumUint)
+	}
+	if byteSlices > schema.NumByteSlice {
+		return fmt.Errorf("%d byte slices exceed the schema of %d", byteSlices, schema.NumByteSlice)
+	}
+	return nil
+}
+
+func sortIDs(ids []uint64) []uint64 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func sortAddresses(addrs []types.Address) []types.Address {
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+func addressString(addr types.Address) string {
+	if addr.IsZero() {
+		return ""
+	}
+	return addr.String()
+}
+
+func decodeOptionalAddress(addr string) (types.Address, error) {
+	if addr == "" {
+		return types.Address{}, nil
+	}
+	return types.DecodeAddress(addr)
+}
+
+func stateSchema(schema models.ApplicationStateSchema) types.StateSchema {
+	return types.StateSchema{NumUint: schema.NumUint, NumByteSlice: schema.NumByteSlice}
+}
+
+func modelSchema(schema types.StateSchema) models.ApplicationStateSchema {
+	return models.ApplicationStateSchema{NumUint: schema.NumUint, NumByteSlice: schema.NumByteSlice}
+}
+
+// model returns the application in the shape of the REST API
+func (app *application) model(id uint64) models.Application {
+	return models.Application{
+		Id: id,
+		Params: models.ApplicationParams{
+			Creator:           app.creator.String(),
+			ApprovalProgram:   app.approval,
+			ClearStateProgram: app.clear,
+			GlobalState:       app.global.Encode(),
+			GlobalStateSchema: modelSchema(app.globalSchema),
+			LocalStateSchema:  modelSchema(app.localSchema),
+		},
+	}
+}
+
+func applicationFromModel(app models.Application) (*application, error) {
+	creator, err := decodeOptionalAddress(app.Params.Creator)
+	if err != nil {
+		return nil, fmt.Errorf("creator: %v", err)
+	}
+	global, err := appstate.Decode(app.Params.GlobalState)
+	if err != nil {
+		return nil, fmt.Errorf("global state: %v", err)
+	}
+	return &application{
+		creator:      creator,
+		approval:     app.Params.ApprovalProgram,
+		clear:        app.Params.ClearStateProgram,
+		globalSchema: stateSchema(app.Params.GlobalStateSchema),
+		localSchema:  stateSchema(app.Params.LocalStateSchema),
+		global:       global,
+	}, nil
+}
+
+// model returns the asset in the shape of the REST API
+func (a *asset) model(index uint64) models.Asset {
+	p := a.params
+	params := models.AssetParams{
+		Creator:       a.creator.String(),
+		Total:         p.Total,
+		Decimals:      uint64(p.Decimals),
+		DefaultFrozen: p.DefaultFrozen,
+		UnitName:      p.UnitName,
+		Name:          p.AssetName,
+		Url:           p.URL,
+		Manager:       addressString(p.Manager),
+		Reserve:       addressString(p.Reserve),
+		Freeze:        addressString(p.Freeze),
+		Clawback:      addressString(p.Clawback),
+	}
+	if p.MetadataHash != ([types.AssetMetadataHashLen]byte{}) {
+		params.MetadataHash = append([]byte{}, p.MetadataHash[:]...)
+	}
+	return models.Asset{Index: index, Params: params}
+}
+
+func assetFromModel(a models.Asset) (*asset, error) {
+	p := a.Params
+	if len(p.MetadataHash) > types.AssetMetadataHashLen {
+		return nil, fmt.Errorf("metadata hash of %d bytes is longer than %d bytes", len(p.MetadataHash), types.AssetMetadataHashLen)
+	}
+	result := &asset{params: types.AssetParams{
+		Total:         p.Total,
+		Decimals:      uint32(p.Decimals),
+		DefaultFrozen: p.DefaultFrozen,
+		UnitName:      p.UnitName,
+		AssetName:     p.Name,
+		URL:           p.Url,
+	}}
+	copy(result.params.MetadataHash[:], p.MetadataHash)
+	addrs := []struct {
+		name string
+		from string
+		to   *types.Address
+	}{
+		{"creator", p.Creator, &result.creator},
+		{"manager", p.Manager, &result.params.Manager},
+		{"reserve", p.Reserve, &result.params.Reserve},
+		{"freeze", p.Freeze, &result.params.Freeze},
+		{"clawback", p.Clawback, &result.params.Clawback},
+	}
+	for _, addr := range addrs {
+		decoded, err := decodeOptionalAddress(addr.from)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", addr.name, err)
+		}
+		*addr.to = decoded
+	}
+	return result, nil
+}
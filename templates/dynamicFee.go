@@ -35,6 +35,13 @@ func MakeDynamicFee(receiver, closeRemainder string, amount, firstValid, lastVal
 	return makeDynamicFeeWithLease(receiver, closeRemainder, leaseString, amount, firstValid, lastValid)
 }
 
+// MakeDynamicFeeWithLease is as MakeDynamicFee, but the caller specifies the
+// lease, for instance one derived with the lease package
+func MakeDynamicFeeWithLease(receiver, closeRemainder string, lease [32]byte, amount, firstValid, lastValid uint64) (DynamicFee, error) {
+	leaseString := base64.StdEncoding.EncodeToString(lease[:])
+	return makeDynamicFeeWithLease(receiver, closeRemainder, leaseString, amount, firstValid, lastValid)
+}
+
 // makeDynamicFeeWithLease is as MakeDynamicFee, but the caller can specify the lease (using b64 string)
 func makeDynamicFeeWithLease(receiver, closeRemainder, lease string, amount, firstValid, lastValid uint64) (DynamicFee, error) {
 	const referenceProgram = "ASAFAgEHBgUmAyD+vKC7FEpaTqe0OKRoGsgObKEFvLYH/FZTJclWlfaiEyDmmpYeby1feshmB5JlUr6YI17TM2PKiJGLuck4qRW2+SB/g7Flf/H8U7ktwYFIodZd/C1LH6PWdyhK3dIAEm2QaTIEIhIzABAjEhAzAAcxABIQMwAIMQESEDEWIxIQMRAjEhAxBygSEDEJKRIQMQgkEhAxAiUSEDEEIQQSEDEGKhIQ"
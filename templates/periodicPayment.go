@@ -85,6 +85,13 @@ func MakePeriodicPayment(receiver string, amount, withdrawWindow, period, expiry
 	return makePeriodicPaymentWithLease(receiver, leaseString, amount, withdrawWindow, period, expiryRound, maxFee)
 }
 
+// MakePeriodicPaymentWithLease is as MakePeriodicPayment, but the caller
+// specifies the lease, for instance one derived with the lease package
+func MakePeriodicPaymentWithLease(receiver string, lease [32]byte, amount, withdrawWindow, period, expiryRound, maxFee uint64) (PeriodicPayment, error) {
+	leaseString := base64.StdEncoding.EncodeToString(lease[:])
+	return makePeriodicPaymentWithLease(receiver, leaseString, amount, withdrawWindow, period, expiryRound, maxFee)
+}
+
 // makePeriodicPaymentWithLease is as MakePeriodicPayment, but the caller can specify the lease (using b64 string)
 func makePeriodicPaymentWithLease(receiver, lease string, amount, withdrawWindow, period, expiryRound, maxFee uint64) (PeriodicPayment, error) {
 	const referenceProgram = "ASAHAQYFAAQDByYCIAECAwQFBgcIAQIDBAUGBwgBAgMEBQYHCAECAwQFBgcIIJKvkYTkEzwJf2arzJOxERsSogG9nQzKPkpIoc4TzPTFMRAiEjEBIw4QMQIkGCUSEDEEIQQxAggSEDEGKBIQMQkyAxIxBykSEDEIIQUSEDEJKRIxBzIDEhAxAiEGDRAxCCUSEBEQ"
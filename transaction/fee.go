@@ -0,0 +1,215 @@
+package transaction
+
+import (
+	"fmt"
+
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// maxFeeIterations bounds the search for a fee that pays for its own encoding
+const maxFeeIterations = 8
+
+// SignatureShape describes the authorization a transaction will carry once
+// signed, so that its encoded size can be computed before any key is used.
+// The zero value describes a transaction signed by a single ed25519 key.
+type SignatureShape struct {
+	// Multisig is set when the transaction will be signed by a multisig account.
+	// Threshold subsignatures are assumed.
+	Multisig *crypto.MultisigAccount
+
+	// LogicSig is set when the transaction will be authorized by this LogicSig,
+	// including its program, arguments and delegation signature
+	LogicSig *types.LogicSig
+
+	// AuthAddr is set when the sender has been rekeyed to another account
+	AuthAddr types.Address
+
+	// GroupEnvelop is any envelop produced with the same scheme and group public
+	// key as the one the transaction will carry. Its signature is only used for its size.
+	GroupEnvelop *types.GroupEnvelop
+}
+
+// signaturePlaceholder is a non-zero signature so that it is not omitted from the encoding
+var signaturePlaceholder = func() (sig types.Signature) {
+	for i := range sig {
+		sig[i] = 0xff
+	}
+	return
+}()
+
+// groupPlaceholder is a non-zero group id, for the same reason
+var groupPlaceholder = types.Digest{0xff}
+
+// placeholder builds a SignedTxn whose signature fields have the exact size of
+// the ones described by the shape
+func (shape SignatureShape) placeholder(txn types.Transaction) (stx types.SignedTxn, err error) {
+	stx.Txn = txn
+	stx.AuthAddr = shape.AuthAddr
+
+	switch {
+	case shape.Multisig != nil && shape.LogicSig != nil:
+		err = fmt.Errorf("signature shape cannot be both multisig and logicsig")
+		return
+	case shape.Multisig != nil:
+		err = shape.Multisig.Validate()
+		if err != nil {
+			return
+		}
+		stx.Msig = multisigPlaceholder(*shape.Multisig)
+	case shape.LogicSig != nil:
+		stx.Lsig = *shape.LogicSig
+	default:
+		stx.Sig = signaturePlaceholder
+	}
+
+	if shape.GroupEnvelop != nil {
+		stx.GroupSignature = *shape.GroupEnvelop
+	}
+	return
+}
+
+func multisigPlaceholder(ma crypto.MultisigAccount) types.MultisigSig {
+	msig := types.MultisigSig{
+		Version:   ma.Version,
+		Threshold: ma.Threshold,
+		Subsigs:   make([]types.MultisigSubsig, len(ma.Pks)),
+	}
+	for i, pk := range ma.Pks {
+		msig.Subsigs[i].Key = pk
+		if i < int(ma.Threshold) {
+			msig.Subsigs[i].Sig = signaturePlaceholder
+		}
+	}
+	return msig
+}
+
+// EstimateSignedSize returns the length of the transaction once encoded as a
+// SignedTxn carrying the signature described by shape
+func EstimateSignedSize(txn types.Transaction, shape SignatureShape) (uint64, error) {
+	stx, err := shape.placeholder(txn)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(msgpack.Encode(stx))), nil
+}
+
+// CalculateFee returns the fee the transaction must pay at feePerByte once signed
+// as described by shape, never lower than the MinTxnFee of the protocol. The fee
+// field is part of the encoding, so the returned fee already accounts for its own size.
+func CalculateFee(txn types.Transaction, feePerByte uint64, shape SignatureShape, params consensus.Params) (types.MicroAlgos, error) {
+	return calculateFee(txn, feePerByte, shape, types.MicroAlgos(params.MinTxnFee))
+}
+
+// calculateFee returns the fee the transaction must pay at feePerByte, never
+// lower than minFee
+func calculateFee(txn types.Transaction, feePerByte uint64, shape SignatureShape, minFee types.MicroAlgos) (types.MicroAlgos, error) {
+	// starting from no fee, each round can only grow the fee, so it settles
+	txn.Fee = 0
+	for i := 0; i < maxFeeIterations; i++ {
+		size, err := EstimateSignedSize(txn, shape)
+		if err != nil {
+			return 0, err
+		}
+		fee := types.MicroAlgos(size * feePerByte)
+		if fee < minFee {
+			fee = minFee
+		}
+		if fee == txn.Fee {
+			return fee, nil
+		}
+		txn.Fee = fee
+	}
+	return 0, fmt.Errorf("fee did not converge after %d iterations", maxFeeIterations)
+}
+
+// AssignFee sets the fee of the transaction from the suggested params. With a
+// flat fee the suggested fee is used as is, otherwise it is a fee per byte of
+// the transaction signed as described by shape. The fee is never lower than
+// the MinTxnFee of params.ConsensusVersion, which must be known.
+func AssignFee(txn *types.Transaction, params types.SuggestedParams, shape SignatureShape) error {
+	protocol, err := consensus.FromSuggestedParams(params)
+	if err != nil {
+		return err
+	}
+	if params.FlatFee {
+		txn.Fee = params.Fee
+		if minFee := types.MicroAlgos(protocol.MinTxnFee); txn.Fee < minFee {
+			txn.Fee = minFee
+		}
+		return nil
+	}
+	fee, err := CalculateFee(*txn, uint64(params.Fee), shape, protocol)
+	if err != nil {
+		return err
+	}
+	txn.Fee = fee
+	return nil
+}
+
+// AssignGroupFees recalculates the fee of every member of a group now that all
+// of them are known, then assigns the group id. Fees must be set before the
+// group id since they are covered by it, and each size accounts for the group
+// field. shapes holds the signature shape of every transaction, in order; a nil
+// shapes means every transaction is signed by a single key.
+//
+// When the protocol of params.ConsensusVersion pools the fees of groups and
+// the fee is per byte, every member pays for its own size only and the first
+// one also pays what is left of the minimum fee of the whole group.
+func AssignGroupFees(txns []types.Transaction, params types.SuggestedParams, shapes []SignatureShape) ([]types.Transaction, error) {
+	if shapes != nil && len(shapes) != len(txns) {
+		return nil, fmt.Errorf("got %d signature shapes for %d transactions", len(shapes), len(txns))
+	}
+	protocol, err := consensus.FromSuggestedParams(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) > protocol.MaxTxGroupSize {
+		return nil, fmt.Errorf("group of %d transactions exceeds max group size %d", len(txns), protocol.MaxTxGroupSize)
+	}
+	pooled := protocol.EnableFeePooling && !params.FlatFee
+
+	result := make([]types.Transaction, len(txns))
+	shapeOf := func(i int) SignatureShape {
+		if shapes == nil {
+			return SignatureShape{}
+		}
+		return shapes[i]
+	}
+	// members are assigned from the last one, so that the first one knows
+	// the fees paid by the others
+	var others types.MicroAlgos
+	for i := len(txns) - 1; i >= 0; i-- {
+		txn := txns[i]
+		// any non-zero digest has the size of the real group id
+		txn.Group = groupPlaceholder
+		if !pooled {
+			err = AssignFee(&txn, params, shapeOf(i))
+		} else if i > 0 {
+			txn.Fee, err = calculateFee(txn, uint64(params.Fee), shapeOf(i), 0)
+			others += txn.Fee
+		} else {
+			var minFee types.MicroAlgos
+			if groupFee := types.MicroAlgos(protocol.MinTxnFee * uint64(len(txns))); groupFee > others {
+				minFee = groupFee - others
+			}
+			txn.Fee, err = calculateFee(txn, uint64(params.Fee), shapeOf(i), minFee)
+		}
+		if err != nil {
+			return nil, err
+		}
+		txn.Group = types.Digest{}
+		result[i] = txn
+	}
+
+	gid, err := crypto.ComputeGroupID(result)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Group = gid
+	}
+	return result, nil
+}
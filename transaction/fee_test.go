@@ -0,0 +1,150 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/encoding/msgpack"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+func makeFeeTestTxn(t *testing.T, sender types.Address) types.Transaction {
+	receiver := crypto.GenerateAccount()
+	tx, err := MakePaymentTxnWithFlatFee(sender.String(), receiver.Address.String(), 0, 1000, 1, 1001, []byte("fee test"), "", "testnet-v1.0", byteFromBase64("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="))
+	require.NoError(t, err)
+	return tx
+}
+
+func TestEstimateSignedSizeMatchesSignatures(t *testing.T) {
+	// single key, rekeyed to another account
+	sender := crypto.GenerateAccount()
+	auth := crypto.GenerateAccount()
+	tx := makeFeeTestTxn(t, sender.Address)
+	_, stxBytes, err := crypto.SignTransaction(auth.PrivateKey, tx)
+	require.NoError(t, err)
+	size, err := EstimateSignedSize(tx, SignatureShape{AuthAddr: auth.Address})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(stxBytes)), size)
+
+	// 2-of-3 multisig
+	accts := []crypto.Account{crypto.GenerateAccount(), crypto.GenerateAccount(), crypto.GenerateAccount()}
+	ma := crypto.MultisigAccount{Version: 1, Threshold: 2, Pks: []ed25519.PublicKey{accts[0].PublicKey, accts[1].PublicKey, accts[2].PublicKey}}
+	msigAddr, err := ma.Address()
+	require.NoError(t, err)
+	tx = makeFeeTestTxn(t, msigAddr)
+	_, partial, err := crypto.SignMultisigTransaction(accts[0].PrivateKey, ma, tx)
+	require.NoError(t, err)
+	_, stxBytes, err = crypto.AppendMultisigTransaction(accts[1].PrivateKey, ma, partial)
+	require.NoError(t, err)
+	size, err = EstimateSignedSize(tx, SignatureShape{Multisig: &ma})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(stxBytes)), size)
+
+	// contract account with arguments
+	lsig, err := crypto.MakeLogicSig([]byte{0x01, 0x20, 0x01, 0x01, 0x22}, [][]byte{[]byte("argument")}, nil, crypto.MultisigAccount{})
+	require.NoError(t, err)
+	tx = makeFeeTestTxn(t, crypto.AddressFromProgram(lsig.Logic))
+	_, stxBytes, err = crypto.SignLogicsigTransaction(lsig, tx)
+	require.NoError(t, err)
+	size, err = EstimateSignedSize(tx, SignatureShape{LogicSig: &lsig})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(stxBytes)), size)
+
+	_, err = EstimateSignedSize(tx, SignatureShape{LogicSig: &lsig, Multisig: &ma})
+	require.Error(t, err)
+}
+
+func TestCalculateFeePaysForItself(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	tx := makeFeeTestTxn(t, sender.Address)
+
+	params, err := consensus.Lookup(consensus.Latest)
+	require.NoError(t, err)
+	fee, err := CalculateFee(tx, 10, SignatureShape{}, params)
+	require.NoError(t, err)
+	tx.Fee = fee
+	_, stxBytes, err := crypto.SignTransaction(sender.PrivateKey, tx)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(10*len(stxBytes)), fee)
+
+	fee, err = CalculateFee(tx, 1, SignatureShape{}, params)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(params.MinTxnFee), fee)
+}
+
+func TestAssignFee(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	tx := makeFeeTestTxn(t, sender.Address)
+
+	// per byte fees pay for the signed transaction
+	require.NoError(t, AssignFee(&tx, types.SuggestedParams{Fee: 10}, SignatureShape{}))
+	_, stxBytes, err := crypto.SignTransaction(sender.PrivateKey, tx)
+	require.NoError(t, err)
+	require.Equal(t, types.MicroAlgos(10*len(stxBytes)), tx.Fee)
+
+	// flat fees are used as is, but never below the minimum fee
+	require.NoError(t, AssignFee(&tx, types.SuggestedParams{Fee: 2500, FlatFee: true}, SignatureShape{}))
+	require.Equal(t, types.MicroAlgos(2500), tx.Fee)
+	require.NoError(t, AssignFee(&tx, types.SuggestedParams{Fee: 10, FlatFee: true}, SignatureShape{}))
+	require.Equal(t, types.MicroAlgos(1000), tx.Fee)
+
+	require.Error(t, AssignFee(&tx, types.SuggestedParams{ConsensusVersion: "unknown"}, SignatureShape{}))
+}
+
+func TestAssignGroupFees(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	envelop := types.GroupEnvelop{Scheme: crypto.Ed25519ThresholdScheme, PublicKey: make([]byte, 100), Signature: make([]byte, 140)}
+	txns := []types.Transaction{makeFeeTestTxn(t, sender.Address), makeFeeTestTxn(t, sender.Address)}
+	shapes := []SignatureShape{{GroupEnvelop: &envelop}, {GroupEnvelop: &envelop}}
+	params := types.SuggestedParams{Fee: 10}
+
+	grouped, err := AssignGroupFees(txns, params, shapes)
+	require.NoError(t, err)
+	ungrouped := make([]types.Transaction, len(grouped))
+	for i, tx := range grouped {
+		tx.Group = types.Digest{}
+		ungrouped[i] = tx
+	}
+	gid, err := crypto.ComputeGroupID(ungrouped)
+	require.NoError(t, err)
+	for _, tx := range grouped {
+		require.Equal(t, gid, tx.Group)
+		stx := types.SignedTxn{Txn: tx, GroupSignature: envelop}
+		stx.Sig[0] = 1
+		require.Equal(t, types.MicroAlgos(10*len(msgpack.Encode(stx))), tx.Fee)
+	}
+
+	_, err = AssignGroupFees(txns, params, shapes[:1])
+	require.Error(t, err)
+}
+
+func TestAssignGroupFeesFollowsProtocol(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	txns := []types.Transaction{makeFeeTestTxn(t, sender.Address), makeFeeTestTxn(t, sender.Address), makeFeeTestTxn(t, sender.Address)}
+	fees := func(params types.SuggestedParams) []types.MicroAlgos {
+		grouped, err := AssignGroupFees(txns, params, nil)
+		require.NoError(t, err)
+		var fees []types.MicroAlgos
+		for _, tx := range grouped {
+			fees = append(fees, tx.Fee)
+		}
+		return fees
+	}
+
+	// from v28 the first transaction pays the minimum fee of the whole group
+	require.Equal(t, []types.MicroAlgos{3000, 0, 0}, fees(types.SuggestedParams{ConsensusVersion: consensus.V28}))
+	require.Equal(t, []types.MicroAlgos{1000, 1000, 1000}, fees(types.SuggestedParams{ConsensusVersion: consensus.V27}))
+
+	// flat fees are used as is, but never below the minimum fee
+	require.Equal(t, []types.MicroAlgos{2000, 2000, 2000}, fees(types.SuggestedParams{Fee: 2000, FlatFee: true, ConsensusVersion: consensus.V28}))
+	require.Equal(t, []types.MicroAlgos{1000, 1000, 1000}, fees(types.SuggestedParams{Fee: 10, FlatFee: true, ConsensusVersion: consensus.V28}))
+
+	_, err := AssignGroupFees(txns, types.SuggestedParams{ConsensusVersion: "unknown"}, nil)
+	require.Error(t, err)
+	_, err = AssignGroupFees(make([]types.Transaction, 17), types.SuggestedParams{}, nil)
+	require.Error(t, err)
+}
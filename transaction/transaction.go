@@ -788,7 +788,9 @@ func AssignGroupID(txns []types.Transaction, account string) (result []types.Tra
 	return result, nil
 }
 
-// EstimateSize returns the estimated length of the encoded transaction
+// EstimateSize returns the estimated length of the encoded transaction, assuming
+// a single signature. Use EstimateSignedSize for multisig, LogicSig, rekeyed or
+// group signed transactions.
 func EstimateSize(txn types.Transaction) (uint64, error) {
 	return uint64(len(msgpack.Encode(txn))) + NumOfAdditionalBytesAfterSigning, nil
 }
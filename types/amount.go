@@ -0,0 +1,200 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AlgoDecimals is the number of decimals of Algos: one Algo is 10^6 microAlgos
+const AlgoDecimals = 6
+
+// AlgoUnitName is the unit name used to format Algos
+const AlgoUnitName = "Algo"
+
+// Amount is an exact amount of Algos or of an asset: a number of base units
+// with the number of decimals used to display them. An Amount of 12345678
+// units with 6 decimals reads 12.345678.
+type Amount struct {
+	// Units is the amount in base units, microAlgos for Algos
+	Units uint64
+
+	// Decimals is the number of digits after the decimal point, between 0 and
+	// AssetMaxNumberOfDecimals
+	Decimals uint32
+
+	// UnitName is the name appended by Format, empty for none
+	UnitName string
+}
+
+// Algos returns an amount of microAlgos as an Amount
+func Algos(microalgos MicroAlgos) Amount {
+	return Amount{Units: uint64(microalgos), Decimals: AlgoDecimals, UnitName: AlgoUnitName}
+}
+
+// AssetAmount returns an amount of base units of the asset with the given
+// params as an Amount
+func AssetAmount(units uint64, params AssetParams) Amount {
+	return Amount{Units: units, Decimals: params.Decimals, UnitName: params.UnitName}
+}
+
+// ParseAmount parses s, a decimal number like "12.345678" with at most
+// decimals digits after the decimal point, into an Amount
+func ParseAmount(s string, decimals uint32) (Amount, error) {
+	if decimals > AssetMaxNumberOfDecimals {
+		return Amount{}, fmt.Errorf("%d decimals is more than the maximum of %d", decimals, AssetMaxNumberOfDecimals)
+	}
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if whole == "" && fraction == "" || !digits(whole) || !digits(fraction) {
+		return Amount{}, fmt.Errorf("%q is not a decimal amount", s)
+	}
+	if uint32(len(fraction)) > decimals {
+		return Amount{}, fmt.Errorf("%q has more than %d decimals", s, decimals)
+	}
+	fraction += strings.Repeat("0", int(decimals)-len(fraction))
+
+	units, err := strconv.ParseUint("0"+whole+fraction, 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%q overflows the amount of base units", s)
+	}
+	return Amount{Units: units, Decimals: decimals}, nil
+}
+
+// ParseAlgos parses s, a number of Algos like "12.345678" optionally followed
+// by AlgoUnitName
+func ParseAlgos(s string) (Amount, error) {
+	return parseWithUnit(s, AlgoDecimals, AlgoUnitName)
+}
+
+// ParseAssetAmount parses s, an amount of the asset with the given params
+// like "12.34" optionally followed by the unit name of the asset
+func ParseAssetAmount(s string, params AssetParams) (Amount, error) {
+	return parseWithUnit(s, params.Decimals, params.UnitName)
+}
+
+func parseWithUnit(s string, decimals uint32, unitName string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if unitName != "" {
+		s = strings.TrimSpace(strings.TrimSuffix(s, unitName))
+	}
+	amount, err := ParseAmount(s, decimals)
+	amount.UnitName = unitName
+	return amount, err
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount as a decimal number with all its decimals, like
+// "12.345678"
+func (a Amount) String() string {
+	units := strconv.FormatUint(a.Units, 10)
+	if a.Decimals == 0 {
+		return units
+	}
+	if pad := int(a.Decimals) + 1 - len(units); pad > 0 {
+		units = strings.Repeat("0", pad) + units
+	}
+	point := len(units) - int(a.Decimals)
+	return units[:point] + "." + units[point:]
+}
+
+// Format formats the amount like String followed by its unit name, like
+// "12.345678 Algo"
+func (a Amount) Format() string {
+	if a.UnitName == "" {
+		return a.String()
+	}
+	return a.String() + " " + a.UnitName
+}
+
+// MicroAlgos returns the amount in microAlgos. It fails if the amount does
+// not have AlgoDecimals decimals.
+func (a Amount) MicroAlgos() (MicroAlgos, error) {
+	if a.Decimals != AlgoDecimals {
+		return 0, fmt.Errorf("an amount with %d decimals is not an amount of Algos", a.Decimals)
+	}
+	return MicroAlgos(a.Units), nil
+}
+
+// AssetUnits returns the amount in base units of the asset with the given
+// params. It fails if the amount does not have the decimals of the asset, or
+// names another unit.
+func (a Amount) AssetUnits(params AssetParams) (uint64, error) {
+	if a.Decimals != params.Decimals {
+		return 0, fmt.Errorf("an amount with %d decimals is not an amount of an asset with %d decimals", a.Decimals, params.Decimals)
+	}
+	if a.UnitName != "" && a.UnitName != params.UnitName {
+		return 0, fmt.Errorf("an amount of %s is not an amount of %q", a.UnitName, params.UnitName)
+	}
+	return a.Units, nil
+}
+
+// Add returns a + b. It fails on overflow or if the amounts have different
+// decimals.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if err := a.sameDecimals(b); err != nil {
+		return Amount{}, err
+	}
+	units, overflowed := OAdd(a.Units, b.Units)
+	if overflowed {
+		return Amount{}, fmt.Errorf("%s + %s overflows", a, b)
+	}
+	a.Units = units
+	return a, nil
+}
+
+// Sub returns a - b. It fails if b is greater than a or if the amounts have
+// different decimals.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if err := a.sameDecimals(b); err != nil {
+		return Amount{}, err
+	}
+	units, overflowed := OSub(a.Units, b.Units)
+	if overflowed {
+		return Amount{}, fmt.Errorf("%s - %s is negative", a, b)
+	}
+	a.Units = units
+	return a, nil
+}
+
+// Mul returns a times n. It fails on overflow.
+func (a Amount) Mul(n uint64) (Amount, error) {
+	units, overflowed := OMul(a.Units, n)
+	if overflowed {
+		return Amount{}, fmt.Errorf("%s * %d overflows", a, n)
+	}
+	a.Units = units
+	return a, nil
+}
+
+// Cmp compares a and b and returns -1, 0 or +1 when a is less than, equal to
+// or greater than b. It fails if the amounts have different decimals.
+func (a Amount) Cmp(b Amount) (int, error) {
+	if err := a.sameDecimals(b); err != nil {
+		return 0, err
+	}
+	switch {
+	case a.Units < b.Units:
+		return -1, nil
+	case a.Units > b.Units:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (a Amount) sameDecimals(b Amount) error {
+	if a.Decimals != b.Decimals {
+		return fmt.Errorf("amounts with %d and %d decimals cannot be combined", a.Decimals, b.Decimals)
+	}
+	return nil
+}
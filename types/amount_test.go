@@ -0,0 +1,109 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndFormatAmount(t *testing.T) {
+	valid := []struct {
+		in       string
+		decimals uint32
+		units    uint64
+		out      string
+	}{
+		{"12.345678", 6, 12345678, "12.345678"},
+		{"12.3", 6, 12300000, "12.300000"},
+		{"0.000001", 6, 1, "0.000001"},
+		{".5", 2, 50, "0.50"},
+		{"7.", 1, 70, "7.0"},
+		{"42", 0, 42, "42"},
+		{"18446744073709551615", 0, math.MaxUint64, "18446744073709551615"},
+		{"1.8446744073709551615", 19, math.MaxUint64, "1.8446744073709551615"},
+	}
+	for _, test := range valid {
+		amount, err := ParseAmount(test.in, test.decimals)
+		require.NoError(t, err, test.in)
+		require.Equal(t, test.units, amount.Units, test.in)
+		require.Equal(t, test.out, amount.String(), test.in)
+	}
+
+	invalid := []struct {
+		in       string
+		decimals uint32
+	}{
+		{"", 6},
+		{".", 6},
+		{"-1", 6},
+		{"1e6", 6},
+		{"1.2.3", 6},
+		{"0.1234567", 6},
+		{"1.5", 0},
+		{"18446744073709551616", 0},
+		{"1", 20},
+	}
+	for _, test := range invalid {
+		_, err := ParseAmount(test.in, test.decimals)
+		require.Error(t, err, test.in)
+	}
+
+	// amounts of Algos and assets carry their unit name
+	algos, err := ParseAlgos("1.5 Algo")
+	require.NoError(t, err)
+	require.Equal(t, Algos(1500000), algos)
+	require.Equal(t, "1.500000 Algo", algos.Format())
+	microalgos, err := algos.MicroAlgos()
+	require.NoError(t, err)
+	require.Equal(t, MicroAlgos(1500000), microalgos)
+
+	params := AssetParams{Decimals: 2, UnitName: "USDC"}
+	usdc, err := ParseAssetAmount("3.25USDC", params)
+	require.NoError(t, err)
+	require.Equal(t, AssetAmount(325, params), usdc)
+	require.Equal(t, "3.25 USDC", usdc.Format())
+	_, err = usdc.MicroAlgos()
+	require.Error(t, err)
+	units, err := usdc.AssetUnits(params)
+	require.NoError(t, err)
+	require.Equal(t, uint64(325), units)
+	_, err = usdc.AssetUnits(AssetParams{Decimals: 6, UnitName: "USDC"})
+	require.Error(t, err)
+	_, err = Algos(1000000).AssetUnits(AssetParams{Decimals: 6, UnitName: "USDT"})
+	require.Error(t, err)
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := Algos(1500000)
+	b := Algos(250000)
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, Algos(1750000), sum)
+
+	diff, err := a.Sub(b)
+	require.NoError(t, err)
+	require.Equal(t, Algos(1250000), diff)
+	_, err = b.Sub(a)
+	require.Error(t, err)
+
+	product, err := b.Mul(4)
+	require.NoError(t, err)
+	require.Equal(t, Algos(1000000), product)
+	_, err = Algos(math.MaxUint64).Mul(2)
+	require.Error(t, err)
+	_, err = Algos(math.MaxUint64).Add(Algos(1))
+	require.Error(t, err)
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	require.Equal(t, 1, cmp)
+
+	// amounts with different decimals do not combine
+	asset := AssetAmount(1, AssetParams{Decimals: 2})
+	_, err = a.Add(asset)
+	require.Error(t, err)
+	_, err = a.Cmp(asset)
+	require.Error(t, err)
+}
@@ -28,13 +28,16 @@ const (
 
 const masterDerivationKeyLenBytes = 32
 
-// MaxTxGroupSize is max number of transactions in a single group
+// MaxTxGroupSize is max number of transactions in a single group.
+// See package consensus for the values of each protocol version.
 const MaxTxGroupSize = 16
 
-// LogicSigMaxSize is a max TEAL program size (with args)
+// LogicSigMaxSize is a max TEAL program size (with args).
+// See package consensus for the values of each protocol version.
 const LogicSigMaxSize = 1000
 
-// LogicSigMaxCost is a max execution const of a TEAL program
+// LogicSigMaxCost is a max execution const of a TEAL program.
+// See package consensus for the values of each protocol version.
 const LogicSigMaxCost = 20000
 
 // MicroAlgos are the base unit of currency in Algorand
@@ -57,12 +60,14 @@ type Digest [hashLenBytes]byte
 
 const microAlgoConversionFactor = 1e6
 
-// ToAlgos converts amount in microAlgos to Algos
+// ToAlgos converts amount in microAlgos to Algos. Large amounts lose
+// precision, see Algos for an exact amount.
 func (microalgos MicroAlgos) ToAlgos() float64 {
 	return float64(microalgos) / microAlgoConversionFactor
 }
 
-// ToMicroAlgos converts amount in Algos to microAlgos
+// ToMicroAlgos converts amount in Algos to microAlgos. Large amounts lose
+// precision, see ParseAlgos for an exact conversion.
 func ToMicroAlgos(algos float64) MicroAlgos {
 	return MicroAlgos(math.Round(algos * microAlgoConversionFactor))
 }
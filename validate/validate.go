@@ -0,0 +1,315 @@
+// Package validate checks transactions for the well-formedness rules enforced
+// by algod, so that malformed transactions are caught before submission.
+// Every violation is reported at once, each with the path of the field at fault.
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+// Violation is a single well-formedness rule broken by a transaction
+type Violation struct {
+	// Field is the path of the offending field, such as "FirstValid",
+	// "ApplicationArgs[3]" or, for groups, "[2].Fee"
+	Field string
+
+	// Message describes the problem
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return v.Field + ": " + v.Message
+}
+
+// Error lists every violation found in a transaction or group
+type Error []Violation
+
+func (e Error) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("%d violation(s): %s", len(e), strings.Join(msgs, "; "))
+}
+
+// checker accumulates violations under a path prefix
+type checker struct {
+	prefix     string
+	violations Error
+}
+
+func (c *checker) fail(field string, format string, args ...interface{}) {
+	c.violations = append(c.violations, Violation{Field: c.prefix + field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) result() error {
+	if len(c.violations) == 0 {
+		return nil
+	}
+	return c.violations
+}
+
+// Transaction checks a transaction against the consensus parameters. It
+// returns nil or an Error listing every violation found.
+func Transaction(txn types.Transaction, params consensus.Params) error {
+	var c checker
+	c.transaction(txn, params)
+	if params.EnableFeePooling {
+		c.pooledFee([]types.Transaction{txn}, params)
+	}
+	return c.result()
+}
+
+// Group checks every transaction of a group, and that they form a valid group:
+// not too many members, the same genesis and a group id matching its members.
+func Group(txns []types.Transaction, params consensus.Params) error {
+	var c checker
+	if len(txns) == 0 {
+		c.fail("", "group is empty")
+		return c.result()
+	}
+	if len(txns) > params.MaxTxGroupSize {
+		c.fail("", "group has %d transactions, at most %d allowed", len(txns), params.MaxTxGroupSize)
+	}
+
+	for i, txn := range txns {
+		c.prefix = fmt.Sprintf("[%d].", i)
+		c.transaction(txn, params)
+		if txn.GenesisHash != txns[0].GenesisHash {
+			c.fail("GenesisHash", "differs from the rest of the group")
+		}
+	}
+	c.prefix = ""
+
+	if params.EnableFeePooling {
+		c.pooledFee(txns, params)
+	}
+	if len(txns) > 1 {
+		c.groupID(txns)
+	}
+	return c.result()
+}
+
+// pooledFee checks that the fees of a group, pooled, cover the minimum fee of
+// each of its transactions
+func (c *checker) pooledFee(txns []types.Transaction, params consensus.Params) {
+	var total uint64
+	for _, txn := range txns {
+		var overflowed bool
+		total, overflowed = types.OAdd(total, uint64(txn.Fee))
+		if overflowed {
+			c.fail("", "fees of the group overflow")
+			return
+		}
+	}
+	min, overflowed := types.OMul(params.MinTxnFee, uint64(len(txns)))
+	if overflowed || total >= min {
+		return
+	}
+	if len(txns) == 1 {
+		c.fail("Fee", "%d is below the minimum fee of %d", total, params.MinTxnFee)
+	} else {
+		c.fail("", "fees of the group total %d, below the minimum of %d for %d transactions", total, min, len(txns))
+	}
+}
+
+func (c *checker) groupID(txns []types.Transaction) {
+	ungrouped := make([]types.Transaction, len(txns))
+	for i, txn := range txns {
+		if txn.Group == (types.Digest{}) {
+			c.fail(fmt.Sprintf("[%d].Group", i), "is not set")
+			return
+		}
+		txn.Group = types.Digest{}
+		ungrouped[i] = txn
+	}
+	gid, err := crypto.ComputeGroupID(ungrouped)
+	if err != nil {
+		c.fail("", "%v", err)
+		return
+	}
+	for i, txn := range txns {
+		if txn.Group != gid {
+			c.fail(fmt.Sprintf("[%d].Group", i), "does not match the group id computed from its members")
+		}
+	}
+}
+
+func (c *checker) transaction(txn types.Transaction, params consensus.Params) {
+	c.header(txn, params)
+	switch txn.Type {
+	case types.PaymentTx:
+		c.payment(txn)
+	case types.KeyRegistrationTx:
+		c.keyreg(txn, params)
+	case types.AssetConfigTx:
+		c.assetConfig(txn, params)
+	case types.AssetTransferTx:
+		c.assetTransfer(txn)
+	case types.AssetFreezeTx:
+		c.assetFreeze(txn)
+	case types.ApplicationCallTx:
+		c.applicationCall(txn, params)
+	default:
+		c.fail("Type", "unknown transaction type %q", txn.Type)
+	}
+}
+
+func (c *checker) header(txn types.Transaction, params consensus.Params) {
+	if txn.Sender.IsZero() {
+		c.fail("Sender", "is not set")
+	}
+	if txn.FirstValid > txn.LastValid {
+		c.fail("LastValid", "round %d is before FirstValid %d", txn.LastValid, txn.FirstValid)
+	} else if uint64(txn.LastValid-txn.FirstValid) > params.MaxTxnLife {
+		c.fail("LastValid", "validity window of %d rounds exceeds the max of %d", txn.LastValid-txn.FirstValid, params.MaxTxnLife)
+	}
+	// with fee pooling, the fee is checked for the whole group instead
+	if !params.EnableFeePooling && uint64(txn.Fee) < params.MinTxnFee {
+		c.fail("Fee", "%d is below the minimum fee of %d", txn.Fee, params.MinTxnFee)
+	}
+	if len(txn.Note) > params.MaxTxnNoteBytes {
+		c.fail("Note", "is %d bytes, at most %d allowed", len(txn.Note), params.MaxTxnNoteBytes)
+	}
+	if txn.GenesisHash == (types.Digest{}) {
+		c.fail("GenesisHash", "is not set")
+	}
+	if !txn.RekeyTo.IsZero() && !params.SupportRekeying {
+		c.fail("RekeyTo", "rekeying is not supported by this protocol version")
+	}
+}
+
+func (c *checker) payment(txn types.Transaction) {
+	if txn.CloseRemainderTo.IsZero() {
+		return
+	}
+	// closing and rekeying at once is accepted by algod, rekey.Warnings
+	// reports it as pointless
+	if txn.CloseRemainderTo == txn.Sender {
+		c.fail("CloseRemainderTo", "cannot close an account to itself")
+	}
+}
+
+func (c *checker) keyreg(txn types.Transaction, params consensus.Params) {
+	if !txn.Nonparticipation {
+		return
+	}
+	if !params.SupportBecomeNonParticipatingTransactions {
+		c.fail("Nonparticipation", "is not supported by this protocol version")
+	}
+	if txn.VotePK != (types.VotePK{}) || txn.SelectionPK != (types.VRFPK{}) {
+		c.fail("Nonparticipation", "cannot be set along with participation keys")
+	}
+}
+
+func (c *checker) assetConfig(txn types.Transaction, params consensus.Params) {
+	ap := txn.AssetParams
+	if txn.ConfigAsset == 0 && ap.IsZero() {
+		c.fail("AssetParams", "asset creation requires asset params")
+	}
+	if ap.Decimals > params.MaxAssetDecimals {
+		c.fail("AssetParams.Decimals", "%d exceeds the max of %d", ap.Decimals, params.MaxAssetDecimals)
+	}
+	if len(ap.AssetName) > params.MaxAssetNameBytes {
+		c.fail("AssetParams.AssetName", "is %d bytes, at most %d allowed", len(ap.AssetName), params.MaxAssetNameBytes)
+	}
+	if len(ap.UnitName) > params.MaxAssetUnitNameBytes {
+		c.fail("AssetParams.UnitName", "is %d bytes, at most %d allowed", len(ap.UnitName), params.MaxAssetUnitNameBytes)
+	}
+	if len(ap.URL) > params.MaxAssetURLBytes {
+		c.fail("AssetParams.URL", "is %d bytes, at most %d allowed", len(ap.URL), params.MaxAssetURLBytes)
+	}
+}
+
+func (c *checker) assetTransfer(txn types.Transaction) {
+	if txn.XferAsset == 0 {
+		c.fail("XferAsset", "is not set")
+	}
+	if txn.AssetCloseTo.IsZero() {
+		return
+	}
+	if !txn.AssetSender.IsZero() {
+		c.fail("AssetCloseTo", "a clawback transaction cannot close out the holding")
+	}
+	if txn.AssetCloseTo == txn.Sender {
+		c.fail("AssetCloseTo", "cannot close a holding to its own account")
+	}
+}
+
+func (c *checker) assetFreeze(txn types.Transaction) {
+	if txn.FreezeAsset == 0 {
+		c.fail("FreezeAsset", "is not set")
+	}
+	if txn.FreezeAccount.IsZero() {
+		c.fail("FreezeAccount", "is not set")
+	}
+}
+
+func (c *checker) applicationCall(txn types.Transaction, params consensus.Params) {
+	if len(txn.ApplicationArgs) > params.MaxAppArgs {
+		c.fail("ApplicationArgs", "has %d arguments, at most %d allowed", len(txn.ApplicationArgs), params.MaxAppArgs)
+	}
+	argLen := 0
+	for _, arg := range txn.ApplicationArgs {
+		argLen += len(arg)
+	}
+	if argLen > params.MaxAppTotalArgLen {
+		c.fail("ApplicationArgs", "arguments total %d bytes, at most %d allowed", argLen, params.MaxAppTotalArgLen)
+	}
+	if len(txn.Accounts) > params.MaxAppTxnAccounts {
+		c.fail("Accounts", "has %d accounts, at most %d allowed", len(txn.Accounts), params.MaxAppTxnAccounts)
+	}
+	if len(txn.ForeignApps) > params.MaxAppTxnForeignApps {
+		c.fail("ForeignApps", "has %d applications, at most %d allowed", len(txn.ForeignApps), params.MaxAppTxnForeignApps)
+	}
+	if len(txn.ForeignAssets) > params.MaxAppTxnForeignAssets {
+		c.fail("ForeignAssets", "has %d assets, at most %d allowed", len(txn.ForeignAssets), params.MaxAppTxnForeignAssets)
+	}
+	refs := len(txn.Accounts) + len(txn.ForeignApps) + len(txn.ForeignAssets)
+	if params.MaxAppTotalTxnReferences > 0 && refs > params.MaxAppTotalTxnReferences {
+		c.fail("", "application call references %d accounts, applications and assets, at most %d allowed", refs, params.MaxAppTotalTxnReferences)
+	}
+
+	creating := txn.ApplicationID == 0
+	updating := txn.OnCompletion == types.UpdateApplicationOC
+	if creating || updating {
+		if len(txn.ApprovalProgram) == 0 {
+			c.fail("ApprovalProgram", "is required to create or update an application")
+		}
+		if len(txn.ClearStateProgram) == 0 {
+			c.fail("ClearStateProgram", "is required to create or update an application")
+		}
+	} else {
+		if len(txn.ApprovalProgram) > 0 {
+			c.fail("ApprovalProgram", "may only be set to create or update an application")
+		}
+		if len(txn.ClearStateProgram) > 0 {
+			c.fail("ClearStateProgram", "may only be set to create or update an application")
+		}
+	}
+	if len(txn.ApprovalProgram) > params.MaxAppProgramLen {
+		c.fail("ApprovalProgram", "is %d bytes, at most %d allowed", len(txn.ApprovalProgram), params.MaxAppProgramLen)
+	}
+	if len(txn.ClearStateProgram) > params.MaxAppProgramLen {
+		c.fail("ClearStateProgram", "is %d bytes, at most %d allowed", len(txn.ClearStateProgram), params.MaxAppProgramLen)
+	}
+
+	if creating {
+		if entries := txn.GlobalStateSchema.NumUint + txn.GlobalStateSchema.NumByteSlice; entries > params.MaxGlobalSchemaEntries {
+			c.fail("GlobalStateSchema", "has %d entries, at most %d allowed", entries, params.MaxGlobalSchemaEntries)
+		}
+		if entries := txn.LocalStateSchema.NumUint + txn.LocalStateSchema.NumByteSlice; entries > params.MaxLocalSchemaEntries {
+			c.fail("LocalStateSchema", "has %d entries, at most %d allowed", entries, params.MaxLocalSchemaEntries)
+		}
+	} else if txn.GlobalStateSchema != (types.StateSchema{}) || txn.LocalStateSchema != (types.StateSchema{}) {
+		c.fail("GlobalStateSchema", "schemas may only be set when creating an application")
+	}
+}
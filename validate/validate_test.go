@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jffp113/go-algorand-sdk/consensus"
+	"github.com/jffp113/go-algorand-sdk/crypto"
+	"github.com/jffp113/go-algorand-sdk/future"
+	"github.com/jffp113/go-algorand-sdk/types"
+)
+
+var testParams = types.SuggestedParams{
+	Fee:              1000,
+	FlatFee:          true,
+	GenesisID:        "testnet-v1.0",
+	GenesisHash:      []byte("JgsgCaCTqIaLeVhyL6XlRu3n7Rfk2FxMeK+wRSaQ7dI="),
+	FirstRoundValid:  100,
+	LastRoundValid:   1100,
+	ConsensusVersion: consensus.V24,
+}
+
+func fields(t *testing.T, err error) []string {
+	var verr Error
+	require.True(t, errors.As(err, &verr), "expected a validate.Error, got %v", err)
+	var result []string
+	for _, v := range verr {
+		result = append(result, v.Field)
+	}
+	return result
+}
+
+func TestTransactionReportsAllViolations(t *testing.T) {
+	params, err := consensus.FromSuggestedParams(testParams)
+	require.NoError(t, err)
+	sender := crypto.GenerateAccount()
+	tx, err := future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+	require.NoError(t, Transaction(tx, params))
+
+	tx.Fee = 10
+	tx.LastValid = tx.FirstValid + 1001
+	tx.Note = make([]byte, 1025)
+	tx.CloseRemainderTo = sender.Address
+	tx.RekeyTo = crypto.GenerateAccount().Address
+	require.Equal(t, []string{"LastValid", "Fee", "Note", "CloseRemainderTo"}, fields(t, Transaction(tx, params)))
+
+	// an account may be closed and rekeyed at once
+	tx.LastValid, tx.Fee, tx.Note = tx.FirstValid+1000, 1000, nil
+	tx.CloseRemainderTo = crypto.GenerateAccount().Address
+	require.NoError(t, Transaction(tx, params))
+}
+
+func TestApplicationCallLimitsFollowVersion(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	tx, err := future.MakeApplicationNoOpTx(5, make([][]byte, 17), nil, []uint64{1, 2, 3}, []uint64{4}, testParams, sender.Address, nil, types.Digest{}, [32]byte{}, types.Address{})
+	require.NoError(t, err)
+	tx.ApprovalProgram = []byte{0x02}
+
+	v24, err := consensus.Lookup(consensus.V24)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ApplicationArgs", "ForeignApps", "ApprovalProgram"}, fields(t, Transaction(tx, v24)))
+
+	v26, err := consensus.Lookup(consensus.V26)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ApplicationArgs", "ApprovalProgram"}, fields(t, Transaction(tx, v26)))
+
+	_, err = consensus.Lookup("unknown")
+	require.Error(t, err)
+}
+
+func TestGroup(t *testing.T) {
+	params, err := consensus.Lookup(consensus.Latest)
+	require.NoError(t, err)
+	sender := crypto.GenerateAccount()
+	pay, err := future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+	optin, err := future.MakeAssetAcceptanceTxn(sender.Address.String(), nil, testParams, 0)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"[1].XferAsset", "[0].Group"}, fields(t, Group([]types.Transaction{pay, optin}, params)))
+
+	optin.XferAsset = 10
+	gid, err := crypto.ComputeGroupID([]types.Transaction{pay, optin})
+	require.NoError(t, err)
+	pay.Group, optin.Group = gid, gid
+	require.NoError(t, Group([]types.Transaction{pay, optin}, params))
+
+	optin.Note = []byte("changed")
+	require.Equal(t, []string{"[0].Group", "[1].Group"}, fields(t, Group([]types.Transaction{pay, optin}, params)))
+}
+
+func TestGroupPoolsFees(t *testing.T) {
+	sender := crypto.GenerateAccount()
+	pay, err := future.MakePaymentTxn(sender.Address.String(), sender.Address.String(), 1, nil, "", testParams)
+	require.NoError(t, err)
+	covered, err := future.MakeAssetAcceptanceTxn(sender.Address.String(), nil, testParams, 10)
+	require.NoError(t, err)
+	covered.Fee = 0
+	group := func(fee types.MicroAlgos) []types.Transaction {
+		pay.Fee = fee
+		pay.Group, covered.Group = types.Digest{}, types.Digest{}
+		gid, err := crypto.ComputeGroupID([]types.Transaction{pay, covered})
+		require.NoError(t, err)
+		pay.Group, covered.Group = gid, gid
+		return []types.Transaction{pay, covered}
+	}
+
+	// the payment covers the fee of the opt-in from v28 on
+	v28, err := consensus.Lookup(consensus.V28)
+	require.NoError(t, err)
+	require.True(t, v28.EnableFeePooling)
+	require.NoError(t, Group(group(2000), v28))
+	require.Equal(t, []string{"Fee"}, fields(t, Transaction(covered, v28)))
+
+	v27, err := consensus.Lookup(consensus.V27)
+	require.NoError(t, err)
+	require.Equal(t, []string{"[1].Fee"}, fields(t, Group(group(2000), v27)))
+
+	// the pooled fees must still cover every transaction
+	require.Equal(t, []string{""}, fields(t, Group(group(1999), v28)))
+}